@@ -8,6 +8,8 @@ import (
 	"github.com/cryptk/williams/internal/api"
 	"github.com/cryptk/williams/internal/config"
 	"github.com/cryptk/williams/internal/database"
+	"github.com/cryptk/williams/internal/encryption"
+	"github.com/cryptk/williams/internal/repository"
 	"github.com/cryptk/williams/pkg/logger"
 	"github.com/cryptk/williams/pkg/utils"
 	"github.com/rs/zerolog/log"
@@ -30,6 +32,14 @@ func main() {
 		log.Fatal().Err(err).Str("timezone", cfg.Timezone).Msg("Failed to initialize timezone")
 	}
 
+	// Initialize encryption of sensitive fields such as notes and account numbers
+	cipher, err := encryption.New(&cfg.Encryption)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize encryption")
+	}
+	encryption.Use(cipher)
+	log.Info().Str("key_id", cipher.KeyID()).Msg("Encryption initialized")
+
 	// Initialize database
 	log.Info().Str("driver", cfg.Database.Driver).Msg("Initializing database connection")
 	db, err := database.New(&cfg.Database)
@@ -45,6 +55,17 @@ func main() {
 	}
 	log.Info().Msg("Migrations completed successfully")
 
+	// "williams reencrypt" rewrites encrypted fields with the current key, e.g. after a key rotation
+	if len(os.Args) > 1 && os.Args[1] == "reencrypt" {
+		log.Info().Str("key_id", cipher.KeyID()).Msg("Re-encrypting sensitive fields...")
+		rewritten, err := repository.Reencrypt(db.DB, cipher)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to re-encrypt sensitive fields")
+		}
+		log.Info().Int64("rows", rewritten).Msg("Re-encryption completed successfully")
+		return
+	}
+
 	// Initialize API server
 	server := api.NewServer(cfg, db)
 
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cryptk/williams/internal/api/middleware"
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Account self-service handlers
+
+func (s *Server) updateProfile(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := s.accountService.UpdateProfile(c.Request.Context(), userID, &req)
+	var limitErr *ratelimit.LimitError
+	if errors.As(err, &limitErr) {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Profile update throttled")
+		middleware.AbortWithLimitError(c, limitErr)
+		return
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Profile update failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (s *Server) exportData(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	export, err := s.accountService.ExportData(scopedDB, userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to export personal data")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export personal data"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=williams-export-%s.json", export.ExportedAt.Format("2006-01-02")))
+	c.JSON(http.StatusOK, export)
+}
+
+func (s *Server) deleteAccount(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scheduledFor, err := s.accountService.RequestDeletion(c.Request.Context(), userID, &req)
+	var limitErr *ratelimit.LimitError
+	if errors.As(err, &limitErr) {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Account deletion request throttled")
+		middleware.AbortWithLimitError(c, limitErr)
+		return
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Account deletion request failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Without a grace period the account is already gone
+	status, message := http.StatusAccepted, "Account scheduled for deletion, log in and restore it before then to keep it"
+	if !scheduledFor.After(time.Now()) {
+		status, message = http.StatusOK, "Account deleted"
+	}
+	c.JSON(status, models.AccountDeletionResponse{
+		Message:              message,
+		DeletionScheduledFor: scheduledFor,
+	})
+}
+
+func (s *Server) restoreAccount(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	user, err := s.accountService.CancelDeletion(c.Request.Context(), userID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Account restore failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/cryptk/williams/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestPasswordConfirmationsCountTowardsLockout(t *testing.T) {
+	routes := []struct {
+		name   string
+		method string
+		path   string
+		body   gin.H
+	}{
+		{"change password", http.MethodPost, "/api/v1/auth/password", gin.H{"current_password": "wrong-password", "new_password": "An0ther-passw0rd"}},
+		{"change email", http.MethodPut, "/api/v1/me", gin.H{"email": "new@example.com", "current_password": "wrong-password"}},
+		{"delete account", http.MethodDelete, "/api/v1/me", gin.H{"password": "wrong-password"}},
+	}
+	for _, route := range routes {
+		t.Run(route.name, func(t *testing.T) {
+			ts := newTestServer(t)
+			session, user := ts.register(t, "alice")
+
+			threshold := ts.config.Auth.RateLimit.LockoutThreshold
+			for range threshold {
+				if rec := ts.request(t, route.method, route.path, session, route.body); rec.Code != http.StatusBadRequest {
+					t.Fatalf("wrong password: got status %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body.String())
+				}
+			}
+
+			// The account is now locked, for further confirmations and for logging in
+			rec := ts.request(t, route.method, route.path, session, route.body)
+			if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+				t.Errorf("after %d wrong passwords: got status %d, want %d with Retry-After", threshold, rec.Code, http.StatusTooManyRequests)
+			}
+			rec = ts.request(t, http.MethodPost, "/api/v1/auth/login", "", gin.H{"username": user.Username, "password": testPassword})
+			if rec.Code != http.StatusTooManyRequests {
+				t.Errorf("login after %d wrong passwords: got status %d, want %d", threshold, rec.Code, http.StatusTooManyRequests)
+			}
+		})
+	}
+}
+
+func TestPasswordConfirmationsAreRateLimited(t *testing.T) {
+	ts := newTestServer(t, func(cfg *config.Config) {
+		cfg.Auth.RateLimit.IPRequests = 3
+	})
+	session, _ := ts.register(t, "alice")
+
+	// Registering used the first of the three requests allowed per address
+	for i := range 2 {
+		body := gin.H{"username": fmt.Sprintf("alice%d", i)}
+		if rec := ts.request(t, http.MethodPut, "/api/v1/me", session, body); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: got status %d, want %d: %s", i+2, rec.Code, http.StatusOK, rec.Body.String())
+		}
+	}
+	for _, route := range []struct{ method, path string }{
+		{http.MethodPut, "/api/v1/me"},
+		{http.MethodDelete, "/api/v1/me"},
+		{http.MethodPost, "/api/v1/auth/password"},
+		{http.MethodPost, "/api/v1/auth/2fa/disable"},
+		{http.MethodPost, "/api/v1/auth/2fa/recovery-codes"},
+	} {
+		if rec := ts.request(t, route.method, route.path, session, gin.H{}); rec.Code != http.StatusTooManyRequests {
+			t.Errorf("%s %s over the limit: got status %d, want %d", route.method, route.path, rec.Code, http.StatusTooManyRequests)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Admin handlers
+
+func (s *Server) resetUserTOTP(c *gin.Context) {
+	adminID := c.GetString("user_id")
+	targetID := c.Param("id")
+
+	if err := s.authService.ResetTOTP(c.Request.Context(), targetID); err != nil {
+		log.Warn().Err(err).Str("admin_id", adminID).Str("target_user_id", targetID).Msg("Failed to reset user 2FA")
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	log.Info().Str("admin_id", adminID).Str("target_user_id", targetID).Msg("Admin reset user 2FA")
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Two-factor authentication reset",
+		"id":      targetID,
+	})
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// API token handlers
+
+func (s *Server) listAPITokens(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	tokens, err := s.authService.ListAPITokens(scopedDB)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to list api tokens")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve api tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tokens": tokens,
+		"total":  len(tokens),
+	})
+}
+
+func (s *Server) createAPIToken(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req models.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plaintext, token, err := s.authService.CreateAPIToken(scopedDB, userID, &req)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to create api token")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Info().Str("user_id", userID).Str("token_id", token.ID).Str("scope", token.Scope).Msg("API token created")
+
+	c.JSON(http.StatusCreated, models.CreateAPITokenResponse{
+		Token:    plaintext,
+		APIToken: *token,
+	})
+}
+
+func (s *Server) revokeAPIToken(c *gin.Context) {
+	id := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := s.authService.RevokeAPIToken(scopedDB, id); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Str("token_id", id).Msg("Failed to revoke api token")
+		c.JSON(http.StatusNotFound, gin.H{"error": "API token not found"})
+		return
+	}
+
+	log.Info().Str("user_id", userID).Str("token_id", id).Msg("API token revoked")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API token revoked successfully",
+		"id":      id,
+	})
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAPITokensCannotUseAccountControlRoutes(t *testing.T) {
+	ts := newTestServer(t)
+	_, admin := ts.register(t, "admin")
+	_, victim := ts.register(t, "victim")
+	session := ts.makeAdmin(t, admin)
+	token := ts.createAPIToken(t, session, "read_write")
+
+	routes := []struct {
+		method string
+		path   string
+		body   any
+	}{
+		{http.MethodDelete, "/api/v1/admin/users/" + victim.ID + "/2fa", nil},
+		{http.MethodGet, "/api/v1/admin/audit", nil},
+		{http.MethodPost, "/api/v1/auth/password", gin.H{"current_password": testPassword, "new_password": "An0ther-passw0rd"}},
+		{http.MethodPut, "/api/v1/me", gin.H{"email": "attacker@example.com", "current_password": testPassword}},
+		{http.MethodDelete, "/api/v1/me", gin.H{"password": testPassword}},
+		{http.MethodPost, "/api/v1/me/restore", nil},
+		{http.MethodGet, "/api/v1/me/export", nil},
+		{http.MethodGet, "/api/v1/me/preferences", nil},
+		{http.MethodPost, "/api/v1/auth/2fa/setup", nil},
+		{http.MethodPost, "/api/v1/auth/2fa/disable", gin.H{"password": testPassword, "code": "000000"}},
+		{http.MethodPost, "/api/v1/auth/2fa/recovery-codes", gin.H{"code": "000000"}},
+		{http.MethodPost, "/api/v1/auth/oidc/link", nil},
+		{http.MethodPost, "/api/v1/tokens", gin.H{"name": "another", "scope": "read_write"}},
+	}
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			if rec := ts.request(t, route.method, route.path, token, route.body); rec.Code != http.StatusForbidden {
+				t.Errorf("got status %d for an api token, want %d: %s", rec.Code, http.StatusForbidden, rec.Body.String())
+			}
+		})
+	}
+
+	// Nothing was changed, and the same session can still use the routes
+	if rec := ts.request(t, http.MethodGet, "/api/v1/admin/audit", session, nil); rec.Code != http.StatusOK {
+		t.Errorf("admin audit with a session: got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := ts.request(t, http.MethodGet, "/api/v1/me/export", session, nil); rec.Code != http.StatusOK {
+		t.Errorf("export with a session: got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	ts.login(t, admin.Username)
+
+	// Api tokens keep working for everything else
+	if rec := ts.request(t, http.MethodGet, "/api/v1/bills", token, nil); rec.Code != http.StatusOK {
+		t.Errorf("listing bills with an api token: got status %d, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// multipartOverhead allows for the multipart headers around an uploaded file
+const multipartOverhead = 64 << 10
+
+// Attachment handlers
+
+func (s *Server) listBillAttachments(c *gin.Context) {
+	billID := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	attachments, err := s.attachmentService.ListForBill(scopedDB, billID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Str("bill_id", billID).Msg("Failed to list bill attachments")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Bill not found",
+			"id":    billID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attachments": attachments})
+}
+
+func (s *Server) listPaymentAttachments(c *gin.Context) {
+	billID := c.Param("id")
+	paymentID := c.Param("payment_id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	attachments, err := s.attachmentService.ListForPayment(scopedDB, billID, paymentID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Str("payment_id", paymentID).Msg("Failed to list payment attachments")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Payment not found",
+			"id":    paymentID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attachments": attachments})
+}
+
+func (s *Server) uploadBillAttachment(c *gin.Context) {
+	s.uploadAttachment(c, nil)
+}
+
+func (s *Server) uploadPaymentAttachment(c *gin.Context) {
+	paymentID := c.Param("payment_id")
+	s.uploadAttachment(c, &paymentID)
+}
+
+// uploadAttachment attaches the multipart "file" field to the bill in the URL, or to one of its payments
+func (s *Server) uploadAttachment(c *gin.Context, paymentID *string) {
+	billID := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, s.attachmentService.MaxSize()+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a file must be uploaded in the 'file' form field, up to " + strconv.FormatInt(s.attachmentService.MaxSize(), 10) + " bytes"})
+		return
+	}
+	if header.Size > s.attachmentService.MaxSize() {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file exceeds the maximum size of " + strconv.FormatInt(s.attachmentService.MaxSize(), 10) + " bytes"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	attachment := models.Attachment{
+		UserID:    userID, // Set user ID from authenticated context
+		BillID:    billID,
+		PaymentID: paymentID,
+		FileName:  header.Filename,
+	}
+	if err := s.attachmentService.Upload(c.Request.Context(), scopedDB, &attachment, file); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("bill_id", billID).Msg("Failed to upload attachment")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+func (s *Server) downloadAttachment(c *gin.Context) {
+	id := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	attachment, file, err := s.attachmentService.Open(c.Request.Context(), scopedDB, id)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("attachment_id", id).Msg("Failed to open attachment")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Attachment not found",
+			"id":    id,
+		})
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, io.Reader(file), nil)
+}
+
+func (s *Server) deleteAttachment(c *gin.Context) {
+	id := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := s.attachmentService.Delete(c.Request.Context(), scopedDB, id); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("attachment_id", id).Msg("Failed to delete attachment")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Attachment not found",
+			"id":    id,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Attachment deleted successfully",
+		"id":      id,
+	})
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// defaultAuditPageSize and maxAuditPageSize bound audit listings
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 500
+)
+
+// Audit log handlers
+
+func (s *Server) listAuditEvents(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	events, err := s.auditService.List(scopedDB, filter)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to list audit events")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newAuditListResponse(events, filter.Limit))
+}
+
+func (s *Server) listAllAuditEvents(c *gin.Context) {
+	adminID := c.GetString("user_id")
+
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.UserID = c.Query("user_id")
+
+	events, err := s.auditService.ListAll(filter)
+	if err != nil {
+		log.Error().Err(err).Str("admin_id", adminID).Msg("Failed to list audit events")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newAuditListResponse(events, filter.Limit))
+}
+
+// parseAuditFilter reads the action, before and limit query parameters
+func parseAuditFilter(c *gin.Context) (repository.AuditFilter, error) {
+	filter := repository.AuditFilter{Action: c.Query("action"), Limit: defaultAuditPageSize}
+
+	if before := c.Query("before"); before != "" {
+		t, err := time.Parse(time.RFC3339Nano, before)
+		if err != nil {
+			return filter, errors.New("before must be an RFC 3339 timestamp")
+		}
+		filter.Before = t
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxAuditPageSize {
+			return filter, fmt.Errorf("limit must be between 1 and %d", maxAuditPageSize)
+		}
+		filter.Limit = n
+	}
+	return filter, nil
+}
+
+// newAuditListResponse builds a page of events with the cursor for the next page, if there may be one
+func newAuditListResponse(events []*models.AuditEvent, limit int) models.AuditListResponse {
+	response := models.AuditListResponse{Events: events}
+	if len(events) == limit {
+		response.NextBefore = &events[len(events)-1].CreatedAt
+	}
+	return response
+}
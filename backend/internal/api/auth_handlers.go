@@ -1,9 +1,13 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/cryptk/williams/internal/api/middleware"
 	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/services"
+	"github.com/cryptk/williams/pkg/ratelimit"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
@@ -17,7 +21,7 @@ func (s *Server) register(c *gin.Context) {
 		return
 	}
 
-	user, err := s.authService.Register(&req)
+	user, err := s.authService.Register(c.Request.Context(), &req)
 	if err != nil {
 		log.Warn().Err(err).Str("username", req.Username).Msg("Registration failed")
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -26,8 +30,13 @@ func (s *Server) register(c *gin.Context) {
 
 	log.Info().Str("user_id", user.ID).Str("username", user.Username).Msg("User registered successfully")
 
+	// Failing to send the verification email should not fail registration; the user can request a new one
+	if err := s.accountService.SendVerificationEmail(user); err != nil {
+		log.Error().Err(err).Str("user_id", user.ID).Msg("Failed to send verification email")
+	}
+
 	// Generate token for the new user
-	token, _, err := s.authService.Login(&models.LoginRequest{
+	token, _, err := s.authService.Login(c.Request.Context(), &models.LoginRequest{
 		Username: req.Username,
 		Password: req.Password,
 	})
@@ -50,7 +59,21 @@ func (s *Server) login(c *gin.Context) {
 		return
 	}
 
-	token, user, err := s.authService.Login(&req)
+	token, user, err := s.authService.Login(c.Request.Context(), &req)
+	if errors.Is(err, services.ErrMFARequired) {
+		log.Info().Str("user_id", user.ID).Msg("Password accepted, awaiting second factor")
+		c.JSON(http.StatusOK, models.MFAChallengeResponse{
+			MFARequired:  true,
+			PreAuthToken: token,
+		})
+		return
+	}
+	var limitErr *ratelimit.LimitError
+	if errors.As(err, &limitErr) {
+		log.Warn().Err(err).Str("username", req.Username).Msg("Login throttled")
+		middleware.AbortWithLimitError(c, limitErr)
+		return
+	}
 	if err != nil {
 		log.Warn().Err(err).Str("username", req.Username).Msg("Login failed")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
@@ -80,3 +103,208 @@ func (s *Server) getCurrentUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, user)
 }
+
+// Password and email verification handlers
+
+func (s *Server) changePassword(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := s.accountService.ChangePassword(c.Request.Context(), userID, &req)
+	var limitErr *ratelimit.LimitError
+	if errors.As(err, &limitErr) {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Password change throttled")
+		middleware.AbortWithLimitError(c, limitErr)
+		return
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Password change failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Other sessions are now invalid, so hand the caller a fresh token for this one
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password changed successfully",
+		"token":   token,
+	})
+}
+
+func (s *Server) forgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Send in the background so response timing does not reveal whether the account exists
+	go func() {
+		if err := s.accountService.RequestPasswordReset(req.Email); err != nil {
+			log.Error().Err(err).Msg("Failed to send password reset email")
+		}
+	}()
+
+	c.JSON(http.StatusOK, gin.H{"message": "If an account exists for that email, a reset link has been sent"})
+}
+
+func (s *Server) resetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.accountService.ResetPassword(c.Request.Context(), &req); err != nil {
+		log.Warn().Err(err).Msg("Password reset failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please log in"})
+}
+
+func (s *Server) verifyEmail(c *gin.Context) {
+	var req models.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := s.accountService.VerifyEmail(c.Request.Context(), &req)
+	if err != nil {
+		log.Warn().Err(err).Msg("Email verification failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (s *Server) resendVerificationEmail(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	if err := s.accountService.ResendVerificationEmail(userID); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Failed to resend verification email")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
+
+// Two-factor authentication handlers
+
+func (s *Server) verifyMFA(c *gin.Context) {
+	var req models.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, user, err := s.authService.VerifyMFA(c.Request.Context(), &req)
+	var limitErr *ratelimit.LimitError
+	if errors.As(err, &limitErr) {
+		log.Warn().Err(err).Msg("Two-factor verification throttled")
+		middleware.AbortWithLimitError(c, limitErr)
+		return
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg("Two-factor verification failed")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Info().Str("user_id", user.ID).Str("username", user.Username).Msg("User logged in successfully")
+
+	c.JSON(http.StatusOK, models.AuthResponse{
+		Token: token,
+		User:  *user,
+	})
+}
+
+func (s *Server) setupTOTP(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	setup, err := s.authService.SetupTOTP(userID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Failed to start TOTP setup")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+func (s *Server) enableTOTP(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := s.authService.EnableTOTP(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Failed to enable TOTP")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (s *Server) disableTOTP(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.TOTPDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := s.authService.DisableTOTP(c.Request.Context(), userID, &req)
+	var limitErr *ratelimit.LimitError
+	if errors.As(err, &limitErr) {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Disabling TOTP throttled")
+		middleware.AbortWithLimitError(c, limitErr)
+		return
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Failed to disable TOTP")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+func (s *Server) regenerateRecoveryCodes(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := s.authService.RegenerateRecoveryCodes(c.Request.Context(), userID, req.Code)
+	var limitErr *ratelimit.LimitError
+	if errors.As(err, &limitErr) {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Recovery code regeneration throttled")
+		middleware.AbortWithLimitError(c, limitErr)
+		return
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Failed to regenerate recovery codes")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Funding account handlers
+
+func (s *Server) listFundingAccounts(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	accounts, err := s.fundingAccountService.List(scopedDB)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to list accounts")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"accounts": accounts})
+}
+
+func (s *Server) createFundingAccount(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var account models.FundingAccount
+	if err := c.ShouldBindJSON(&account); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	account.ID = ""
+	account.UserID = userID // Set user ID from authenticated context
+
+	if err := s.fundingAccountService.Create(scopedDB, &account); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Failed to create account")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, account)
+}
+
+func (s *Server) getFundingAccount(c *gin.Context) {
+	id := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	account, err := s.fundingAccountService.Get(scopedDB, id)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Str("account_id", id).Msg("Failed to get account")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Account not found",
+			"id":    id,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+func (s *Server) updateFundingAccount(c *gin.Context) {
+	id := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var account models.FundingAccount
+	if err := c.ShouldBindJSON(&account); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	account.ID = id
+
+	if err := s.fundingAccountService.Update(scopedDB, &account); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("account_id", id).Msg("Failed to update account")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Account not found",
+			"id":    id,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+func (s *Server) deleteFundingAccount(c *gin.Context) {
+	id := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := s.fundingAccountService.Delete(scopedDB, id); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("account_id", id).Msg("Failed to delete account")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Account not found",
+			"id":    id,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account deleted successfully",
+		"id":      id,
+	})
+}
+
+// getBalanceProjection projects each account's balance over the next days as its bills come due
+func (s *Server) getBalanceProjection(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "90"))
+	if err != nil || days < 1 || days > 366 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 366"})
+		return
+	}
+
+	accountID := c.Query("account_id")
+	projection, err := s.fundingAccountService.Projection(scopedDB, days, accountID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("account_id", accountID).Msg("Failed to project account balances")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Account not found",
+			"id":    accountID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, projection)
+}
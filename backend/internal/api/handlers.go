@@ -3,8 +3,10 @@ package api
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
 	"github.com/cryptk/williams/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
@@ -32,6 +34,14 @@ func fetchTenancyFromContext(c *gin.Context) (string, *gorm.DB, error) {
 	return userID, scopedDB, nil
 }
 
+// isNotFound reports whether err is about a bill, payment or category that does not exist or belongs to
+// another user, which is answered with 404 like any other missing record
+func isNotFound(err error) bool {
+	return errors.Is(err, repository.ErrBillNotFound) ||
+		errors.Is(err, repository.ErrPaymentNotFound) ||
+		errors.Is(err, repository.ErrCategoryNotFound)
+}
+
 // Bill handlers
 
 func (s *Server) listBills(c *gin.Context) {
@@ -43,17 +53,24 @@ func (s *Server) listBills(c *gin.Context) {
 		return
 	}
 
-	bills, err := s.billService.List(scopedDB)
+	filter, err := parseBillFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bills, err := s.billService.Search(scopedDB, filter)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		log.Error().Err(err).Str("user_id", userID).Msg("Failed to list bills")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bills"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"bills": bills,
-		"total": len(bills),
-	})
+	c.JSON(http.StatusOK, bills)
 }
 
 func (s *Server) getBill(c *gin.Context) {
@@ -79,6 +96,57 @@ func (s *Server) getBill(c *gin.Context) {
 	c.JSON(http.StatusOK, bill)
 }
 
+func (s *Server) getBillHistory(c *gin.Context) {
+	id := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	versions, err := s.billService.History(scopedDB, id)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Str("bill_id", id).Msg("Failed to get bill history")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Bill not found",
+			"id":    id,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"versions": versions})
+}
+
+func (s *Server) getBillAmortization(c *gin.Context) {
+	id := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	extra, err := strconv.ParseFloat(c.DefaultQuery("extra_payment", "0"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "extra_payment must be a number"})
+		return
+	}
+
+	amortization, err := s.billService.Amortization(scopedDB, id, extra)
+	if err != nil {
+		if isNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Warn().Err(err).Str("user_id", userID).Str("bill_id", id).Msg("Failed to get bill amortization")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, amortization)
+}
+
 func (s *Server) createBill(c *gin.Context) {
 	// SECURITY: Always set user_id from JWT, never from request body
 	userID, scopedDB, err := fetchTenancyFromContext(c)
@@ -98,12 +166,12 @@ func (s *Server) createBill(c *gin.Context) {
 
 	bill.UserID = userID
 
-	// If CategoryID is present but empty, set to nil so GORM inserts NULL
-	if bill.CategoryID != nil && *bill.CategoryID == "" {
-		bill.CategoryID = nil
-	}
-
 	if err := s.billService.Create(scopedDB, &bill); err != nil {
+		var refErr *repository.ReferenceError
+		if errors.As(err, &refErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": refErr.Error()})
+			return
+		}
 		log.Error().Err(err).Str("user_id", userID).Msg("Failed to create bill")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create bill"})
 		return
@@ -133,6 +201,15 @@ func (s *Server) updateBill(c *gin.Context) {
 	bill.UserID = userID
 
 	if err := s.billService.Update(scopedDB, &bill); err != nil {
+		var refErr *repository.ReferenceError
+		if errors.As(err, &refErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": refErr.Error()})
+			return
+		}
+		if isNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		log.Error().Err(err).Str("user_id", userID).Str("bill_id", id).Msg("Failed to update bill")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update bill"})
 		return
@@ -151,13 +228,17 @@ func (s *Server) deleteBill(c *gin.Context) {
 	}
 
 	if err := s.billService.Delete(scopedDB, id); err != nil {
+		if isNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		log.Error().Err(err).Str("user_id", userID).Str("bill_id", id).Msg("Failed to delete bill")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete bill"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Bill deleted successfully",
+		"message": "Bill moved to trash",
 		"id":      id,
 	})
 }
@@ -172,7 +253,7 @@ func (s *Server) listCategories(c *gin.Context) {
 		return
 	}
 
-	categories, err := s.categoryService.List(scopedDB)
+	categories, err := s.categoryService.List(scopedDB, parseListOptions(c))
 	if err != nil {
 		log.Error().Err(err).Str("user_id", userID).Msg("Failed to list categories")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve categories"})
@@ -219,13 +300,17 @@ func (s *Server) deleteCategory(c *gin.Context) {
 	}
 
 	if err := s.categoryService.Delete(scopedDB, id); err != nil {
+		if isNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		log.Error().Err(err).Str("user_id", userID).Str("category_id", id).Msg("Failed to delete category")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Category deleted successfully",
+		"message": "Category moved to trash",
 		"id":      id,
 	})
 }
@@ -240,7 +325,7 @@ func (s *Server) getStatsSummary(c *gin.Context) {
 		return
 	}
 
-	stats, err := s.billService.GetStats(scopedDB)
+	stats, err := s.billService.GetStats(scopedDB, parseReportFilter(c))
 	if err != nil {
 		log.Error().Err(err).Str("user_id", userID).Msg("Failed to get stats")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bill statistics"})
@@ -250,6 +335,55 @@ func (s *Server) getStatsSummary(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+func (s *Server) getForecast(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "90"))
+	if err != nil || days < 1 || days > 366 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 366"})
+		return
+	}
+
+	from := utils.NowInAppTimezone()
+	forecast, err := s.billService.Forecast(scopedDB, from, from.AddDate(0, 0, days), parseReportFilter(c))
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to get forecast")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bill forecast"})
+		return
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
+
+func (s *Server) getPriceIncreases(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	months, err := strconv.Atoi(c.DefaultQuery("months", "12"))
+	if err != nil || months < 1 || months > 120 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "months must be between 1 and 120"})
+		return
+	}
+
+	increases, err := s.billService.PriceIncreases(scopedDB, utils.NowInAppTimezone().AddDate(0, -months, 0), parseReportFilter(c))
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to get price increases")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch price increases"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"price_increases": increases})
+}
+
 // Payment handlers
 
 func (s *Server) createPayment(c *gin.Context) {
@@ -270,10 +404,19 @@ func (s *Server) createPayment(c *gin.Context) {
 	payment.BillID = billID
 	payment.UserID = userID // Set user ID from authenticated context
 
-	// Convert payment date to application timezone
-	payment.PaymentDate = utils.ConvertToAppTimezone(payment.PaymentDate)
+	// Convert payment date to the user's timezone
+	payment.PaymentDate = payment.PaymentDate.In(utils.LocationFromContext(c.Request.Context()))
 
 	if err := s.billService.CreatePayment(scopedDB, &payment); err != nil {
+		var refErr *repository.ReferenceError
+		if errors.As(err, &refErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": refErr.Error()})
+			return
+		}
+		if isNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		log.Error().Err(err).Str("user_id", userID).Str("bill_id", billID).Msg("Failed to create payment")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create payment"})
 		return
@@ -291,17 +434,67 @@ func (s *Server) listPayments(c *gin.Context) {
 		return
 	}
 
-	payments, err := s.billService.ListPayments(scopedDB, billID)
+	filter, err := parsePaymentFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.BillID = billID
+
+	payments, err := s.billService.SearchPayments(scopedDB, filter)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, repository.ErrBillNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Bill not found",
+			"id":    billID,
+		})
+		return
+	}
 	if err != nil {
 		log.Error().Err(err).Str("user_id", userID).Str("bill_id", billID).Msg("Failed to list payments")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve payments"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"payments": payments,
-		"total":    len(payments),
-	})
+	c.JSON(http.StatusOK, payments)
+}
+
+// listAllPayments lists the payments of every bill that is not in the trash with their bill and
+// category, like a checkbook register
+func (s *Server) listAllPayments(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	filter, err := parsePaymentFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.BillID = c.Query("bill_id")
+	filter.CategoryID = c.Query("category_id")
+	filter.TagID = c.Query("tag_id")
+	filter.PayeeID = c.Query("payee_id")
+	filter.AccountID = c.Query("account_id")
+
+	ledger, err := s.ledgerService.Ledger(scopedDB, filter)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to list payment ledger")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve payments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ledger)
 }
 
 func (s *Server) deletePayment(c *gin.Context) {
@@ -314,6 +507,10 @@ func (s *Server) deletePayment(c *gin.Context) {
 	}
 
 	if err := s.billService.DeletePayment(scopedDB, paymentID); err != nil {
+		if isNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		log.Error().Err(err).Str("user_id", userID).Str("payment_id", paymentID).Msg("Failed to delete payment")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete payment"})
 		return
@@ -324,3 +521,33 @@ func (s *Server) deletePayment(c *gin.Context) {
 		"id":      paymentID,
 	})
 }
+
+func (s *Server) reversePayment(c *gin.Context) {
+	billID := c.Param("id")
+	paymentID := c.Param("payment_id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req models.ReversePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	payment, err := s.billService.ReversePayment(scopedDB, billID, paymentID, req.Reason)
+	if err != nil {
+		if isNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Warn().Err(err).Str("user_id", userID).Str("payment_id", paymentID).Msg("Failed to reverse payment")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Holiday calendar handlers
+
+func (s *Server) listHolidayCalendars(c *gin.Context) {
+	calendars := s.holidayService.ListCalendars()
+	c.JSON(http.StatusOK, gin.H{"calendars": calendars})
+}
+
+func (s *Server) getHolidayCalendar(c *gin.Context) {
+	country := c.Param("country")
+
+	year := 0
+	if raw := c.Query("year"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "year must be a positive number"})
+			return
+		}
+		year = parsed
+	}
+
+	cal, err := s.holidayService.GetCalendar(country)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"country":  cal.Country,
+		"name":     cal.Name,
+		"holidays": cal.Holidays(year),
+	})
+}
+
+// User holiday handlers
+
+func (s *Server) listUserHolidays(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	holidays, err := s.holidayService.ListUserHolidays(scopedDB)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to list holidays")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve holidays"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"holidays": holidays})
+}
+
+func (s *Server) createUserHoliday(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var holiday models.UserHoliday
+	if err := c.ShouldBindJSON(&holiday); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	holiday.UserID = userID // Set user ID from authenticated context
+
+	if err := s.holidayService.CreateUserHoliday(scopedDB, &holiday); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Failed to create holiday")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, holiday)
+}
+
+func (s *Server) deleteUserHoliday(c *gin.Context) {
+	id := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := s.holidayService.DeleteUserHoliday(scopedDB, id); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("holiday_id", id).Msg("Failed to delete holiday")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Holiday not found",
+			"id":    id,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Holiday deleted successfully",
+		"id":      id,
+	})
+}
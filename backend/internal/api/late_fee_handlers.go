@@ -0,0 +1,89 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Late fee waiver handlers
+
+func (s *Server) listLateFeeWaivers(c *gin.Context) {
+	billID := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	waivers, err := s.billService.ListLateFeeWaivers(scopedDB, billID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Str("bill_id", billID).Msg("Failed to list late fee waivers")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Bill not found",
+			"id":    billID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"waivers": waivers})
+}
+
+func (s *Server) createLateFeeWaiver(c *gin.Context) {
+	billID := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var waiver models.BillLateFeeWaiver
+	if err := c.ShouldBindJSON(&waiver); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	waiver.BillID = billID
+	waiver.UserID = userID // Set user ID from authenticated context
+
+	if err := s.billService.WaiveLateFee(scopedDB, &waiver); err != nil {
+		if isNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Warn().Err(err).Str("user_id", userID).Str("bill_id", billID).Msg("Failed to waive late fee")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, waiver)
+}
+
+func (s *Server) deleteLateFeeWaiver(c *gin.Context) {
+	billID := c.Param("id")
+	waiverID := c.Param("waiver_id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := s.billService.UnwaiveLateFee(scopedDB, billID, waiverID); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("waiver_id", waiverID).Msg("Failed to delete late fee waiver")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Waiver not found",
+			"id":    waiverID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Waiver deleted successfully",
+		"id":      waiverID,
+	})
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
+	"github.com/cryptk/williams/internal/services"
+	"github.com/cryptk/williams/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// maxListPageSize bounds the limit of paginated bill and payment listings. Without a limit every
+// match is returned.
+const maxListPageSize = 500
+
+// billListStatuses are the accepted values of the bill listing's status parameter
+var billListStatuses = []string{
+	models.BillStatusActive,
+	models.BillStatusPaused,
+	models.BillStatusEnded,
+	models.BillStatusPaidOff,
+	services.BillFilterStatusPaid,
+	services.BillFilterStatusUnpaid,
+}
+
+// parseBillFilter reads the search, filter, sort and pagination query parameters of the bill listing,
+// e.g. ?q=power&status=unpaid&due_before=2024-06-30&sort=-amount&limit=20
+func parseBillFilter(c *gin.Context) (services.BillListFilter, error) {
+	filter := services.BillListFilter{
+		BillFilter: repository.BillFilter{
+			ListOptions: parseListOptions(c),
+			CategoryID:  c.Query("category_id"),
+			TagID:       c.Query("tag_id"),
+			PayeeID:     c.Query("payee_id"),
+			AccountID:   c.Query("account_id"),
+		},
+	}
+	var err error
+
+	filter.Query = c.Query("q")
+	filter.Status = c.Query("status")
+	if filter.Status != "" && !slices.Contains(billListStatuses, filter.Status) {
+		return filter, fmt.Errorf("status must be one of %v", billListStatuses)
+	}
+	if filter.DueAfter, err = parseDateQuery(c, "due_after"); err != nil {
+		return filter, err
+	}
+	if filter.DueBefore, err = parseDateQuery(c, "due_before"); err != nil {
+		return filter, err
+	}
+	if filter.MinAmount, err = parseAmountQuery(c, "min_amount"); err != nil {
+		return filter, err
+	}
+	if filter.MaxAmount, err = parseAmountQuery(c, "max_amount"); err != nil {
+		return filter, err
+	}
+	if filter.Sort, err = repository.ParseSort(c.Query("sort"), repository.Sort{Field: "name"}, "name", "amount", "created_at", services.BillSortDueDate); err != nil {
+		return filter, err
+	}
+	filter.Cursor, filter.Limit, err = parsePageQuery(c)
+	return filter, err
+}
+
+// parseReportFilter reads the category_id, tag_id, payee_id and account_id query parameters that narrow
+// stats and reports to some bills
+func parseReportFilter(c *gin.Context) repository.BillFilter {
+	return repository.BillFilter{
+		CategoryID: c.Query("category_id"),
+		TagID:      c.Query("tag_id"),
+		PayeeID:    c.Query("payee_id"),
+		AccountID:  c.Query("account_id"),
+	}
+}
+
+// parsePaymentFilter reads the filter, sort and pagination query parameters of payment listings,
+// e.g. ?from=2024-01-01&to=2024-03-31&min_amount=50&limit=20. Dates are inclusive calendar days.
+func parsePaymentFilter(c *gin.Context) (repository.PaymentFilter, error) {
+	var filter repository.PaymentFilter
+	var err error
+
+	if filter.From, err = parseDateQuery(c, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseDateQuery(c, "to"); err != nil {
+		return filter, err
+	}
+	if filter.To != nil {
+		// Include payments made at any time on the last day
+		to := filter.To.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+	if filter.MinAmount, err = parseAmountQuery(c, "min_amount"); err != nil {
+		return filter, err
+	}
+	if filter.MaxAmount, err = parseAmountQuery(c, "max_amount"); err != nil {
+		return filter, err
+	}
+	if filter.Sort, err = repository.ParseSort(c.Query("sort"), repository.Sort{Field: "payment_date", Desc: true}, "payment_date", "amount"); err != nil {
+		return filter, err
+	}
+	filter.Cursor, filter.Limit, err = parsePageQuery(c)
+	return filter, err
+}
+
+// parsePageQuery reads the cursor and limit query parameters
+func parsePageQuery(c *gin.Context) (*repository.Cursor, int, error) {
+	var cursor *repository.Cursor
+	if encoded := c.Query("cursor"); encoded != "" {
+		var err error
+		if cursor, err = repository.DecodeCursor(encoded); err != nil {
+			return nil, 0, err
+		}
+	}
+	limit := 0
+	if param := c.Query("limit"); param != "" {
+		n, err := strconv.Atoi(param)
+		if err != nil || n < 1 || n > maxListPageSize {
+			return nil, 0, fmt.Errorf("limit must be between 1 and %d", maxListPageSize)
+		}
+		limit = n
+	}
+	return cursor, limit, nil
+}
+
+// parseDateQuery reads a YYYY-MM-DD query parameter as the start of that day in the user's timezone
+func parseDateQuery(c *gin.Context, name string) (*time.Time, error) {
+	param := c.Query(name)
+	if param == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, param, utils.LocationFromContext(c.Request.Context()))
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a date formatted as YYYY-MM-DD", name)
+	}
+	return &t, nil
+}
+
+// parseAmountQuery reads a non-negative amount query parameter
+func parseAmountQuery(c *gin.Context, name string) (*float64, error) {
+	param := c.Query(name)
+	if param == "" {
+		return nil, nil
+	}
+	amount, err := strconv.ParseFloat(param, 64)
+	if err != nil || amount < 0 {
+		return nil, errors.New(name + " must be a non-negative number")
+	}
+	return &amount, nil
+}
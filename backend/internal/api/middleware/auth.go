@@ -4,12 +4,19 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/cryptk/williams/internal/models"
 	"github.com/cryptk/williams/internal/services"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
 
-// AuthMiddleware creates a middleware that validates JWT tokens and optionally checks for required roles.
+// Authentication methods stored in the context under "auth_method"
+const (
+	AuthMethodJWT      = "jwt"
+	AuthMethodAPIToken = "api_token"
+)
+
+// AuthMiddleware creates a middleware that validates JWT tokens or personal access tokens and optionally checks for required roles.
 // If requiredRoles is empty, only authentication is checked.
 // If requiredRoles is provided, the user must have at least one of the specified roles.
 func AuthMiddleware(authService *services.AuthService, requiredRoles ...string) gin.HandlerFunc {
@@ -31,21 +38,66 @@ func AuthMiddleware(authService *services.AuthService, requiredRoles ...string)
 		}
 
 		token := parts[1]
-		user_claims, err := authService.ValidateToken(token)
-		if err != nil {
-			log.Warn().Err(err).Str("path", c.Request.URL.Path).Msg("Invalid or expired token")
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
-			c.Abort()
-			return
-		}
 
-		// Verify the user still exists in the database
-		_, err = authService.GetUserByID(user_claims.Subject)
-		if err != nil {
-			log.Warn().Err(err).Str("user_id", user_claims.Subject).Str("path", c.Request.URL.Path).Msg("User not found for valid token")
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
-			c.Abort()
-			return
+		var userID string
+		var userRoles []string
+		authMethod := AuthMethodJWT
+
+		if strings.HasPrefix(token, services.APITokenPrefix) {
+			// Personal access token
+			apiToken, user, err := authService.ValidateAPIToken(token)
+			if err != nil {
+				log.Warn().Err(err).Str("path", c.Request.URL.Path).Msg("Invalid or expired api token")
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+				c.Abort()
+				return
+			}
+
+			// Read-only tokens may only perform safe requests
+			if apiToken.Scope == models.APITokenScopeRead && !isReadOnlyMethod(c.Request.Method) {
+				log.Warn().
+					Str("user_id", user.ID).
+					Str("token_id", apiToken.ID).
+					Str("method", c.Request.Method).
+					Str("path", c.Request.URL.Path).
+					Msg("Read-only api token used for write request")
+				c.JSON(http.StatusForbidden, gin.H{"error": "token is read-only"})
+				c.Abort()
+				return
+			}
+
+			userID = user.ID
+			userRoles = user.Roles
+			authMethod = AuthMethodAPIToken
+			c.Set("api_token_id", apiToken.ID)
+		} else {
+			user_claims, err := authService.ValidateToken(token)
+			if err != nil {
+				log.Warn().Err(err).Str("path", c.Request.URL.Path).Msg("Invalid or expired token")
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+				c.Abort()
+				return
+			}
+
+			// Verify the user still exists in the database
+			user, err := authService.GetUserByID(user_claims.Subject)
+			if err != nil {
+				log.Warn().Err(err).Str("user_id", user_claims.Subject).Str("path", c.Request.URL.Path).Msg("User not found for valid token")
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+				c.Abort()
+				return
+			}
+
+			// Tokens issued before the last password change (or other session revocation) are rejected
+			if user_claims.TokenVersion != user.TokenVersion {
+				log.Warn().Str("user_id", user_claims.Subject).Str("path", c.Request.URL.Path).Msg("Token has been revoked")
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+				c.Abort()
+				return
+			}
+
+			userID = user_claims.Subject
+			userRoles = user_claims.Roles
 		}
 
 		// Check if user has required roles (if any specified)
@@ -53,7 +105,7 @@ func AuthMiddleware(authService *services.AuthService, requiredRoles ...string)
 		if len(requiredRoles) > 0 {
 			hasRequiredRole := false
 			for _, requiredRole := range requiredRoles {
-				for _, userRole := range user_claims.Roles {
+				for _, userRole := range userRoles {
 					if userRole == requiredRole {
 						hasRequiredRole = true
 						break
@@ -66,8 +118,8 @@ func AuthMiddleware(authService *services.AuthService, requiredRoles ...string)
 
 			if !hasRequiredRole {
 				log.Warn().
-					Str("user_id", user_claims.Subject).
-					Strs("user_roles", user_claims.Roles).
+					Str("user_id", userID).
+					Strs("user_roles", userRoles).
 					Strs("required_roles", requiredRoles).
 					Str("path", c.Request.URL.Path).
 					Msg("User does not have required role")
@@ -77,8 +129,40 @@ func AuthMiddleware(authService *services.AuthService, requiredRoles ...string)
 			}
 		}
 
-		c.Set("user_id", user_claims.Subject)
-		c.Set("user_roles", user_claims.Roles)
+		c.Set("user_id", userID)
+		c.Set("user_roles", userRoles)
+		c.Set("auth_method", authMethod)
+
+		// Record the authenticated user as the actor for audit events written by services
+		meta := services.RequestMetaFrom(c.Request.Context())
+		meta.ActorID = userID
+		c.Request = c.Request.WithContext(services.WithRequestMeta(c.Request.Context(), meta))
+
 		c.Next()
 	}
 }
+
+// RequireSessionMiddleware rejects requests authenticated with a personal access token. It guards admin
+// and account control routes so a leaked token cannot take over the account or act as an admin.
+// It must run after AuthMiddleware.
+func RequireSessionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_method") != AuthMethodJWT {
+			log.Warn().
+				Str("user_id", c.GetString("user_id")).
+				Str("token_id", c.GetString("api_token_id")).
+				Str("method", c.Request.Method).
+				Str("path", c.Request.URL.Path).
+				Msg("Api token used for a route that requires a session")
+			c.JSON(http.StatusForbidden, gin.H{"error": "api tokens cannot be used for this request, log in instead"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// isReadOnlyMethod reports whether an HTTP method does not modify data
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/cryptk/williams/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// RateLimitMiddleware limits requests per client IP using the given limiter
+func RateLimitMiddleware(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := limiter.Allow(c.ClientIP())
+		if err == nil {
+			c.Next()
+			return
+		}
+
+		var limitErr *ratelimit.LimitError
+		if !errors.As(err, &limitErr) {
+			// Fail open: a broken limiter store must not take authentication down with it
+			log.Error().Err(err).Msg("Rate limiter failed")
+			c.Next()
+			return
+		}
+
+		log.Warn().
+			Str("client_ip", c.ClientIP()).
+			Str("path", c.Request.URL.Path).
+			Dur("retry_after", limitErr.RetryAfter).
+			Msg("Rate limit exceeded")
+		AbortWithLimitError(c, limitErr)
+	}
+}
+
+// AbortWithLimitError responds with 429 Too Many Requests and a Retry-After header
+func AbortWithLimitError(c *gin.Context, limitErr *ratelimit.LimitError) {
+	seconds := int(math.Ceil(limitErr.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": limitErr.Error()})
+}
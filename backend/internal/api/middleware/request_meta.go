@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"github.com/cryptk/williams/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestMetaMiddleware stores the client IP and user agent in the request context for audit events
+func RequestMetaMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		meta := services.RequestMeta{
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		c.Request = c.Request.WithContext(services.WithRequestMeta(c.Request.Context(), meta))
+		c.Next()
+	}
+}
@@ -33,7 +33,8 @@ func ScopedDBMiddleware(db *database.DB) gin.HandlerFunc {
 			return
 		}
 
-		scopedDB := db.Scopes(TenantScoped(userIDStr))
+		// Carry the request context so services can read audit metadata from the scoped DB
+		scopedDB := db.WithContext(c.Request.Context()).Scopes(TenantScoped(userIDStr))
 		c.Set("scoped_db", scopedDB)
 
 		c.Next()
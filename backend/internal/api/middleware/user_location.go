@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/cryptk/williams/internal/services"
+	"github.com/cryptk/williams/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// UserLocationMiddleware stores the authenticated user's timezone in the request context and on the
+// scoped DB, so due dates are calculated in the user's timezone. It must run after ScopedDBMiddleware.
+func UserLocationMiddleware(preferences *services.PreferenceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopedDB := GetScopedDB(c)
+		if scopedDB == nil {
+			c.Next()
+			return
+		}
+
+		ctx := utils.WithLocation(c.Request.Context(), preferences.Location(scopedDB))
+		c.Request = c.Request.WithContext(ctx)
+		c.Set(ScopedDBKey, scopedDB.WithContext(ctx))
+		c.Next()
+	}
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// oidcFlowCookie holds the signed state, nonce and PKCE verifier during an SSO login
+const oidcFlowCookie = "williams_oidc_flow"
+
+// oidcCookiePath limits the flow cookie to the SSO endpoints
+const oidcCookiePath = "/api/v1/auth/oidc"
+
+// SSO handlers
+
+func (s *Server) getOIDCInfo(c *gin.Context) {
+	info := models.OIDCInfoResponse{Enabled: s.oidcService.Enabled()}
+	if info.Enabled {
+		info.ProviderName = s.oidcService.ProviderName()
+	}
+	c.JSON(http.StatusOK, info)
+}
+
+func (s *Server) oidcLogin(c *gin.Context) {
+	authURL, flowToken, err := s.oidcService.BeginLogin()
+	if errors.Is(err, services.ErrOIDCDisabled) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to start SSO login")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "identity provider unavailable"})
+		return
+	}
+
+	// SameSite=Lax so the cookie is sent on the top-level redirect back from the identity provider
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookie, flowToken, int(services.OIDCFlowTTL.Seconds()), oidcCookiePath, "", isSecureRequest(c), true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+func (s *Server) oidcLink(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	authURL, flowToken, err := s.oidcService.BeginLink(userID)
+	if errors.Is(err, services.ErrOIDCDisabled) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to start SSO link")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "identity provider unavailable"})
+		return
+	}
+
+	// The frontend navigates to the returned URL; the callback completes the link and logs the user in
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookie, flowToken, int(services.OIDCFlowTTL.Seconds()), oidcCookiePath, "", isSecureRequest(c), true)
+	c.JSON(http.StatusOK, gin.H{"url": authURL})
+}
+
+func (s *Server) oidcCallback(c *gin.Context) {
+	// The flow cookie is single use regardless of the outcome
+	flowToken, _ := c.Cookie(oidcFlowCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookie, "", -1, oidcCookiePath, "", isSecureRequest(c), true)
+
+	if errParam := c.Query("error"); errParam != "" {
+		log.Warn().Str("error", errParam).Str("description", c.Query("error_description")).Msg("Identity provider returned an error")
+		s.redirectAfterSSO(c, url.Values{"error": {"single sign-on was cancelled or denied"}})
+		return
+	}
+
+	token, user, err := s.oidcService.CompleteLogin(c.Request.Context(), flowToken, c.Query("state"), c.Query("code"))
+	if err != nil {
+		log.Warn().Err(err).Msg("SSO login failed")
+		s.redirectAfterSSO(c, url.Values{"error": {err.Error()}})
+		return
+	}
+
+	log.Info().Str("user_id", user.ID).Str("username", user.Username).Msg("User logged in via SSO")
+	s.redirectAfterSSO(c, url.Values{"token": {token}})
+}
+
+// redirectAfterSSO sends the browser back to the frontend with the result in the URL fragment,
+// which keeps the token out of server logs and Referer headers
+func (s *Server) redirectAfterSSO(c *gin.Context, result url.Values) {
+	c.Redirect(http.StatusFound, s.oidcService.PostLoginRedirect()+"#"+result.Encode())
+}
+
+// isSecureRequest reports whether the request reached us (or the reverse proxy) over HTTPS
+func isSecureRequest(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
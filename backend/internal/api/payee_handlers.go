@@ -0,0 +1,128 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Payee handlers
+
+func (s *Server) listPayees(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	payees, err := s.payeeService.List(scopedDB)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to list payees")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve payees"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payees": payees})
+}
+
+func (s *Server) createPayee(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payee models.Payee
+	if err := c.ShouldBindJSON(&payee); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	payee.ID = ""
+	payee.UserID = userID // Set user ID from authenticated context
+
+	if err := s.payeeService.Create(scopedDB, &payee); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("Failed to create payee")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, payee)
+}
+
+func (s *Server) getPayee(c *gin.Context) {
+	id := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	payee, err := s.payeeService.Get(scopedDB, id)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Str("payee_id", id).Msg("Failed to get payee")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Payee not found",
+			"id":    id,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, payee)
+}
+
+func (s *Server) updatePayee(c *gin.Context) {
+	id := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payee models.Payee
+	if err := c.ShouldBindJSON(&payee); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	payee.ID = id
+
+	if err := s.payeeService.Update(scopedDB, &payee); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("payee_id", id).Msg("Failed to update payee")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Payee not found",
+			"id":    id,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, payee)
+}
+
+func (s *Server) deletePayee(c *gin.Context) {
+	id := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := s.payeeService.Delete(scopedDB, id); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("payee_id", id).Msg("Failed to delete payee")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Payee not found",
+			"id":    id,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payee deleted successfully",
+		"id":      id,
+	})
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Bill pause handlers
+
+func (s *Server) listBillPauses(c *gin.Context) {
+	billID := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	pauses, err := s.billService.ListPauses(scopedDB, billID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Str("bill_id", billID).Msg("Failed to list bill pauses")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Bill not found",
+			"id":    billID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pauses": pauses})
+}
+
+func (s *Server) createBillPause(c *gin.Context) {
+	billID := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var pause models.BillPause
+	if err := c.ShouldBindJSON(&pause); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pause.BillID = billID
+	pause.UserID = userID // Set user ID from authenticated context
+
+	if err := s.billService.Pause(scopedDB, &pause); err != nil {
+		if isNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Warn().Err(err).Str("user_id", userID).Str("bill_id", billID).Msg("Failed to pause bill")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, pause)
+}
+
+func (s *Server) deleteBillPause(c *gin.Context) {
+	billID := c.Param("id")
+	pauseID := c.Param("pause_id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := s.billService.Unpause(scopedDB, billID, pauseID); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("pause_id", pauseID).Msg("Failed to delete bill pause")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Pause not found",
+			"id":    pauseID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pause deleted successfully",
+		"id":      pauseID,
+	})
+}
+
+// Bill skip handlers
+
+func (s *Server) listBillSkips(c *gin.Context) {
+	billID := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	skips, err := s.billService.ListSkips(scopedDB, billID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Str("bill_id", billID).Msg("Failed to list skipped occurrences")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Bill not found",
+			"id":    billID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"skips": skips})
+}
+
+func (s *Server) createBillSkip(c *gin.Context) {
+	billID := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var skip models.BillSkip
+	if err := c.ShouldBindJSON(&skip); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	skip.BillID = billID
+	skip.UserID = userID // Set user ID from authenticated context
+
+	if err := s.billService.Skip(scopedDB, &skip); err != nil {
+		if isNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Warn().Err(err).Str("user_id", userID).Str("bill_id", billID).Msg("Failed to skip bill occurrence")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, skip)
+}
+
+func (s *Server) deleteBillSkip(c *gin.Context) {
+	billID := c.Param("id")
+	skipID := c.Param("skip_id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := s.billService.Unskip(scopedDB, billID, skipID); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("skip_id", skipID).Msg("Failed to delete skipped occurrence")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Skip not found",
+			"id":    skipID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Skip deleted successfully",
+		"id":      skipID,
+	})
+}
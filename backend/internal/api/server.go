@@ -11,20 +11,39 @@ import (
 	"github.com/cryptk/williams/internal/api/middleware"
 	"github.com/cryptk/williams/internal/config"
 	"github.com/cryptk/williams/internal/database"
+	"github.com/cryptk/williams/internal/mailer"
 	"github.com/cryptk/williams/internal/repository"
 	"github.com/cryptk/williams/internal/services"
+	"github.com/cryptk/williams/internal/storage"
+	"github.com/cryptk/williams/pkg/holidays"
+	"github.com/cryptk/williams/pkg/ratelimit"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
 )
 
 // Server represents the API server
 type Server struct {
-	config          *config.Config
-	router          *gin.Engine
-	httpServer      *http.Server
-	authService     *services.AuthService
-	billService     *services.BillService
-	categoryService *services.CategoryService
+	config                *config.Config
+	router                *gin.Engine
+	httpServer            *http.Server
+	authService           *services.AuthService
+	billService           *services.BillService
+	categoryService       *services.CategoryService
+	ledgerService         *services.LedgerService
+	tagService            *services.TagService
+	payeeService          *services.PayeeService
+	fundingAccountService *services.FundingAccountService
+	preferenceService     *services.PreferenceService
+	holidayService        *services.HolidayService
+	oidcService           *services.OIDCService
+	accountService        *services.AccountService
+	auditService          *services.AuditService
+	trashService          *services.TrashService
+	autopayService        *services.AutopayService
+	attachmentService     *services.AttachmentService
+	authLimiter           *ratelimit.Limiter
+	stopBackground        context.CancelFunc
 }
 
 // NewServer creates a new API server
@@ -38,6 +57,16 @@ func NewServer(cfg *config.Config, db *database.DB) *Server {
 
 	router := gin.New()
 
+	// Only honour X-Forwarded-For from known proxies, so client IPs used for rate limiting and
+	// audit events cannot be spoofed. Without configured proxies the connection's address is used.
+	var trustedProxies []string
+	if len(cfg.Server.TrustedProxies) > 0 {
+		trustedProxies = cfg.Server.TrustedProxies
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatal().Err(err).Msg("Invalid trusted proxies configuration")
+	}
+
 	// Add request logging middleware
 	router.Use(func(c *gin.Context) {
 		start := time.Now()
@@ -67,6 +96,9 @@ func NewServer(cfg *config.Config, db *database.DB) *Server {
 	// Add recovery middleware
 	router.Use(gin.Recovery())
 
+	// Capture client IP and user agent for audit events
+	router.Use(middleware.RequestMetaMiddleware())
+
 	// Enable CORS for frontend
 	router.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
@@ -84,21 +116,77 @@ func NewServer(cfg *config.Config, db *database.DB) *Server {
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db.DB)
-	billRepo := repository.NewBillRepository()
+	billRepo := repository.NewBillRepository(db.DB)
 	categoryRepo := repository.NewCategoryRepository(db.DB)
+	tagRepo := repository.NewTagRepository()
+	payeeRepo := repository.NewPayeeRepository()
+	fundingAccountRepo := repository.NewFundingAccountRepository()
+	preferenceRepo := repository.NewPreferenceRepository()
 	paymentRepo := repository.NewPaymentRepository()
+	billVersionRepo := repository.NewBillVersionRepository()
+	billScheduleRepo := repository.NewBillScheduleRepository()
+	billLoanRepo := repository.NewBillLoanRepository()
+	billStatementRepo := repository.NewBillStatementRepository()
+	lateFeeRepo := repository.NewLateFeeRepository()
+	holidayRepo := repository.NewHolidayRepository()
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(db.DB)
+	identityRepo := repository.NewIdentityRepository(db.DB)
+	apiTokenRepo := repository.NewAPITokenRepository(db.DB)
+	userTokenRepo := repository.NewUserTokenRepository(db.DB)
+	userDataRepo := repository.NewUserDataRepository(db.DB)
+	auditRepo := repository.NewAuditRepository(db.DB)
+	attachmentRepo := repository.NewAttachmentRepository(db.DB)
+
+	// Rate limit counters are kept in memory; the store interface allows swapping in a shared backend
+	limitStore := ratelimit.NewMemoryStore()
+
+	// Holiday calendars are bundled with the application and can be extended from a directory
+	holidayRegistry, err := holidays.NewRegistry(cfg.Bills.HolidayCalendarsPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load holiday calendars")
+	}
+
+	// Attachments are kept on the local filesystem or in an S3-compatible object store
+	attachmentStore, err := storage.New(&cfg.Attachments)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize attachment storage")
+	}
+
+	// Background jobs that act on behalf of a user use the same tenant scope as API requests
+	tenantDB := func(userID string) *gorm.DB {
+		return db.DB.Scopes(middleware.TenantScoped(userID))
+	}
 
 	// Initialize services
-	authService := services.NewAuthService(userRepo, categoryRepo, cfg.Auth.JWTSecret, cfg.Auth.FirstUserIsAdmin)
-	billService := services.NewBillService(billRepo, paymentRepo, cfg)
-	categoryService := services.NewCategoryService(categoryRepo)
+	auditService := services.NewAuditService(auditRepo, &cfg.Audit)
+	authService := services.NewAuthService(userRepo, categoryRepo, recoveryCodeRepo, apiTokenRepo, limitStore, auditService, &cfg.Auth)
+	holidayService := services.NewHolidayService(holidayRegistry, holidayRepo, auditService)
+	billService := services.NewBillService(billRepo, paymentRepo, billVersionRepo, billScheduleRepo, billLoanRepo, billStatementRepo, lateFeeRepo, tagRepo, payeeRepo, holidayService, auditService, cfg)
+	categoryService := services.NewCategoryService(categoryRepo, auditService)
+	ledgerService := services.NewLedgerService(billService, billRepo, paymentRepo, categoryRepo)
+	oidcService := services.NewOIDCService(authService, userRepo, identityRepo, auditService, &cfg.Auth.OIDC)
+	preferenceService := services.NewPreferenceService(preferenceRepo, auditService)
+	accountService := services.NewAccountService(authService, userRepo, userTokenRepo, userDataRepo, preferenceService, mailer.New(&cfg.Email), auditService, cfg.Server.PublicURL, cfg.Auth.DeletionGracePeriod)
 
 	server := &Server{
-		config:          cfg,
-		router:          router,
-		authService:     authService,
-		billService:     billService,
-		categoryService: categoryService,
+		config:                cfg,
+		router:                router,
+		authService:           authService,
+		billService:           billService,
+		categoryService:       categoryService,
+		ledgerService:         ledgerService,
+		tagService:            services.NewTagService(tagRepo, ledgerService, auditService),
+		payeeService:          services.NewPayeeService(payeeRepo, billService, auditService),
+		fundingAccountService: services.NewFundingAccountService(fundingAccountRepo, billRepo, paymentRepo, billService, auditService),
+		preferenceService:     preferenceService,
+		holidayService:        holidayService,
+		oidcService:           oidcService,
+		accountService:        accountService,
+		auditService:          auditService,
+		trashService:          services.NewTrashService(billRepo, categoryRepo, cfg.Bills.TrashRetention),
+		autopayService:        services.NewAutopayService(billService, billRepo, preferenceService, tenantDB, cfg.Bills.AutopayInterval),
+		attachmentService:     services.NewAttachmentService(attachmentRepo, billRepo, paymentRepo, attachmentStore, auditService, &cfg.Attachments),
+		authLimiter:           ratelimit.NewLimiter(limitStore, "auth_ip", cfg.Auth.RateLimit.IPRequests, cfg.Auth.RateLimit.IPWindow),
 	}
 
 	server.setupRoutes(db)
@@ -117,30 +205,109 @@ func (s *Server) setupRoutes(db *database.DB) {
 	{
 		// Public auth endpoints
 		auth := v1.Group("/auth")
+		auth.Use(middleware.RateLimitMiddleware(s.authLimiter))
 		{
 			auth.POST("/register", s.register)
 			auth.POST("/login", s.login)
+			auth.POST("/2fa/verify", s.verifyMFA)
+			auth.GET("/oidc", s.getOIDCInfo)
+			auth.GET("/oidc/login", s.oidcLogin)
+			auth.GET("/oidc/callback", s.oidcCallback)
+			auth.POST("/password/forgot", s.forgotPassword)
+			auth.POST("/password/reset", s.resetPassword)
+			auth.POST("/email/verify", s.verifyEmail)
 		}
 
 		// Protected routes (require authentication)
 		protected := v1.Group("")
 		protected.Use(middleware.AuthMiddleware(s.authService))
 		protected.Use(middleware.ScopedDBMiddleware(db))
+		protected.Use(middleware.UserLocationMiddleware(s.preferenceService))
 		{
 			// User endpoints
 			protected.GET("/auth/me", s.getCurrentUser)
+			protected.POST("/auth/email/verify/resend", s.resendVerificationEmail)
+
+			// Account control endpoints; a leaked api token must not be able to take over the account, and
+			// those re-checking the password are rate limited like login
+			protected.POST("/auth/password", middleware.RequireSessionMiddleware(), middleware.RateLimitMiddleware(s.authLimiter), s.changePassword)
+			protected.POST("/auth/oidc/link", middleware.RequireSessionMiddleware(), s.oidcLink)
+
+			// Account self-service endpoints
+			me := protected.Group("/me")
+			me.Use(middleware.RequireSessionMiddleware())
+			{
+				me.PUT("", middleware.RateLimitMiddleware(s.authLimiter), s.updateProfile)
+				me.DELETE("", middleware.RateLimitMiddleware(s.authLimiter), s.deleteAccount)
+				me.POST("/restore", s.restoreAccount)
+				me.GET("/export", s.exportData)
+				me.GET("/preferences", s.getPreferences)
+				me.PUT("/preferences", s.updatePreferences)
+			}
+
+			// Two-factor authentication endpoints; those re-checking a code are rate limited like login
+			twoFactor := protected.Group("/auth/2fa")
+			twoFactor.Use(middleware.RequireSessionMiddleware())
+			{
+				twoFactor.POST("/setup", s.setupTOTP)
+				twoFactor.POST("/enable", s.enableTOTP)
+				twoFactor.POST("/disable", middleware.RateLimitMiddleware(s.authLimiter), s.disableTOTP)
+				twoFactor.POST("/recovery-codes", middleware.RateLimitMiddleware(s.authLimiter), s.regenerateRecoveryCodes)
+			}
+
+			// Personal access token endpoints
+			tokens := protected.Group("/tokens")
+			{
+				tokens.GET("", s.listAPITokens)
+				tokens.POST("", middleware.RequireSessionMiddleware(), s.createAPIToken)
+				tokens.DELETE("/:id", s.revokeAPIToken)
+			}
 
 			// Bills endpoints
 			bills := protected.Group("/bills")
 			{
 				bills.GET("", s.listBills)
+				bills.GET("/trash", s.listBillTrash)
 				bills.GET("/:id", s.getBill)
 				bills.POST("", s.createBill)
 				bills.PUT("/:id", s.updateBill)
 				bills.DELETE("/:id", s.deleteBill)
+				bills.GET("/:id/history", s.getBillHistory)
+				bills.GET("/:id/amortization", s.getBillAmortization)
+				bills.POST("/:id/archive", s.archiveBill)
+				bills.POST("/:id/unarchive", s.unarchiveBill)
+				bills.POST("/:id/restore", s.restoreBill)
+				bills.GET("/:id/pauses", s.listBillPauses)
+				bills.POST("/:id/pauses", s.createBillPause)
+				bills.DELETE("/:id/pauses/:pause_id", s.deleteBillPause)
+				bills.GET("/:id/skips", s.listBillSkips)
+				bills.POST("/:id/skips", s.createBillSkip)
+				bills.DELETE("/:id/skips/:skip_id", s.deleteBillSkip)
+				bills.GET("/:id/late-fees/waivers", s.listLateFeeWaivers)
+				bills.POST("/:id/late-fees/waivers", s.createLateFeeWaiver)
+				bills.DELETE("/:id/late-fees/waivers/:waiver_id", s.deleteLateFeeWaiver)
+				bills.GET("/:id/statements", s.listBillStatements)
+				bills.POST("/:id/statements", s.createBillStatement)
+				bills.POST("/:id/statements/import", s.importBillStatements)
+				bills.DELETE("/:id/statements/:statement_id", s.deleteBillStatement)
 				bills.POST("/:id/payments", s.createPayment)
 				bills.GET("/:id/payments", s.listPayments)
 				bills.DELETE("/:id/payments/:payment_id", s.deletePayment)
+				bills.POST("/:id/payments/:payment_id/reverse", s.reversePayment)
+				bills.GET("/:id/attachments", s.listBillAttachments)
+				bills.POST("/:id/attachments", s.uploadBillAttachment)
+				bills.GET("/:id/payments/:payment_id/attachments", s.listPaymentAttachments)
+				bills.POST("/:id/payments/:payment_id/attachments", s.uploadPaymentAttachment)
+			}
+
+			// Payment ledger across all bills
+			protected.GET("/payments", s.listAllPayments)
+
+			// Attachment endpoints; uploads and listings live under the bill they belong to
+			attachments := protected.Group("/attachments")
+			{
+				attachments.GET("/:id/download", s.downloadAttachment)
+				attachments.DELETE("/:id", s.deleteAttachment)
 			}
 
 			// Categories endpoints
@@ -149,14 +316,73 @@ func (s *Server) setupRoutes(db *database.DB) {
 				categories.GET("", s.listCategories)
 				categories.POST("", s.createCategory)
 				categories.DELETE("/:id", s.deleteCategory)
+				categories.GET("/trash", s.listCategoryTrash)
+				categories.POST("/:id/archive", s.archiveCategory)
+				categories.POST("/:id/unarchive", s.unarchiveCategory)
+				categories.POST("/:id/restore", s.restoreCategory)
+			}
+
+			// Tag endpoints
+			tags := protected.Group("/tags")
+			{
+				tags.GET("", s.listTags)
+				tags.POST("", s.createTag)
+				tags.PUT("/:id", s.updateTag)
+				tags.DELETE("/:id", s.deleteTag)
+				tags.GET("/payments", s.getTagPaymentReport)
+			}
+
+			// Payee endpoints
+			payees := protected.Group("/payees")
+			{
+				payees.GET("", s.listPayees)
+				payees.POST("", s.createPayee)
+				payees.GET("/:id", s.getPayee)
+				payees.PUT("/:id", s.updatePayee)
+				payees.DELETE("/:id", s.deletePayee)
+			}
+
+			// Funding account endpoints
+			accounts := protected.Group("/accounts")
+			{
+				accounts.GET("", s.listFundingAccounts)
+				accounts.POST("", s.createFundingAccount)
+				accounts.GET("/projection", s.getBalanceProjection)
+				accounts.GET("/:id", s.getFundingAccount)
+				accounts.PUT("/:id", s.updateFundingAccount)
+				accounts.DELETE("/:id", s.deleteFundingAccount)
 			}
 
+			// Holiday calendar endpoints
+			holidayRoutes := protected.Group("/holidays")
+			{
+				holidayRoutes.GET("", s.listUserHolidays)
+				holidayRoutes.POST("", s.createUserHoliday)
+				holidayRoutes.DELETE("/:id", s.deleteUserHoliday)
+				holidayRoutes.GET("/calendars", s.listHolidayCalendars)
+				holidayRoutes.GET("/calendars/:country", s.getHolidayCalendar)
+			}
+
+			// Audit log endpoints
+			protected.GET("/audit", s.listAuditEvents)
+
 			// Statistics endpoints
 			stats := protected.Group("/stats")
 			{
 				stats.GET("/summary", s.getStatsSummary)
+				stats.GET("/forecast", s.getForecast)
+				stats.GET("/price-increases", s.getPriceIncreases)
 			}
 		}
+
+		// Admin routes (require the admin role)
+		admin := v1.Group("/admin")
+		admin.Use(middleware.AuthMiddleware(s.authService, "admin"))
+		admin.Use(middleware.RequireSessionMiddleware())
+		{
+			admin.DELETE("/users/:id/2fa", s.resetUserTOTP)
+			admin.GET("/audit", s.listAllAuditEvents)
+		}
 	}
 
 	assetsPath := filepath.Clean(s.config.Server.StaticAssetsPath)
@@ -191,6 +417,15 @@ func (s *Server) Start() error {
 	}
 	addr := fmt.Sprintf("%s:%d", host, s.config.Server.Port)
 
+	// Start background jobs; they are stopped on Shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopBackground = cancel
+	s.auditService.StartRetention(ctx)
+	s.trashService.StartPurge(ctx)
+	s.autopayService.Start(ctx)
+	s.attachmentService.StartCleanup(ctx)
+	s.accountService.StartDeletionPurge(ctx)
+
 	s.httpServer = &http.Server{
 		Addr:           addr,
 		Handler:        s.router,
@@ -204,6 +439,10 @@ func (s *Server) Start() error {
 
 // Shutdown gracefully shuts down the server
 func (s *Server) Shutdown() error {
+	if s.stopBackground != nil {
+		s.stopBackground()
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
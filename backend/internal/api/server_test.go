@@ -0,0 +1,175 @@
+package api
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cryptk/williams/internal/config"
+	"github.com/cryptk/williams/internal/database"
+	"github.com/cryptk/williams/internal/encryption"
+	"github.com/cryptk/williams/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// testPassword is the password every test user registers with
+const testPassword = "Passw0rd!234"
+
+func TestMain(m *testing.M) {
+	// Every query and request is logged, which would drown out the results
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+	gin.SetMode(gin.TestMode)
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	cipher, err := encryption.New(&config.EncryptionConfig{Key: base64.StdEncoding.EncodeToString(key)})
+	if err != nil {
+		panic(err)
+	}
+	encryption.Use(cipher)
+
+	os.Exit(m.Run())
+}
+
+// testServer is the API server with the default configuration and a migrated SQLite database
+type testServer struct {
+	*Server
+	db *database.DB
+}
+
+// newTestServer starts from the default configuration, applying configure to it if given
+func newTestServer(t *testing.T, configure ...func(*config.Config)) *testServer {
+	t.Helper()
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	cfg.Database = config.DatabaseConfig{Driver: "sqlite", DSN: filepath.Join(dir, "williams.db")}
+	cfg.Attachments.LocalPath = filepath.Join(dir, "attachments")
+	cfg.Server.StaticAssetsPath = dir
+	// Every test request comes from the same address
+	cfg.Auth.RateLimit.IPRequests = 10000
+	for _, fn := range configure {
+		fn(cfg)
+	}
+
+	db, err := database.New(&cfg.Database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.RunMigrations(); err != nil {
+		t.Fatal(err)
+	}
+	return &testServer{Server: NewServer(cfg, db), db: db}
+}
+
+// request sends a request with an optional bearer token and JSON body and returns the response
+func (ts *testServer) request(t *testing.T, method, path, token string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reader).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	ts.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// upload sends a multipart request with content in the "file" field and returns the response
+func (ts *testServer) upload(t *testing.T, path, token, fileName string, content []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	ts.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// decode unmarshals a JSON response into dest, failing the test unless it has the wanted status
+func decode(t *testing.T, rec *httptest.ResponseRecorder, status int, dest any) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("got status %d, want %d: %s", rec.Code, status, rec.Body.String())
+	}
+	if dest != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), dest); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// register creates a user and returns their session token and user
+func (ts *testServer) register(t *testing.T, username string) (string, *models.User) {
+	t.Helper()
+	var resp models.AuthResponse
+	decode(t, ts.request(t, http.MethodPost, "/api/v1/auth/register", "", gin.H{
+		"username": username,
+		"email":    username + "@example.com",
+		"password": testPassword,
+	}), http.StatusCreated, &resp)
+	return resp.Token, &resp.User
+}
+
+// login returns a new session token for a user
+func (ts *testServer) login(t *testing.T, username string) string {
+	t.Helper()
+	var resp models.AuthResponse
+	decode(t, ts.request(t, http.MethodPost, "/api/v1/auth/login", "", gin.H{
+		"username": username,
+		"password": testPassword,
+	}), http.StatusOK, &resp)
+	return resp.Token
+}
+
+// createAPIToken creates a personal access token with the given scope using a session token
+func (ts *testServer) createAPIToken(t *testing.T, session, scope string) string {
+	t.Helper()
+	var resp models.CreateAPITokenResponse
+	decode(t, ts.request(t, http.MethodPost, "/api/v1/tokens", session, gin.H{
+		"name":  "test",
+		"scope": scope,
+	}), http.StatusCreated, &resp)
+	return resp.Token
+}
+
+// makeAdmin gives a user the admin role and returns a session token carrying it
+func (ts *testServer) makeAdmin(t *testing.T, user *models.User) string {
+	t.Helper()
+	if err := ts.db.Model(&models.User{}).Where("id = ?", user.ID).
+		Update("roles", `["user","admin"]`).Error; err != nil {
+		t.Fatal(err)
+	}
+	return ts.login(t, user.Username)
+}
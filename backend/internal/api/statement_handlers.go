@@ -0,0 +1,119 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Bill statement handlers
+
+func (s *Server) listBillStatements(c *gin.Context) {
+	billID := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	statements, err := s.billService.ListStatements(scopedDB, billID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Str("bill_id", billID).Msg("Failed to list bill statements")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Bill not found",
+			"id":    billID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statements": statements})
+}
+
+func (s *Server) createBillStatement(c *gin.Context) {
+	billID := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var statement models.BillStatement
+	if err := c.ShouldBindJSON(&statement); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	statement.BillID = billID
+
+	if err := s.billService.SaveStatement(scopedDB, &statement); err != nil {
+		if isNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Warn().Err(err).Str("user_id", userID).Str("bill_id", billID).Msg("Failed to save bill statement")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, statement)
+}
+
+func (s *Server) importBillStatements(c *gin.Context) {
+	billID := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req models.ImportStatementsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.billService.ImportStatements(scopedDB, billID, req.Statements); err != nil {
+		if isNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Warn().Err(err).Str("user_id", userID).Str("bill_id", billID).Msg("Failed to import bill statements")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statements": req.Statements,
+		"total":      len(req.Statements),
+	})
+}
+
+func (s *Server) deleteBillStatement(c *gin.Context) {
+	billID := c.Param("id")
+	statementID := c.Param("statement_id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := s.billService.DeleteStatement(scopedDB, billID, statementID); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("statement_id", statementID).Msg("Failed to delete bill statement")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Statement not found",
+			"id":    statementID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Statement deleted successfully",
+		"id":      statementID,
+	})
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/services"
+	"github.com/cryptk/williams/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Tag handlers
+
+func (s *Server) listTags(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	tags, err := s.tagService.List(scopedDB)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to list tags")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+func (s *Server) createTag(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var tag models.Tag
+	if err := c.ShouldBindJSON(&tag); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tag.ID = ""
+	tag.UserID = userID // Set user ID from authenticated context
+
+	if err := s.tagService.Create(scopedDB, &tag); err != nil {
+		if errors.Is(err, services.ErrTagExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Warn().Err(err).Str("user_id", userID).Msg("Failed to create tag")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+func (s *Server) updateTag(c *gin.Context) {
+	id := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var tag models.Tag
+	if err := c.ShouldBindJSON(&tag); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tag.ID = id
+
+	if err := s.tagService.Update(scopedDB, &tag); err != nil {
+		if errors.Is(err, services.ErrTagExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Warn().Err(err).Str("user_id", userID).Str("tag_id", id).Msg("Failed to update tag")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Tag not found",
+			"id":    id,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+func (s *Server) deleteTag(c *gin.Context) {
+	id := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := s.tagService.Delete(scopedDB, id); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("tag_id", id).Msg("Failed to delete tag")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Tag not found",
+			"id":    id,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tag deleted successfully",
+		"id":      id,
+	})
+}
+
+// getTagPaymentReport reports a year's payments grouped by tag, as JSON or, with format=csv, as a
+// spreadsheet download
+func (s *Server) getTagPaymentReport(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	// Defaults to the previous year, the one taxes are usually filed for
+	year, err := strconv.Atoi(c.DefaultQuery("year", strconv.Itoa(time.Now().In(utils.LocationFromContext(c.Request.Context())).Year()-1)))
+	if err != nil || year < 1900 || year > 9999 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "year must be a four-digit year"})
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	tagID := c.Query("tag_id")
+	report, err := s.tagService.PaymentReport(scopedDB, year, tagID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("tag_id", tagID).Msg("Failed to build tag payment report")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Tag not found",
+			"id":    tagID,
+		})
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=tag-payments-%d.csv", year))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	loc := utils.LocationFromContext(c.Request.Context())
+	_ = w.Write([]string{"tag", "payment_date", "bill", "category", "amount", "notes"})
+	for _, group := range report.Tags {
+		for _, entry := range group.Payments {
+			_ = w.Write([]string{
+				csvText(group.Tag.Name),
+				entry.PaymentDate.In(loc).Format("2006-01-02"),
+				csvText(entry.BillName),
+				csvText(entry.CategoryName),
+				strconv.FormatFloat(entry.Amount, 'f', 2, 64),
+				csvText(entry.Notes),
+			})
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to write tag payment report")
+	}
+}
+
+// csvText keeps user-entered text from being run as a formula when the CSV is opened in a spreadsheet
+func csvText(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
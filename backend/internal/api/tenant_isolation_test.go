@@ -0,0 +1,315 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// victimMarker is part of the name of every record the victim creates, so listings that leak them are easy to spot
+const victimMarker = "Victim"
+
+// victimRecords are the IDs of one of each record a user can own, created through the API
+type victimRecords struct {
+	category, trashedCategory      string
+	tag, payee, account, holiday   string
+	bill, loanBill, trashedBill    string
+	statement, waiver, pause, skip string
+	payment, billAttachment        string
+	paymentAttachment, token       string
+	billIDs, allIDs                []string
+}
+
+// createRecord posts body to path as the user and returns the ID of the created record
+func (ts *testServer) createRecord(t *testing.T, session, path string, body any) string {
+	t.Helper()
+	var record struct {
+		ID string `json:"id"`
+	}
+	decode(t, ts.request(t, http.MethodPost, path, session, body), http.StatusCreated, &record)
+	return record.ID
+}
+
+// newVictim creates a user with one of each record through the API
+func (ts *testServer) newVictim(t *testing.T, session string) *victimRecords {
+	t.Helper()
+	v := &victimRecords{}
+	v.category = ts.createRecord(t, session, "/api/v1/categories", gin.H{"name": victimMarker + " Housing"})
+	v.trashedCategory = ts.createRecord(t, session, "/api/v1/categories", gin.H{"name": victimMarker + " Old"})
+	v.tag = ts.createRecord(t, session, "/api/v1/tags", gin.H{"name": victimMarker + " Essential"})
+	v.payee = ts.createRecord(t, session, "/api/v1/payees", gin.H{"name": victimMarker + " Landlord"})
+	v.account = ts.createRecord(t, session, "/api/v1/accounts", gin.H{"name": victimMarker + " Checking", "current_balance": 1000})
+	v.holiday = ts.createRecord(t, session, "/api/v1/holidays", gin.H{"date": "2026-12-24", "name": victimMarker + " Eve"})
+
+	v.bill = ts.createRecord(t, session, "/api/v1/bills", gin.H{
+		"name":            victimMarker + " Rent",
+		"amount":          1200,
+		"recurrence_type": "fixed_date",
+		"recurrence_days": 5,
+		"variable_amount": true,
+		"category_id":     v.category,
+		"payee_id":        v.payee,
+		"account_id":      v.account,
+		"tag_ids":         []string{v.tag},
+		"late_fee_rule":   gin.H{"flat_fee": 25, "grace_days": 3},
+	})
+	v.loanBill = ts.createRecord(t, session, "/api/v1/bills", gin.H{
+		"name":            victimMarker + " Car",
+		"kind":            models.BillKindLoan,
+		"recurrence_type": "fixed_date",
+		"recurrence_days": 15,
+		"loan": gin.H{
+			"principal":          10000,
+			"interest_rate":      5,
+			"term_payments":      36,
+			"first_payment_date": "2026-01-15T00:00:00Z",
+		},
+	})
+	v.trashedBill = ts.createRecord(t, session, "/api/v1/bills", gin.H{
+		"name":            victimMarker + " Gym",
+		"amount":          30,
+		"recurrence_type": "fixed_date",
+		"recurrence_days": 1,
+	})
+
+	billPath := "/api/v1/bills/" + v.bill
+	v.statement = ts.createRecord(t, session, billPath+"/statements", gin.H{"due_date": "2026-10-05T00:00:00Z", "amount": 1250})
+	v.waiver = ts.createRecord(t, session, billPath+"/late-fees/waivers", gin.H{"due_date": "2026-09-05T00:00:00Z"})
+	v.pause = ts.createRecord(t, session, billPath+"/pauses", gin.H{"start_date": "2027-06-01T00:00:00Z", "end_date": "2027-07-01T00:00:00Z"})
+	v.skip = ts.createRecord(t, session, billPath+"/skips", gin.H{"due_date": "2027-03-05T00:00:00Z"})
+	v.payment = ts.createRecord(t, session, billPath+"/payments", gin.H{
+		"amount":       1250,
+		"payment_date": time.Now().Format(time.RFC3339),
+		"notes":        victimMarker + " paid",
+	})
+
+	var attachment models.Attachment
+	decode(t, ts.upload(t, billPath+"/attachments", session, "lease.txt", []byte(victimMarker+" lease")), http.StatusCreated, &attachment)
+	v.billAttachment = attachment.ID
+	decode(t, ts.upload(t, billPath+"/payments/"+v.payment+"/attachments", session, "receipt.txt", []byte(victimMarker+" receipt")), http.StatusCreated, &attachment)
+	v.paymentAttachment = attachment.ID
+
+	var token models.CreateAPITokenResponse
+	decode(t, ts.request(t, http.MethodPost, "/api/v1/tokens", session, gin.H{"name": victimMarker + " script", "scope": "read"}), http.StatusCreated, &token)
+	v.token = token.APIToken.ID
+
+	decode(t, ts.request(t, http.MethodDelete, "/api/v1/bills/"+v.trashedBill, session, nil), http.StatusOK, nil)
+	decode(t, ts.request(t, http.MethodDelete, "/api/v1/categories/"+v.trashedCategory, session, nil), http.StatusOK, nil)
+
+	v.billIDs = []string{v.bill, v.loanBill, v.trashedBill}
+	v.allIDs = append([]string{
+		v.category, v.trashedCategory, v.tag, v.payee, v.account, v.holiday,
+		v.statement, v.waiver, v.pause, v.skip, v.payment, v.billAttachment, v.paymentAttachment, v.token,
+	}, v.billIDs...)
+	return v
+}
+
+// export downloads the personal data export of a user, without the time it was made and the audit
+// events of earlier exports
+func (ts *testServer) export(t *testing.T, session string) string {
+	t.Helper()
+	var export map[string]any
+	decode(t, ts.request(t, http.MethodGet, "/api/v1/me/export", session, nil), http.StatusOK, &export)
+	delete(export, "exported_at")
+	events, _ := export["audit_events"].([]any)
+	export["audit_events"] = slices.DeleteFunc(events, func(event any) bool {
+		return event.(map[string]any)["action"] == models.AuditActionDataExport
+	})
+	data, err := json.Marshal(export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestOtherTenantsRecordsCannotBeReachedThroughTheAPI(t *testing.T) {
+	ts := newTestServer(t)
+	victimSession, _ := ts.register(t, "victim")
+	v := ts.newVictim(t, victimSession)
+	before := ts.export(t, victimSession)
+
+	attackerSession, _ := ts.register(t, "attacker")
+	ownBill := ts.createRecord(t, attackerSession, "/api/v1/bills", gin.H{
+		"name":            "Own",
+		"amount":          10,
+		"recurrence_type": "fixed_date",
+		"recurrence_days": 1,
+	})
+
+	bill := "/api/v1/bills/" + v.bill
+	date := "2026-11-05T00:00:00Z"
+	tests := []struct {
+		group  string
+		method string
+		path   string
+		body   any
+	}{
+		// Bills
+		{"bills", http.MethodGet, bill, nil},
+		{"bills", http.MethodPut, bill, gin.H{"name": "Hijacked", "amount": 1, "recurrence_type": "fixed_date", "recurrence_days": 1}},
+		{"bills", http.MethodDelete, bill, nil},
+		{"bills", http.MethodGet, bill + "/history", nil},
+		{"bills", http.MethodPost, bill + "/archive", nil},
+		{"bills", http.MethodPost, bill + "/unarchive", nil},
+		{"bills", http.MethodGet, bill + "/pauses", nil},
+		{"bills", http.MethodPost, bill + "/pauses", gin.H{"start_date": date}},
+		{"bills", http.MethodDelete, bill + "/pauses/" + v.pause, nil},
+		{"bills", http.MethodGet, bill + "/skips", nil},
+		{"bills", http.MethodPost, bill + "/skips", gin.H{"due_date": date}},
+		{"bills", http.MethodDelete, bill + "/skips/" + v.skip, nil},
+		{"bills", http.MethodDelete, "/api/v1/bills/" + ownBill + "/pauses/" + v.pause, nil},
+		{"bills", http.MethodDelete, "/api/v1/bills/" + ownBill + "/skips/" + v.skip, nil},
+
+		// Payments
+		{"payments", http.MethodGet, bill + "/payments", nil},
+		{"payments", http.MethodPost, bill + "/payments", gin.H{"amount": 1, "payment_date": date}},
+		{"payments", http.MethodDelete, bill + "/payments/" + v.payment, nil},
+		{"payments", http.MethodPost, bill + "/payments/" + v.payment + "/reverse", gin.H{"reason": "hijacked"}},
+		{"payments", http.MethodDelete, "/api/v1/bills/" + ownBill + "/payments/" + v.payment, nil},
+
+		// Statements
+		{"statements", http.MethodGet, bill + "/statements", nil},
+		{"statements", http.MethodPost, bill + "/statements", gin.H{"due_date": date, "amount": 1}},
+		{"statements", http.MethodPost, bill + "/statements/import", gin.H{"statements": []gin.H{{"due_date": date, "amount": 1}}}},
+		{"statements", http.MethodDelete, bill + "/statements/" + v.statement, nil},
+		{"statements", http.MethodDelete, "/api/v1/bills/" + ownBill + "/statements/" + v.statement, nil},
+
+		// Late fees
+		{"late fees", http.MethodGet, bill + "/late-fees/waivers", nil},
+		{"late fees", http.MethodPost, bill + "/late-fees/waivers", gin.H{"due_date": date}},
+		{"late fees", http.MethodDelete, bill + "/late-fees/waivers/" + v.waiver, nil},
+		{"late fees", http.MethodDelete, "/api/v1/bills/" + ownBill + "/late-fees/waivers/" + v.waiver, nil},
+
+		// Loans
+		{"loans", http.MethodGet, "/api/v1/bills/" + v.loanBill, nil},
+		{"loans", http.MethodGet, "/api/v1/bills/" + v.loanBill + "/amortization", nil},
+
+		// Attachments
+		{"attachments", http.MethodGet, bill + "/attachments", nil},
+		{"attachments", http.MethodGet, bill + "/payments/" + v.payment + "/attachments", nil},
+		{"attachments", http.MethodGet, "/api/v1/bills/" + ownBill + "/payments/" + v.payment + "/attachments", nil},
+		{"attachments", http.MethodGet, "/api/v1/attachments/" + v.billAttachment + "/download", nil},
+		{"attachments", http.MethodGet, "/api/v1/attachments/" + v.paymentAttachment + "/download", nil},
+		{"attachments", http.MethodDelete, "/api/v1/attachments/" + v.billAttachment, nil},
+
+		// Categories
+		{"categories", http.MethodDelete, "/api/v1/categories/" + v.category, nil},
+		{"categories", http.MethodPost, "/api/v1/categories/" + v.category + "/archive", nil},
+		{"categories", http.MethodPost, "/api/v1/categories/" + v.category + "/unarchive", nil},
+
+		// Tags
+		{"tags", http.MethodPut, "/api/v1/tags/" + v.tag, gin.H{"name": "Hijacked"}},
+		{"tags", http.MethodDelete, "/api/v1/tags/" + v.tag, nil},
+
+		// Payees
+		{"payees", http.MethodGet, "/api/v1/payees/" + v.payee, nil},
+		{"payees", http.MethodPut, "/api/v1/payees/" + v.payee, gin.H{"name": "Hijacked"}},
+		{"payees", http.MethodDelete, "/api/v1/payees/" + v.payee, nil},
+
+		// Funding accounts
+		{"accounts", http.MethodGet, "/api/v1/accounts/" + v.account, nil},
+		{"accounts", http.MethodPut, "/api/v1/accounts/" + v.account, gin.H{"name": "Hijacked"}},
+		{"accounts", http.MethodDelete, "/api/v1/accounts/" + v.account, nil},
+
+		// Holidays
+		{"holidays", http.MethodDelete, "/api/v1/holidays/" + v.holiday, nil},
+
+		// Trash
+		{"trash", http.MethodPost, "/api/v1/bills/" + v.trashedBill + "/restore", nil},
+		{"trash", http.MethodPost, "/api/v1/categories/" + v.trashedCategory + "/restore", nil},
+
+		// API tokens
+		{"tokens", http.MethodDelete, "/api/v1/tokens/" + v.token, nil},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%s %s", tt.group, tt.method, strings.TrimPrefix(tt.path, "/api/v1")), func(t *testing.T) {
+			rec := ts.request(t, tt.method, tt.path, attackerSession, tt.body)
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("got status %d, want %d: %s", rec.Code, http.StatusNotFound, rec.Body.String())
+			}
+			if body := rec.Body.String(); strings.Contains(body, victimMarker) {
+				t.Errorf("response leaks the victim's records: %s", body)
+			}
+		})
+	}
+
+	// Records of the attacker cannot reference the victim's; these are invalid requests rather than missing records
+	rec := ts.request(t, http.MethodPost, "/api/v1/bills/"+ownBill+"/payments", attackerSession, gin.H{"amount": 1, "payment_date": date, "account_id": v.account})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("payment from the victim's account: got status %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+	rec = ts.request(t, http.MethodPut, "/api/v1/bills/"+ownBill, attackerSession, gin.H{
+		"name": "Own", "amount": 10, "recurrence_type": "fixed_date", "recurrence_days": 1, "category_id": v.category,
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("bill in the victim's category: got status %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+
+	// Nothing the attacker did changed the victim's data
+	if after := ts.export(t, victimSession); after != before {
+		t.Errorf("the victim's data changed:\nbefore: %s\nafter:  %s", before, after)
+	}
+}
+
+func TestListingsOnlyShowOwnRecords(t *testing.T) {
+	ts := newTestServer(t)
+	victimSession, _ := ts.register(t, "victim")
+	v := ts.newVictim(t, victimSession)
+	attackerSession, _ := ts.register(t, "attacker")
+
+	paths := []struct {
+		group string
+		path  string
+	}{
+		{"bills", "/api/v1/bills"},
+		{"bills", "/api/v1/bills?include_archived=true"},
+		{"payments", "/api/v1/payments"},
+		{"categories", "/api/v1/categories"},
+		{"tags", "/api/v1/tags"},
+		{"tags", "/api/v1/tags/payments"},
+		{"payees", "/api/v1/payees"},
+		{"accounts", "/api/v1/accounts"},
+		{"accounts", "/api/v1/accounts/projection"},
+		{"holidays", "/api/v1/holidays"},
+		{"trash", "/api/v1/bills/trash"},
+		{"trash", "/api/v1/categories/trash"},
+		{"tokens", "/api/v1/tokens"},
+		{"audit", "/api/v1/audit"},
+		{"export", "/api/v1/me/export"},
+		{"stats", "/api/v1/stats/summary"},
+		{"stats", "/api/v1/stats/forecast"},
+		{"stats", "/api/v1/stats/price-increases"},
+	}
+	for _, p := range paths {
+		t.Run(p.group+" "+strings.TrimPrefix(p.path, "/api/v1"), func(t *testing.T) {
+			victim := ts.request(t, http.MethodGet, p.path, victimSession, nil)
+			attacker := ts.request(t, http.MethodGet, p.path, attackerSession, nil)
+			if victim.Code != http.StatusOK || attacker.Code != http.StatusOK {
+				t.Fatalf("got status %d for the victim and %d for the attacker, want %d", victim.Code, attacker.Code, http.StatusOK)
+			}
+			body := attacker.Body.String()
+			if strings.Contains(body, victimMarker) {
+				t.Errorf("listing shows the victim's records: %s", body)
+			}
+			for _, id := range v.allIDs {
+				if strings.Contains(body, id) {
+					t.Errorf("listing contains the victim's record %s: %s", id, body)
+				}
+			}
+		})
+	}
+
+	// The stats summary of the attacker counts none of the victim's bills
+	var stats models.BillStats
+	decode(t, ts.request(t, http.MethodGet, "/api/v1/stats/summary", attackerSession, nil), http.StatusOK, &stats)
+	if stats.TotalBills != 0 || stats.TotalAmount != 0 {
+		t.Errorf("attacker's stats count the victim's bills: %+v", stats)
+	}
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cryptk/williams/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// parseListOptions reads the include query parameter, e.g. ?include=archived
+func parseListOptions(c *gin.Context) repository.ListOptions {
+	include := strings.Split(c.Query("include"), ",")
+	return repository.ListOptions{
+		IncludeArchived: slices.Contains(include, "archived"),
+	}
+}
+
+// Bill archive and trash handlers
+
+func (s *Server) archiveBill(c *gin.Context) {
+	s.changeArchiveState(c, "Bill", s.billService.Archive, "Bill archived")
+}
+
+func (s *Server) unarchiveBill(c *gin.Context) {
+	s.changeArchiveState(c, "Bill", s.billService.Unarchive, "Bill unarchived")
+}
+
+func (s *Server) restoreBill(c *gin.Context) {
+	s.changeArchiveState(c, "Bill", s.billService.Restore, "Bill restored")
+}
+
+func (s *Server) listBillTrash(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	bills, err := s.billService.ListTrash(scopedDB)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to list deleted bills")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve deleted bills"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bills": bills,
+		"total": len(bills),
+	})
+}
+
+// Category archive and trash handlers
+
+func (s *Server) archiveCategory(c *gin.Context) {
+	s.changeArchiveState(c, "Category", s.categoryService.Archive, "Category archived")
+}
+
+func (s *Server) unarchiveCategory(c *gin.Context) {
+	s.changeArchiveState(c, "Category", s.categoryService.Unarchive, "Category unarchived")
+}
+
+func (s *Server) restoreCategory(c *gin.Context) {
+	s.changeArchiveState(c, "Category", s.categoryService.Restore, "Category restored")
+}
+
+func (s *Server) listCategoryTrash(c *gin.Context) {
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	categories, err := s.categoryService.ListTrash(scopedDB)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to list deleted categories")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve deleted categories"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"categories": categories,
+	})
+}
+
+// changeArchiveState runs an archive, unarchive or restore action against the record named by the id parameter
+func (s *Server) changeArchiveState(c *gin.Context, entity string, action func(*gorm.DB, string) error, message string) {
+	id := c.Param("id")
+	userID, scopedDB, err := fetchTenancyFromContext(c)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to fetch tenancy from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := action(scopedDB, id); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("id", id).Msg("Failed to change archive state")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": entity + " not found",
+			"id":    id,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"id":      id,
+	})
+}
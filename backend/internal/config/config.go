@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -12,19 +13,25 @@ var Version string = "dev"
 
 // Config represents the application configuration
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Auth     AuthConfig     `mapstructure:"auth"`
-	Bills    BillsConfig    `mapstructure:"bills"`
-	Logging  LoggingConfig  `mapstructure:"logging"`
-	Timezone string         `mapstructure:"timezone"` // IANA timezone (e.g., "America/New_York", "UTC")
+	Server      ServerConfig      `mapstructure:"server"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+	Bills       BillsConfig       `mapstructure:"bills"`
+	Email       EmailConfig       `mapstructure:"email"`
+	Audit       AuditConfig       `mapstructure:"audit"`
+	Attachments AttachmentsConfig `mapstructure:"attachments"`
+	Encryption  EncryptionConfig  `mapstructure:"encryption"`
+	Logging     LoggingConfig     `mapstructure:"logging"`
+	Timezone    string            `mapstructure:"timezone"` // IANA timezone (e.g., "America/New_York", "UTC")
 }
 
 // ServerConfig represents server configuration
 type ServerConfig struct {
-	Host             string `mapstructure:"host"`
-	Port             int    `mapstructure:"port"`
-	StaticAssetsPath string `mapstructure:"static_assets_path"`
+	Host             string   `mapstructure:"host"`
+	Port             int      `mapstructure:"port"`
+	StaticAssetsPath string   `mapstructure:"static_assets_path"`
+	PublicURL        string   `mapstructure:"public_url"`      // Externally reachable base URL, used in links sent by email
+	TrustedProxies   []string `mapstructure:"trusted_proxies"` // Proxies allowed to set X-Forwarded-For; empty trusts none and uses the connection's address
 }
 
 // DatabaseConfig represents database configuration
@@ -35,14 +42,87 @@ type DatabaseConfig struct {
 
 // AuthConfig represents authentication configuration
 type AuthConfig struct {
-	JWTSecret        string `mapstructure:"jwt_secret"`
-	FirstUserIsAdmin bool   `mapstructure:"first_user_is_admin"`
+	JWTSecret           string          `mapstructure:"jwt_secret"`
+	FirstUserIsAdmin    bool            `mapstructure:"first_user_is_admin"`
+	TOTPIssuer          string          `mapstructure:"totp_issuer"`           // Issuer name shown in authenticator apps
+	DeletionGracePeriod time.Duration   `mapstructure:"deletion_grace_period"` // How long a deleted account can be restored before it is purged; 0 deletes immediately
+	OIDC                OIDCConfig      `mapstructure:"oidc"`
+	RateLimit           RateLimitConfig `mapstructure:"rate_limit"`
+}
+
+// RateLimitConfig represents brute-force protection for the authentication endpoints
+type RateLimitConfig struct {
+	IPRequests         int           `mapstructure:"ip_requests"` // Requests per client IP per window across auth endpoints; 0 disables
+	IPWindow           time.Duration `mapstructure:"ip_window"`
+	UsernameRequests   int           `mapstructure:"username_requests"` // Login attempts per username per window; 0 disables
+	UsernameWindow     time.Duration `mapstructure:"username_window"`
+	LockoutThreshold   int           `mapstructure:"lockout_threshold"`    // Consecutive failures before an account is locked; 0 disables
+	LockoutDuration    time.Duration `mapstructure:"lockout_duration"`     // First lockout length, doubled for each subsequent lockout
+	LockoutMaxDuration time.Duration `mapstructure:"lockout_max_duration"` // Upper bound for progressive lockouts
+}
+
+// OIDCConfig represents OpenID Connect single sign-on configuration
+type OIDCConfig struct {
+	Enabled           bool     `mapstructure:"enabled"`
+	ProviderName      string   `mapstructure:"provider_name"` // Display name for the login button (e.g., "Authelia")
+	IssuerURL         string   `mapstructure:"issuer_url"`    // Used for discovery via /.well-known/openid-configuration
+	ClientID          string   `mapstructure:"client_id"`
+	ClientSecret      string   `mapstructure:"client_secret"`
+	RedirectURL       string   `mapstructure:"redirect_url"` // Must point at /api/v1/auth/oidc/callback
+	Scopes            []string `mapstructure:"scopes"`
+	AutoProvision     bool     `mapstructure:"auto_provision"`      // Create local accounts on first login (just-in-time provisioning)
+	GroupsClaim       string   `mapstructure:"groups_claim"`        // ID token claim holding the user's groups
+	AdminGroup        string   `mapstructure:"admin_group"`         // Members of this group get the admin role; empty disables role mapping
+	PostLoginRedirect string   `mapstructure:"post_login_redirect"` // Frontend URL the token is handed to after login
 }
 
 // BillsConfig represents bills configuration
 type BillsConfig struct {
-	PaymentGraceDays       int `mapstructure:"payment_grace_days"`
-	MaximumBillingInterval int `mapstructure:"maximum_billing_interval"`
+	PaymentGraceDays       int           `mapstructure:"payment_grace_days"`
+	MaximumBillingInterval int           `mapstructure:"maximum_billing_interval"`
+	TrashRetention         time.Duration `mapstructure:"trash_retention"`        // Deleted bills and categories are purged after this long; 0 keeps them forever
+	HolidayCalendarsPath   string        `mapstructure:"holiday_calendars_path"` // Directory of extra holiday calendar files (.json or .ics) added to the bundled ones
+	AutopayInterval        time.Duration `mapstructure:"autopay_interval"`       // How often payments are recorded for autopay bills that came due; 0 disables autopay
+}
+
+// EmailConfig represents outgoing email configuration
+type EmailConfig struct {
+	SMTPHost string `mapstructure:"smtp_host"` // Empty disables sending; messages are written to the log instead
+	SMTPPort int    `mapstructure:"smtp_port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+}
+
+// AuditConfig represents audit log configuration
+type AuditConfig struct {
+	Retention time.Duration `mapstructure:"retention"` // Events older than this are deleted; 0 keeps them forever
+}
+
+// AttachmentsConfig represents file attachment configuration
+type AttachmentsConfig struct {
+	Storage      string   `mapstructure:"storage"`       // local or s3
+	LocalPath    string   `mapstructure:"local_path"`    // Directory files are kept in with local storage
+	MaxSize      int64    `mapstructure:"max_size"`      // Largest accepted file in bytes
+	AllowedTypes []string `mapstructure:"allowed_types"` // Accepted MIME types, detected from the file contents
+	S3           S3Config `mapstructure:"s3"`
+}
+
+// S3Config represents an S3-compatible object store such as AWS S3 or MinIO
+type S3Config struct {
+	Endpoint        string `mapstructure:"endpoint"` // e.g. https://s3.us-east-1.amazonaws.com or http://minio:9000
+	Region          string `mapstructure:"region"`
+	Bucket          string `mapstructure:"bucket"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	UsePathStyle    bool   `mapstructure:"use_path_style"` // Address the bucket in the path rather than the host name, as MinIO expects
+}
+
+// EncryptionConfig represents the keys sensitive fields such as notes and account numbers are encrypted with
+type EncryptionConfig struct {
+	Key          string   `mapstructure:"key"`           // Base64 encoded 32 byte key; takes precedence over key_file
+	KeyFile      string   `mapstructure:"key_file"`      // File holding the base64 encoded key, generated if it does not exist
+	PreviousKeys []string `mapstructure:"previous_keys"` // Old keys still accepted for decryption until values are re-encrypted
 }
 
 // LoggingConfig represents logging configuration
@@ -59,12 +139,56 @@ func Load() (*Config, error) {
 	v.SetDefault("server.host", "localhost")
 	v.SetDefault("server.port", 8080)
 	v.SetDefault("server.static_assets_path", "./dist")
+	v.SetDefault("server.public_url", "http://localhost:8080")
+	v.SetDefault("server.trusted_proxies", []string{})
 	v.SetDefault("database.driver", "sqlite")
 	v.SetDefault("database.dsn", "./williams.db")
 	v.SetDefault("auth.jwt_secret", "change-this-secret-in-production")
 	v.SetDefault("auth.first_user_is_admin", false)
+	v.SetDefault("auth.totp_issuer", "Williams")
+	v.SetDefault("auth.deletion_grace_period", "720h")
+	v.SetDefault("auth.rate_limit.ip_requests", 20)
+	v.SetDefault("auth.rate_limit.ip_window", time.Minute)
+	v.SetDefault("auth.rate_limit.username_requests", 10)
+	v.SetDefault("auth.rate_limit.username_window", time.Minute)
+	v.SetDefault("auth.rate_limit.lockout_threshold", 5)
+	v.SetDefault("auth.rate_limit.lockout_duration", time.Minute)
+	v.SetDefault("auth.rate_limit.lockout_max_duration", time.Hour)
+	v.SetDefault("auth.oidc.enabled", false)
+	v.SetDefault("auth.oidc.provider_name", "SSO")
+	v.SetDefault("auth.oidc.issuer_url", "")
+	v.SetDefault("auth.oidc.client_id", "")
+	v.SetDefault("auth.oidc.client_secret", "")
+	v.SetDefault("auth.oidc.redirect_url", "")
+	v.SetDefault("auth.oidc.scopes", []string{"openid", "profile", "email", "groups"})
+	v.SetDefault("auth.oidc.auto_provision", false)
+	v.SetDefault("auth.oidc.groups_claim", "groups")
+	v.SetDefault("auth.oidc.admin_group", "")
+	v.SetDefault("auth.oidc.post_login_redirect", "/login")
 	v.SetDefault("bills.payment_grace_days", 7)
 	v.SetDefault("bills.maximum_billing_interval", 365)
+	v.SetDefault("bills.trash_retention", "720h")
+	v.SetDefault("bills.holiday_calendars_path", "")
+	v.SetDefault("bills.autopay_interval", "1h")
+	v.SetDefault("email.smtp_host", "")
+	v.SetDefault("email.smtp_port", 587)
+	v.SetDefault("email.username", "")
+	v.SetDefault("email.password", "")
+	v.SetDefault("email.from", "Williams <williams@localhost>")
+	v.SetDefault("audit.retention", "8760h")
+	v.SetDefault("attachments.storage", "local")
+	v.SetDefault("attachments.local_path", "./data/attachments")
+	v.SetDefault("attachments.max_size", 10<<20)
+	v.SetDefault("attachments.allowed_types", []string{"application/pdf", "image/png", "image/jpeg", "image/gif", "image/webp", "text/plain"})
+	v.SetDefault("attachments.s3.endpoint", "")
+	v.SetDefault("attachments.s3.region", "us-east-1")
+	v.SetDefault("attachments.s3.bucket", "")
+	v.SetDefault("attachments.s3.access_key_id", "")
+	v.SetDefault("attachments.s3.secret_access_key", "")
+	v.SetDefault("attachments.s3.use_path_style", false)
+	v.SetDefault("encryption.key", "")
+	v.SetDefault("encryption.key_file", "./data/encryption.key")
+	v.SetDefault("encryption.previous_keys", []string{})
 	v.SetDefault("logging.level", "info")
 	v.SetDefault("logging.format", "json")
 	v.SetDefault("timezone", "UTC")
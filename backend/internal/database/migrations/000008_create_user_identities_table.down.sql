@@ -0,0 +1,4 @@
+-- Drop user_identities table and indexes
+DROP INDEX IF EXISTS idx_user_identities_user_id;
+DROP INDEX IF EXISTS idx_user_identities_issuer_subject;
+DROP TABLE IF EXISTS user_identities;
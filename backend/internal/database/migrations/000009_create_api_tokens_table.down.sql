@@ -0,0 +1,4 @@
+-- Drop api_tokens table and indexes
+DROP INDEX IF EXISTS idx_api_tokens_token_hash;
+DROP INDEX IF EXISTS idx_api_tokens_user_id;
+DROP TABLE IF EXISTS api_tokens;
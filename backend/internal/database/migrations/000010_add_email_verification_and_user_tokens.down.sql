@@ -0,0 +1,8 @@
+-- Drop user_tokens table and indexes
+DROP INDEX IF EXISTS idx_user_tokens_token_hash;
+DROP INDEX IF EXISTS idx_user_tokens_user_id;
+DROP TABLE IF EXISTS user_tokens;
+
+-- Remove email verification and token version columns from users table
+ALTER TABLE users DROP COLUMN token_version;
+ALTER TABLE users DROP COLUMN email_verified;
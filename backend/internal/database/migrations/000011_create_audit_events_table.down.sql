@@ -0,0 +1,4 @@
+-- Drop audit_events table and indexes
+DROP INDEX IF EXISTS idx_audit_events_created_at;
+DROP INDEX IF EXISTS idx_audit_events_user_id_created_at;
+DROP TABLE IF EXISTS audit_events;
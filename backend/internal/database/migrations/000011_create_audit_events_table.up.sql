@@ -0,0 +1,18 @@
+-- Create append-only audit_events table. No foreign keys, so history outlives the users and records it describes.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NULL,
+    actor_id TEXT NULL,
+    action TEXT NOT NULL,
+    entity_type TEXT NULL,
+    entity_id TEXT NULL,
+    changes TEXT NULL,
+    details TEXT NULL,
+    ip_address TEXT NULL,
+    user_agent TEXT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for per-user history, the admin view and retention cleanup
+CREATE INDEX IF NOT EXISTS idx_audit_events_user_id_created_at ON audit_events(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at);
@@ -0,0 +1,4 @@
+-- Drop bill_versions table and indexes
+DROP INDEX IF EXISTS idx_bill_versions_user_id;
+DROP INDEX IF EXISTS idx_bill_versions_bill_id_effective_from;
+DROP TABLE IF EXISTS bill_versions;
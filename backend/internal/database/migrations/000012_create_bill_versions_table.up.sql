@@ -0,0 +1,25 @@
+-- Create bill_versions table. Each row holds the amount, recurrence and category of a bill from effective_from onward,
+-- so past occurrences keep the price that applied at the time.
+CREATE TABLE IF NOT EXISTS bill_versions (
+    id TEXT PRIMARY KEY,
+    bill_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    amount REAL NOT NULL,
+    recurrence_type TEXT NOT NULL DEFAULT 'none' CHECK(recurrence_type IN ('none', 'fixed_date', 'interval')),
+    recurrence_days INTEGER NOT NULL CHECK(recurrence_days >= 1),
+    category_id TEXT NULL,
+    effective_from DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (bill_id) REFERENCES bills(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE SET NULL
+);
+
+-- Create indexes for faster lookups
+CREATE INDEX IF NOT EXISTS idx_bill_versions_bill_id_effective_from ON bill_versions(bill_id, effective_from);
+CREATE INDEX IF NOT EXISTS idx_bill_versions_user_id ON bill_versions(user_id);
+
+-- Seed an initial version for every existing bill, reusing the bill ID as the version ID
+INSERT INTO bill_versions (id, bill_id, user_id, amount, recurrence_type, recurrence_days, category_id, effective_from, created_at)
+SELECT id, id, user_id, amount, COALESCE(recurrence_type, 'none'), recurrence_days, category_id, COALESCE(start_date, created_at), created_at
+FROM bills;
@@ -0,0 +1,7 @@
+-- Remove archive and soft delete support from bills and categories
+DROP INDEX IF EXISTS idx_categories_deleted_at;
+DROP INDEX IF EXISTS idx_bills_deleted_at;
+ALTER TABLE categories DROP COLUMN deleted_at;
+ALTER TABLE categories DROP COLUMN archived_at;
+ALTER TABLE bills DROP COLUMN deleted_at;
+ALTER TABLE bills DROP COLUMN archived_at;
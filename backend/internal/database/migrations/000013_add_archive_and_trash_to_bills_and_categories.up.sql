@@ -0,0 +1,10 @@
+-- Add archive and soft delete (trash) support to bills and categories.
+-- archived_at hides a record from lists and stats; deleted_at moves it to the trash until it is purged.
+ALTER TABLE bills ADD COLUMN archived_at DATETIME NULL;
+ALTER TABLE bills ADD COLUMN deleted_at DATETIME NULL;
+ALTER TABLE categories ADD COLUMN archived_at DATETIME NULL;
+ALTER TABLE categories ADD COLUMN deleted_at DATETIME NULL;
+
+-- Create indexes for filtering and purging
+CREATE INDEX IF NOT EXISTS idx_bills_deleted_at ON bills(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories(deleted_at);
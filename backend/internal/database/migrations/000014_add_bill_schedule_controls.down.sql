@@ -0,0 +1,9 @@
+-- Drop bill pauses, skips and end conditions
+DROP INDEX IF EXISTS idx_bill_skips_user_id;
+DROP INDEX IF EXISTS idx_bill_skips_bill_id_due_date;
+DROP INDEX IF EXISTS idx_bill_pauses_user_id;
+DROP INDEX IF EXISTS idx_bill_pauses_bill_id;
+DROP TABLE IF EXISTS bill_skips;
+DROP TABLE IF EXISTS bill_pauses;
+ALTER TABLE bills DROP COLUMN max_occurrences;
+ALTER TABLE bills DROP COLUMN end_date;
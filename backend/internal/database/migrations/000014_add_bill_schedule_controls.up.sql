@@ -0,0 +1,36 @@
+-- Add end conditions to recurring bills. A bill stops recurring after end_date or once max_occurrences
+-- occurrences have been billed, whichever comes first.
+ALTER TABLE bills ADD COLUMN end_date DATETIME NULL;
+ALTER TABLE bills ADD COLUMN max_occurrences INTEGER NULL CHECK(max_occurrences >= 1);
+
+-- Create bill_pauses table. Occurrences falling between start_date and end_date are not billed;
+-- a pause without an end_date lasts until it is removed.
+CREATE TABLE IF NOT EXISTS bill_pauses (
+    id TEXT PRIMARY KEY,
+    bill_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    start_date DATETIME NOT NULL,
+    end_date DATETIME NULL,
+    notes TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (bill_id) REFERENCES bills(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create bill_skips table. Each row waives a single occurrence of a recurring bill.
+CREATE TABLE IF NOT EXISTS bill_skips (
+    id TEXT PRIMARY KEY,
+    bill_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    due_date DATETIME NOT NULL,
+    notes TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (bill_id) REFERENCES bills(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create indexes for faster lookups
+CREATE INDEX IF NOT EXISTS idx_bill_pauses_bill_id ON bill_pauses(bill_id);
+CREATE INDEX IF NOT EXISTS idx_bill_pauses_user_id ON bill_pauses(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bill_skips_bill_id_due_date ON bill_skips(bill_id, due_date);
+CREATE INDEX IF NOT EXISTS idx_bill_skips_user_id ON bill_skips(user_id);
@@ -0,0 +1,5 @@
+-- Drop user holidays and business day adjustment
+DROP INDEX IF EXISTS idx_user_holidays_user_id_date;
+DROP TABLE IF EXISTS user_holidays;
+ALTER TABLE bills DROP COLUMN holiday_calendar;
+ALTER TABLE bills DROP COLUMN business_day_roll;
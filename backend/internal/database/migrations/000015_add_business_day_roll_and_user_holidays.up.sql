@@ -0,0 +1,17 @@
+-- Add business day adjustment to bills. Due dates falling on a weekend or holiday are moved according to
+-- business_day_roll, using the holidays of holiday_calendar (a country code) and the user's own holidays.
+ALTER TABLE bills ADD COLUMN business_day_roll TEXT NOT NULL DEFAULT 'none' CHECK(business_day_roll IN ('none', 'previous', 'next', 'modified_following'));
+ALTER TABLE bills ADD COLUMN holiday_calendar TEXT NOT NULL DEFAULT '';
+
+-- Create user_holidays table for holidays a user adds on top of the bundled country calendars
+CREATE TABLE IF NOT EXISTS user_holidays (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    date TEXT NOT NULL,
+    name TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Each user can only add a date once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_holidays_user_id_date ON user_holidays(user_id, date);
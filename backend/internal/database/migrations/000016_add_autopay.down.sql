@@ -0,0 +1,8 @@
+-- Remove autopay from bills and payments
+ALTER TABLE payments DROP COLUMN reversal_reason;
+ALTER TABLE payments DROP COLUMN reversed_at;
+ALTER TABLE payments DROP COLUMN auto_generated;
+ALTER TABLE bills DROP COLUMN autopay_enabled_at;
+ALTER TABLE bills DROP COLUMN autopay_notes;
+ALTER TABLE bills DROP COLUMN autopay_account;
+ALTER TABLE bills DROP COLUMN autopay;
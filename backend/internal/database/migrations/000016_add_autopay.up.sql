@@ -0,0 +1,12 @@
+-- Add autopay to bills. A background job records a payment for each occurrence of an autopay bill on its due date,
+-- starting with occurrences due on or after autopay_enabled_at.
+ALTER TABLE bills ADD COLUMN autopay BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE bills ADD COLUMN autopay_account TEXT NOT NULL DEFAULT '';
+ALTER TABLE bills ADD COLUMN autopay_notes TEXT NOT NULL DEFAULT '';
+ALTER TABLE bills ADD COLUMN autopay_enabled_at DATETIME NULL;
+
+-- Mark generated payments, and allow them to be reversed when the debit failed. Reversed payments are kept
+-- so the job does not generate them again, but no longer count toward the bill being paid.
+ALTER TABLE payments ADD COLUMN auto_generated BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE payments ADD COLUMN reversed_at DATETIME NULL;
+ALTER TABLE payments ADD COLUMN reversal_reason TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+-- Drop bill_loans table and bill kind
+DROP INDEX IF EXISTS idx_bill_loans_user_id;
+DROP TABLE IF EXISTS bill_loans;
+ALTER TABLE bills DROP COLUMN kind;
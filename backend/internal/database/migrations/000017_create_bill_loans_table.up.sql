@@ -0,0 +1,19 @@
+-- Add a kind to bills. Loan bills (car loans, "pay in 4" purchases) have their installment derived from
+-- the loan terms in bill_loans and complete once the balance is paid off.
+ALTER TABLE bills ADD COLUMN kind TEXT NOT NULL DEFAULT 'standard' CHECK(kind IN ('standard', 'loan'));
+
+-- Create bill_loans table holding the terms of loan bills
+CREATE TABLE IF NOT EXISTS bill_loans (
+    bill_id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    principal REAL NOT NULL CHECK(principal > 0),
+    interest_rate REAL NOT NULL DEFAULT 0 CHECK(interest_rate >= 0),
+    term_payments INTEGER NOT NULL CHECK(term_payments >= 1),
+    first_payment_date DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (bill_id) REFERENCES bills(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_bill_loans_user_id ON bill_loans(user_id);
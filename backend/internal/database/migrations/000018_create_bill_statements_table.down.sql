@@ -0,0 +1,5 @@
+-- Drop bill statements and variable amounts
+DROP INDEX IF EXISTS idx_bill_statements_user_id;
+DROP INDEX IF EXISTS idx_bill_statements_bill_id_due_date;
+DROP TABLE IF EXISTS bill_statements;
+ALTER TABLE bills DROP COLUMN variable_amount;
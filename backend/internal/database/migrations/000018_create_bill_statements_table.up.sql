@@ -0,0 +1,23 @@
+-- Variable amount bills (credit cards, utilities) get their amount from a statement per occurrence and
+-- are estimated from recent statements until one is entered
+ALTER TABLE bills ADD COLUMN variable_amount BOOLEAN NOT NULL DEFAULT 0;
+
+-- Create bill_statements table. Each row holds the statement of one occurrence of a variable amount
+-- bill, identified by its due date.
+CREATE TABLE IF NOT EXISTS bill_statements (
+    id TEXT PRIMARY KEY,
+    bill_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    due_date DATETIME NOT NULL,
+    amount REAL NOT NULL CHECK(amount >= 0),
+    minimum_payment REAL NULL CHECK(minimum_payment >= 0),
+    notes TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (bill_id) REFERENCES bills(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create indexes for faster lookups
+CREATE INDEX IF NOT EXISTS idx_bill_statements_bill_id_due_date ON bill_statements(bill_id, due_date);
+CREATE INDEX IF NOT EXISTS idx_bill_statements_user_id ON bill_statements(user_id);
@@ -0,0 +1,6 @@
+-- Drop late fee rules and waivers
+DROP INDEX IF EXISTS idx_bill_late_fee_waivers_user_id;
+DROP INDEX IF EXISTS idx_bill_late_fee_waivers_bill_id_due_date;
+DROP INDEX IF EXISTS idx_bill_late_fee_rules_user_id;
+DROP TABLE IF EXISTS bill_late_fee_waivers;
+DROP TABLE IF EXISTS bill_late_fee_rules;
@@ -0,0 +1,33 @@
+-- Create bill_late_fee_rules table. A bill with a rule accrues a late fee on each occurrence that is
+-- still unpaid grace_days after its due date: flat_fee plus percentage of the amount due plus
+-- daily_fee for each further day, capped at max_fee.
+CREATE TABLE IF NOT EXISTS bill_late_fee_rules (
+    bill_id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    flat_fee REAL NOT NULL DEFAULT 0 CHECK(flat_fee >= 0),
+    percentage REAL NOT NULL DEFAULT 0 CHECK(percentage >= 0),
+    daily_fee REAL NOT NULL DEFAULT 0 CHECK(daily_fee >= 0),
+    grace_days INTEGER NOT NULL DEFAULT 0 CHECK(grace_days >= 0),
+    max_fee REAL NULL CHECK(max_fee >= 0),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (bill_id) REFERENCES bills(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create bill_late_fee_waivers table. Each row waives the late fee of a single occurrence.
+CREATE TABLE IF NOT EXISTS bill_late_fee_waivers (
+    id TEXT PRIMARY KEY,
+    bill_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    due_date DATETIME NOT NULL,
+    notes TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (bill_id) REFERENCES bills(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create indexes for faster lookups
+CREATE INDEX IF NOT EXISTS idx_bill_late_fee_rules_user_id ON bill_late_fee_rules(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bill_late_fee_waivers_bill_id_due_date ON bill_late_fee_waivers(bill_id, due_date);
+CREATE INDEX IF NOT EXISTS idx_bill_late_fee_waivers_user_id ON bill_late_fee_waivers(user_id);
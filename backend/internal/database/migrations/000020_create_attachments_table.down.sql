@@ -0,0 +1,5 @@
+-- Drop attachments table
+DROP INDEX IF EXISTS idx_attachments_payment_id;
+DROP INDEX IF EXISTS idx_attachments_bill_id;
+DROP INDEX IF EXISTS idx_attachments_user_id;
+DROP TABLE IF EXISTS attachments;
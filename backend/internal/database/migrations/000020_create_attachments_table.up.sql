@@ -0,0 +1,19 @@
+-- Create attachments table. Files attached to a bill or one of its payments are kept in the configured
+-- storage under storage_key. There are no foreign keys to bills and payments: when the parent is deleted
+-- the row is left behind so the cleanup job can remove the stored file before deleting it.
+CREATE TABLE IF NOT EXISTS attachments (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    bill_id TEXT NOT NULL,
+    payment_id TEXT NULL,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    storage_key TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for faster lookups
+CREATE INDEX IF NOT EXISTS idx_attachments_user_id ON attachments(user_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_bill_id ON attachments(bill_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_payment_id ON attachments(payment_id);
@@ -0,0 +1,6 @@
+-- Drop tags and their links to bills
+DROP INDEX IF EXISTS idx_bill_tags_user_id;
+DROP INDEX IF EXISTS idx_bill_tags_tag_id;
+DROP INDEX IF EXISTS idx_tags_user_id_name;
+DROP TABLE IF EXISTS bill_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,25 @@
+-- Create tags table. Tags are labels that cut across categories, e.g. "tax-deductible" or "business".
+CREATE TABLE IF NOT EXISTS tags (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    color TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create bill_tags table linking bills to any number of tags
+CREATE TABLE IF NOT EXISTS bill_tags (
+    bill_id TEXT NOT NULL,
+    tag_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    PRIMARY KEY (bill_id, tag_id),
+    FOREIGN KEY (bill_id) REFERENCES bills(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create indexes for faster lookups
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_id_name ON tags(user_id, name);
+CREATE INDEX IF NOT EXISTS idx_bill_tags_tag_id ON bill_tags(tag_id);
+CREATE INDEX IF NOT EXISTS idx_bill_tags_user_id ON bill_tags(user_id);
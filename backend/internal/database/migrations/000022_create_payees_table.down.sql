@@ -0,0 +1,5 @@
+-- Drop payees
+DROP INDEX IF EXISTS idx_bills_payee_id;
+ALTER TABLE bills DROP COLUMN payee_id;
+DROP INDEX IF EXISTS idx_payees_user_id;
+DROP TABLE IF EXISTS payees;
@@ -0,0 +1,21 @@
+-- Create payees table. A payee is the provider bills are owed to, with the details needed to pay it.
+-- account_number is encrypted by the application before it is stored.
+CREATE TABLE IF NOT EXISTS payees (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    website TEXT NOT NULL DEFAULT '',
+    phone TEXT NOT NULL DEFAULT '',
+    account_number TEXT NOT NULL DEFAULT '',
+    portal_url TEXT NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_payees_user_id ON payees(user_id);
+
+-- Any number of bills can be owed to the same payee
+ALTER TABLE bills ADD COLUMN payee_id TEXT NULL REFERENCES payees(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_bills_payee_id ON bills(payee_id);
@@ -0,0 +1,7 @@
+-- Drop funding accounts
+DROP INDEX IF EXISTS idx_payments_account_id;
+DROP INDEX IF EXISTS idx_bills_account_id;
+ALTER TABLE payments DROP COLUMN account_id;
+ALTER TABLE bills DROP COLUMN account_id;
+DROP INDEX IF EXISTS idx_funding_accounts_user_id;
+DROP TABLE IF EXISTS funding_accounts;
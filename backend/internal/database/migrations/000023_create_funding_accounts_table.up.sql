@@ -0,0 +1,23 @@
+-- Create funding_accounts table: the checking accounts, cards and other sources bills are paid from.
+-- current_balance is the balance the user last entered, as of balance_updated_at.
+CREATE TABLE IF NOT EXISTS funding_accounts (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL DEFAULT 'checking' CHECK(type IN ('checking', 'savings', 'credit_card', 'cash', 'other')),
+    current_balance REAL NOT NULL DEFAULT 0,
+    balance_updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    low_balance_threshold REAL NOT NULL DEFAULT 0,
+    notes TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_funding_accounts_user_id ON funding_accounts(user_id);
+
+-- Bills are paid from an account by default; each payment records the account it was actually paid from
+ALTER TABLE bills ADD COLUMN account_id TEXT NULL REFERENCES funding_accounts(id) ON DELETE SET NULL;
+ALTER TABLE payments ADD COLUMN account_id TEXT NULL REFERENCES funding_accounts(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_bills_account_id ON bills(account_id);
+CREATE INDEX IF NOT EXISTS idx_payments_account_id ON payments(account_id);
@@ -0,0 +1,13 @@
+-- Create user_preferences table: one row per user holding the timezone due dates are calculated in and
+-- how amounts and dates are displayed. Users without a row use the instance defaults.
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id TEXT PRIMARY KEY,
+    timezone TEXT NOT NULL,
+    locale TEXT NOT NULL DEFAULT 'en-US',
+    first_day_of_week INTEGER NOT NULL DEFAULT 0 CHECK(first_day_of_week BETWEEN 0 AND 6),
+    currency TEXT NOT NULL DEFAULT 'USD',
+    date_format TEXT NOT NULL DEFAULT 'YYYY-MM-DD' CHECK(date_format IN ('YYYY-MM-DD', 'MM/DD/YYYY', 'DD/MM/YYYY', 'DD.MM.YYYY')),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+-- Add pending account deletion to users. An account is permanently deleted once auth.deletion_grace_period has
+-- passed since deletion_requested_at, unless the user cancels the deletion first.
+ALTER TABLE users ADD COLUMN deletion_requested_at DATETIME NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_requested_at ON users(deletion_requested_at);
@@ -0,0 +1,276 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cryptk/williams/internal/config"
+)
+
+// keySize is the length of an AES-256 key in bytes
+const keySize = 32
+
+// Prefixes mark stored values as ciphertext and name the format they were written in. v1 values are
+// sealed directly with a master key; v2 values are sealed with their own data key, which is in turn
+// sealed with the master key named in the value. Anything else is legacy plaintext.
+const (
+	prefix   = "enc:"
+	prefixV1 = "enc:v1:"
+	prefixV2 = "enc:v2:"
+)
+
+// ErrInvalidCiphertext is returned when a stored value cannot be decrypted with the configured keys
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+// masterKey is a key-encryption key, identified by a fingerprint stored with each value it protects
+type masterKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// Cipher encrypts sensitive values such as notes and account numbers before they are stored. Each
+// value gets a fresh data key (envelope encryption), so rotating the master key only rewraps data keys.
+type Cipher struct {
+	primary *masterKey            // Encrypts new values
+	keys    map[string]*masterKey // Every known key by ID, for decryption
+}
+
+// New creates a cipher from configuration. The primary key is read from encryption.key when set,
+// otherwise from encryption.key_file, which is generated on first start if it does not exist. Keys in
+// encryption.previous_keys are only used to decrypt values written before a rotation.
+func New(cfg *config.EncryptionConfig) (*Cipher, error) {
+	key, err := loadKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+	primary, err := newMasterKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cipher{primary: primary, keys: map[string]*masterKey{primary.id: primary}}
+	for i, encoded := range cfg.PreviousKeys {
+		key, err := decodeKey(encoded, fmt.Sprintf("encryption.previous_keys[%d]", i))
+		if err != nil {
+			return nil, err
+		}
+		previous, err := newMasterKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := c.keys[previous.id]; !ok {
+			c.keys[previous.id] = previous
+		}
+	}
+	return c, nil
+}
+
+// KeyID returns the fingerprint of the primary key
+func (c *Cipher) KeyID() string {
+	return c.primary.id
+}
+
+// Encrypt encrypts plaintext with AES-256-GCM under a new data key, which is sealed with the primary
+// key. Empty values are stored as is.
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	sealedValue, err := seal(dataAEAD, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	sealedKey, err := seal(c.primary.aead, dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	return prefixV2 + c.primary.id + ":" +
+		base64.StdEncoding.EncodeToString(sealedKey) + ":" +
+		base64.StdEncoding.EncodeToString(sealedValue), nil
+}
+
+// Decrypt reverses Encrypt. Values without an encryption prefix are returned unchanged, so columns
+// written before they were encrypted stay readable until they are re-encrypted.
+func (c *Cipher) Decrypt(stored string) (string, error) {
+	switch {
+	case strings.HasPrefix(stored, prefixV2):
+		return c.decryptV2(strings.TrimPrefix(stored, prefixV2))
+	case strings.HasPrefix(stored, prefixV1):
+		return c.decryptV1(strings.TrimPrefix(stored, prefixV1))
+	case strings.HasPrefix(stored, prefix):
+		return "", ErrInvalidCiphertext
+	default:
+		return stored, nil
+	}
+}
+
+// NeedsRotation reports whether a stored value is plaintext, in an older format or sealed with a key
+// other than the primary one
+func (c *Cipher) NeedsRotation(stored string) bool {
+	if stored == "" {
+		return false
+	}
+	encoded, ok := strings.CutPrefix(stored, prefixV2)
+	if !ok {
+		return true
+	}
+	keyID, _, _ := strings.Cut(encoded, ":")
+	return keyID != c.primary.id
+}
+
+// decryptV2 opens a value sealed with its own data key
+func (c *Cipher) decryptV2(encoded string) (string, error) {
+	parts := strings.Split(encoded, ":")
+	if len(parts) != 3 {
+		return "", ErrInvalidCiphertext
+	}
+	key, ok := c.keys[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown key %s", ErrInvalidCiphertext, parts[0])
+	}
+	sealedKey, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	sealedValue, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+
+	dataKey, err := open(key.aead, sealedKey)
+	if err != nil {
+		return "", err
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	plaintext, err := open(dataAEAD, sealedValue)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// decryptV1 opens a value sealed directly with a master key. The format does not name its key, so
+// every known key is tried.
+func (c *Cipher) decryptV1(encoded string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	for _, key := range c.keys {
+		if plaintext, err := open(key.aead, sealed); err == nil {
+			return string(plaintext), nil
+		}
+	}
+	return "", ErrInvalidCiphertext
+}
+
+// seal encrypts data under a random nonce, which is prepended to the result
+func seal(aead cipher.AEAD, data []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// open reverses seal
+func open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+	nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	data, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return data, nil
+}
+
+// newAEAD creates an AES-GCM AEAD from a 256-bit key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// newMasterKey creates a master key identified by the start of the SHA-256 hash of the key
+func newMasterKey(key []byte) (*masterKey, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(key)
+	return &masterKey{id: hex.EncodeToString(sum[:4]), aead: aead}, nil
+}
+
+// loadKey returns the configured key, creating the key file with a random key when needed
+func loadKey(cfg *config.EncryptionConfig) ([]byte, error) {
+	if cfg.Key != "" {
+		return decodeKey(cfg.Key, "encryption.key")
+	}
+	if cfg.KeyFile == "" {
+		return nil, fmt.Errorf("either encryption.key or encryption.key_file must be set")
+	}
+
+	data, err := os.ReadFile(cfg.KeyFile)
+	if err == nil {
+		return decodeKey(strings.TrimSpace(string(data)), cfg.KeyFile)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read encryption key file: %w", err)
+	}
+
+	key, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.KeyFile), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create encryption key directory: %w", err)
+	}
+	if err := os.WriteFile(cfg.KeyFile, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write encryption key file: %w", err)
+	}
+	return key, nil
+}
+
+// generateKey returns a new random 256-bit master key
+func generateKey() ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// decodeKey decodes a base64 encoded 256-bit key
+func decodeKey(encoded, source string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("%s must be a base64 encoded %d byte key", source, keySize)
+	}
+	return key, nil
+}
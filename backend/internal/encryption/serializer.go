@@ -0,0 +1,70 @@
+package encryption
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName is the GORM serializer encrypting a string column, used as gorm:"serializer:encrypted"
+const SerializerName = "encrypted"
+
+// active is the cipher the serializer encrypts and decrypts with
+var active atomic.Pointer[Cipher]
+
+func init() {
+	schema.RegisterSerializer(SerializerName, Serializer{})
+}
+
+// Use sets the cipher columns tagged with the encrypted serializer are encrypted with. It must be
+// called before any of those columns are read or written.
+func Use(c *Cipher) {
+	active.Store(c)
+}
+
+// Serializer encrypts string fields when they are written and decrypts them when they are read, so
+// repositories and services only ever see plaintext
+type Serializer struct{}
+
+// Scan decrypts a stored value into the field
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
+	c := active.Load()
+	if c == nil {
+		return errors.New("encryption is not initialized")
+	}
+
+	var stored string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		stored = v
+	case []byte:
+		stored = string(v)
+	default:
+		return fmt.Errorf("cannot decrypt %s from %T", field.Name, dbValue)
+	}
+
+	plaintext, err := c.Decrypt(stored)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s: %w", field.Name, err)
+	}
+	field.ReflectValueOf(ctx, dst).SetString(plaintext)
+	return nil
+}
+
+// Value encrypts the field for storage
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue any) (any, error) {
+	c := active.Load()
+	if c == nil {
+		return nil, errors.New("encryption is not initialized")
+	}
+	plaintext, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("cannot encrypt %s of type %T", field.Name, fieldValue)
+	}
+	return c.Encrypt(plaintext)
+}
@@ -0,0 +1,72 @@
+package mailer
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cryptk/williams/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// Mailer sends plain text email
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// New creates a mailer from configuration.
+// If no SMTP host is configured, messages are written to the log instead of being sent,
+// which keeps password reset and verification usable in development.
+func New(cfg *config.EmailConfig) Mailer {
+	if cfg.SMTPHost == "" {
+		log.Warn().Msg("No SMTP host configured, emails will be written to the log instead of being sent")
+		return &logMailer{}
+	}
+	return &smtpMailer{cfg: cfg}
+}
+
+// smtpMailer delivers email through an SMTP relay
+type smtpMailer struct {
+	cfg *config.EmailConfig
+}
+
+// Send implements Mailer. STARTTLS is used automatically when the server offers it.
+func (m *smtpMailer) Send(to, subject, body string) error {
+	addr := net.JoinHostPort(m.cfg.SMTPHost, strconv.Itoa(m.cfg.SMTPPort))
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.SMTPHost)
+	}
+
+	if err := smtp.SendMail(addr, auth, m.cfg.From, []string{to}, buildMessage(m.cfg.From, to, subject, body)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// logMailer writes messages to the log instead of sending them
+type logMailer struct{}
+
+// Send implements Mailer
+func (m *logMailer) Send(to, subject, body string) error {
+	log.Info().Str("to", to).Str("subject", subject).Str("body", body).Msg("Email (not sent, no SMTP host configured)")
+	return nil
+}
+
+// buildMessage assembles an RFC 5322 message with CRLF line endings
+func buildMessage(from, to, subject, body string) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + to + "\r\n")
+	b.WriteString("Subject: " + subject + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String())
+}
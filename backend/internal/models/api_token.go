@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// API token scopes
+const (
+	APITokenScopeRead      = "read"       // GET requests only
+	APITokenScopeReadWrite = "read_write" // Full API access
+)
+
+// APIToken represents a personal access token used by scripts and integrations
+type APIToken struct {
+	ID          string     `json:"id" gorm:"primaryKey"`
+	UserID      string     `json:"user_id" gorm:"not null;index"`
+	Name        string     `json:"name" gorm:"not null"`
+	TokenHash   string     `json:"-" gorm:"not null;uniqueIndex"`                // SHA-256 of the token, the plaintext is only shown once
+	TokenPrefix string     `json:"token_prefix" gorm:"not null"`                 // First characters of the token, to help users identify it
+	Scope       string     `json:"scope" gorm:"not null;default:read"`           // read or read_write
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`                         // Nil means the token never expires
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`                       // Updated when the token authenticates a request
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime" binding:"-"` // Read-only, managed by backend
+}
+
+// CreateAPITokenRequest represents a request to create a personal access token
+type CreateAPITokenRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scope     string     `json:"scope" binding:"required,oneof=read read_write"`
+	ExpiresAt *time.Time `json:"expires_at"` // Optional expiry
+}
+
+// CreateAPITokenResponse contains a newly created token. The plaintext token is only returned here.
+type CreateAPITokenResponse struct {
+	Token    string   `json:"token"`
+	APIToken APIToken `json:"api_token"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Attachment is a file, such as a statement PDF or payment confirmation, attached to a bill or one of
+// its payments
+type Attachment struct {
+	ID          string    `json:"id" gorm:"primaryKey"`
+	UserID      string    `json:"user_id" gorm:"not null;index"`
+	BillID      string    `json:"bill_id" gorm:"not null;index"`
+	PaymentID   *string   `json:"payment_id,omitempty" gorm:"index"` // Set when attached to a payment rather than the bill
+	FileName    string    `json:"file_name" gorm:"not null"`
+	ContentType string    `json:"content_type" gorm:"not null"` // Detected from the file contents
+	Size        int64     `json:"size" gorm:"not null"`
+	StorageKey  string    `json:"-" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Computed fields (not stored in database)
+	DownloadURL string `json:"download_url" gorm:"-"`
+}
@@ -0,0 +1,90 @@
+package models
+
+import "time"
+
+// Audit event actions
+const (
+	AuditActionRegister          = "auth.register"
+	AuditActionLogin             = "auth.login"
+	AuditActionLoginFailed       = "auth.login_failed"
+	AuditActionLockout           = "auth.lockout"
+	AuditActionPasswordChange    = "auth.password_change"
+	AuditActionPasswordReset     = "auth.password_reset"
+	AuditActionEmailVerified     = "auth.email_verified"
+	AuditActionProfileUpdate     = "auth.profile_update"
+	AuditActionDataExport        = "auth.data_export"
+	AuditActionDeletionRequest   = "auth.deletion_request"
+	AuditActionDeletionCancel    = "auth.deletion_cancel"
+	AuditActionUserDelete        = "auth.user_delete"
+	AuditActionTOTPEnable        = "auth.2fa_enable"
+	AuditActionTOTPDisable       = "auth.2fa_disable"
+	AuditActionTOTPReset         = "auth.2fa_reset"
+	AuditActionTOTPRecoveryCodes = "auth.2fa_recovery_codes"
+	AuditActionIdentityLink      = "auth.identity_link"
+	AuditActionAPITokenCreate    = "api_token.create"
+	AuditActionAPITokenRevoke    = "api_token.revoke"
+	AuditActionBillCreate        = "bill.create"
+	AuditActionBillUpdate        = "bill.update"
+	AuditActionBillDelete        = "bill.delete"
+	AuditActionBillArchive       = "bill.archive"
+	AuditActionBillUnarchive     = "bill.unarchive"
+	AuditActionBillRestore       = "bill.restore"
+	AuditActionBillPause         = "bill.pause"
+	AuditActionBillUnpause       = "bill.unpause"
+	AuditActionBillSkip          = "bill.skip"
+	AuditActionBillUnskip        = "bill.unskip"
+	AuditActionLateFeeWaive      = "bill.late_fee_waive"
+	AuditActionLateFeeUnwaive    = "bill.late_fee_unwaive"
+	AuditActionPaymentCreate     = "payment.create"
+	AuditActionPaymentDelete     = "payment.delete"
+	AuditActionPaymentAutopay    = "payment.autopay"
+	AuditActionPaymentReverse    = "payment.reverse"
+	AuditActionStatementSave     = "statement.save"
+	AuditActionStatementDelete   = "statement.delete"
+	AuditActionAttachmentCreate  = "attachment.create"
+	AuditActionAttachmentDelete  = "attachment.delete"
+	AuditActionCategoryCreate    = "category.create"
+	AuditActionCategoryDelete    = "category.delete"
+	AuditActionCategoryArchive   = "category.archive"
+	AuditActionCategoryUnarchive = "category.unarchive"
+	AuditActionCategoryRestore   = "category.restore"
+	AuditActionTagCreate         = "tag.create"
+	AuditActionTagUpdate         = "tag.update"
+	AuditActionTagDelete         = "tag.delete"
+	AuditActionAccountCreate     = "account.create"
+	AuditActionAccountUpdate     = "account.update"
+	AuditActionAccountDelete     = "account.delete"
+	AuditActionPayeeCreate       = "payee.create"
+	AuditActionPayeeUpdate       = "payee.update"
+	AuditActionPayeeDelete       = "payee.delete"
+	AuditActionHolidayCreate     = "holiday.create"
+	AuditActionHolidayDelete     = "holiday.delete"
+	AuditActionPreferencesUpdate = "preferences.update"
+)
+
+// AuditEvent is an append-only record of a security-relevant or data-changing action
+type AuditEvent struct {
+	ID         string                 `json:"id" gorm:"primaryKey"`
+	UserID     *string                `json:"user_id,omitempty" gorm:"index"`                     // Account the event belongs to; nil for failed logins against unknown usernames
+	ActorID    *string                `json:"actor_id,omitempty"`                                 // Who performed the action; differs from UserID for admin actions
+	Action     string                 `json:"action" gorm:"not null"`                             // One of the AuditAction constants
+	EntityType string                 `json:"entity_type,omitempty"`                              // e.g. bill, payment
+	EntityID   string                 `json:"entity_id,omitempty"`                                // ID of the affected record
+	Changes    map[string]AuditChange `json:"changes,omitempty" gorm:"serializer:json"`           // Field-level before/after values
+	Details    string                 `json:"details,omitempty"`                                  // Free-form context, e.g. the attempted username of a failed login
+	IPAddress  string                 `json:"ip_address,omitempty"`                               // Client IP of the request
+	UserAgent  string                 `json:"user_agent,omitempty"`                               // User-Agent header of the request
+	CreatedAt  time.Time              `json:"created_at" gorm:"autoCreateTime;index" binding:"-"` // Read-only, managed by backend
+}
+
+// AuditChange holds the old and new value of a single field
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditListResponse is a page of audit events, newest first
+type AuditListResponse struct {
+	Events     []*AuditEvent `json:"events"`
+	NextBefore *time.Time    `json:"next_before,omitempty"` // Pass as ?before= to fetch the next page
+}
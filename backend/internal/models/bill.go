@@ -1,25 +1,105 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Bill represents a bill entity
 type Bill struct {
-	ID             string     `json:"id" gorm:"primaryKey"`
-	UserID         string     `json:"user_id" gorm:"not null"`
-	Name           string     `json:"name" gorm:"not null" binding:"required"`
-	Amount         float64    `json:"amount" gorm:"not null" binding:"required,gt=0"`
-	RecurrenceDays int        `json:"recurrence_days" gorm:"not null;check:recurrence_days >= 1" binding:"required,min=1"`
-	CategoryID     *string    `json:"category_id"`
-	RecurrenceType string     `json:"recurrence_type" gorm:"default:none;check:recurrence_type IN ('none', 'fixed_date', 'interval')" binding:"oneof=none fixed_date interval"`
-	StartDate      *time.Time `json:"start_date,omitempty"` // Used for interval and one-time bills
-	Notes          string     `json:"notes"`
-	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime" binding:"-"` // Read-only, managed by backend
-	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime" binding:"-"` // Read-only, managed by backend
+	ID               string         `json:"id" gorm:"primaryKey"`
+	UserID           string         `json:"user_id" gorm:"not null"`
+	Name             string         `json:"name" gorm:"not null" binding:"required"`
+	Amount           float64        `json:"amount" gorm:"not null" binding:"omitempty,gt=0"` // Required for standard bills; derived from the loan terms for loans
+	RecurrenceDays   int            `json:"recurrence_days" gorm:"not null;check:recurrence_days >= 1" binding:"required,min=1"`
+	CategoryID       *string        `json:"category_id"`
+	PayeeID          *string        `json:"payee_id"`   // Provider the bill is owed to
+	AccountID        *string        `json:"account_id"` // Funding account the bill is paid from
+	RecurrenceType   string         `json:"recurrence_type" gorm:"default:none;check:recurrence_type IN ('none', 'fixed_date', 'interval')" binding:"oneof=none fixed_date interval"`
+	Kind             string         `json:"kind" gorm:"default:standard" binding:"omitempty,oneof=standard loan"`                                  // One of the BillKind constants
+	VariableAmount   bool           `json:"variable_amount"`                                                                                       // Amount comes from a statement per occurrence; amount is the estimate until statements exist
+	StartDate        *time.Time     `json:"start_date,omitempty"`                                                                                  // Used for interval and one-time bills
+	EndDate          *time.Time     `json:"end_date,omitempty"`                                                                                    // Recurring bills stop after this date
+	MaxOccurrences   *int           `json:"max_occurrences,omitempty" binding:"omitempty,min=1"`                                                   // Recurring bills stop after this many billed occurrences
+	BusinessDayRoll  string         `json:"business_day_roll" gorm:"default:none" binding:"omitempty,oneof=none previous next modified_following"` // How due dates on weekends and holidays are moved
+	HolidayCalendar  string         `json:"holiday_calendar"`                                                                                      // Country code of the holidays to observe; empty observes weekends and the user's own holidays only
+	Autopay          bool           `json:"autopay"`                                                                                               // Payments are recorded automatically on each due date
+	AutopayAccount   string         `json:"autopay_account" gorm:"serializer:encrypted"`                                                           // Account the bill is debited from, for reference
+	AutopayNotes     string         `json:"autopay_notes" gorm:"serializer:encrypted"`                                                             // Notes copied onto generated payments
+	AutopayEnabledAt *time.Time     `json:"autopay_enabled_at,omitempty" binding:"-"`                                                              // Read-only, occurrences due before this are not paid automatically
+	Notes            string         `json:"notes" gorm:"serializer:encrypted"`
+	CreatedAt        time.Time      `json:"created_at" gorm:"autoCreateTime" binding:"-"`  // Read-only, managed by backend
+	UpdatedAt        time.Time      `json:"updated_at" gorm:"autoUpdateTime" binding:"-"`  // Read-only, managed by backend
+	ArchivedAt       *time.Time     `json:"archived_at,omitempty" binding:"-"`             // Read-only, set via the archive endpoints
+	DeletedAt        gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" binding:"-"` // Read-only, set when moved to the trash
+
+	// Write-only: when amount, recurrence or category changes in an update take effect. Defaults to now.
+	EffectiveFrom *time.Time `json:"effective_from,omitempty" gorm:"-"`
+
+	// Terms of a loan bill, stored in bill_loans
+	Loan *BillLoan `json:"loan,omitempty" gorm:"-"`
+
+	// Late fee charged on overdue occurrences, stored in bill_late_fee_rules
+	LateFeeRule *BillLateFeeRule `json:"late_fee_rule,omitempty" gorm:"-"`
+
+	// IDs of the bill's tags, stored in bill_tags. Omitting them in an update keeps the current tags.
+	TagIDs []string `json:"tag_ids" gorm:"-"`
 
 	// Computed fields (not stored in database)
-	IsPaid       bool       `json:"is_paid" gorm:"-"`
-	NextDueDate  *time.Time `json:"next_due_date,omitempty" gorm:"-"`
-	LastPaidDate *time.Time `json:"last_paid_date,omitempty" gorm:"-"`
+	IsPaid         bool       `json:"is_paid" gorm:"-"`
+	NextDueDate    *time.Time `json:"next_due_date,omitempty" gorm:"-"`
+	LastPaidDate   *time.Time `json:"last_paid_date,omitempty" gorm:"-"`
+	NominalDueDate *time.Time `json:"nominal_due_date,omitempty" gorm:"-"` // Scheduled due date when next_due_date was moved off a weekend or holiday
+	Status         string     `json:"status" gorm:"-"`                     // One of the BillStatus constants
+	Tags           []*Tag     `json:"tags,omitempty" gorm:"-"`             // The tags named by tag_ids
+	Payee          *Payee     `json:"payee,omitempty" gorm:"-"`            // The payee named by payee_id, with how to pay it
+
+	// Computed for variable amount bills
+	CurrentStatement *BillStatement `json:"current_statement,omitempty" gorm:"-"` // Latest statement due by next_due_date
+	EstimatedAmount  *float64       `json:"estimated_amount,omitempty" gorm:"-"`  // Average of recent statements, used until a statement is entered
+	PaymentStatus    string         `json:"payment_status,omitempty" gorm:"-"`    // Payment status of the current statement
+
+	// Computed for bills with a late fee rule
+	LateFees     []*LateFee `json:"late_fees,omitempty" gorm:"-"`      // Fees of the occurrences overdue past the rule's grace days
+	LateFeeTotal float64    `json:"late_fee_total,omitempty" gorm:"-"` // Total of the fees that were not waived
+}
+
+// Bill statuses, computed from the bill's end conditions and pauses
+const (
+	BillStatusActive  = "active"
+	BillStatusPaused  = "paused"   // A pause covers today; next_due_date is the first occurrence after it
+	BillStatusEnded   = "ended"    // The end date or maximum occurrences has been reached
+	BillStatusPaidOff = "paid_off" // A loan bill whose balance has been repaid
+)
+
+// BillPause suspends a recurring bill: occurrences between StartDate and EndDate are not billed.
+// A pause without an EndDate lasts until it is removed.
+type BillPause struct {
+	ID        string     `json:"id" gorm:"primaryKey"`
+	BillID    string     `json:"bill_id" gorm:"not null;index"` // Set from URL param, not request body
+	UserID    string     `json:"user_id" gorm:"not null;index"` // Set automatically from authenticated user
+	StartDate time.Time  `json:"start_date" gorm:"not null" binding:"required"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+	Notes     string     `json:"notes"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime" binding:"-"` // Read-only, managed by backend
+}
+
+// BillSkip waives a single occurrence of a recurring bill, e.g. a month the provider did not charge
+type BillSkip struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	BillID    string    `json:"bill_id" gorm:"not null;index"` // Set from URL param, not request body
+	UserID    string    `json:"user_id" gorm:"not null;index"` // Set automatically from authenticated user
+	DueDate   time.Time `json:"due_date" gorm:"not null" binding:"required"`
+	Notes     string    `json:"notes"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" binding:"-"` // Read-only, managed by backend
+}
+
+// BillListResponse is a page of bills
+type BillListResponse struct {
+	Bills      []*Bill `json:"bills"`
+	Total      int64   `json:"total"`                 // Bills matching the filters across all pages
+	NextCursor string  `json:"next_cursor,omitempty"` // Pass as ?cursor= to fetch the next page
 }
 
 // Payment represents a payment made for a bill
@@ -29,17 +109,36 @@ type Payment struct {
 	UserID      string    `json:"user_id" gorm:"not null;index"` // Set automatically from authenticated user
 	Amount      float64   `json:"amount" gorm:"not null" binding:"required,gt=0"`
 	PaymentDate time.Time `json:"payment_date" gorm:"not null" binding:"required"`
-	Notes       string    `json:"notes"`
+	Notes       string    `json:"notes" gorm:"serializer:encrypted"`
+	AccountID   *string   `json:"account_id"`                                   // Funding account paid from; defaults to the bill's account
 	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime" binding:"-"` // Read-only, managed by backend
+
+	AutoGenerated  bool       `json:"auto_generated" binding:"-"`            // Read-only, recorded by autopay
+	ReversedAt     *time.Time `json:"reversed_at,omitempty" binding:"-"`     // Read-only, set when an autopay debit failed
+	ReversalReason string     `json:"reversal_reason,omitempty" binding:"-"` // Read-only, set when an autopay debit failed
+}
+
+// PaymentListResponse is a page of payments
+type PaymentListResponse struct {
+	Payments   []*Payment `json:"payments"`
+	Total      int64      `json:"total"`                 // Payments matching the filters across all pages
+	NextCursor string     `json:"next_cursor,omitempty"` // Pass as ?cursor= to fetch the next page
+}
+
+// ReversePaymentRequest marks an autopay payment as failed
+type ReversePaymentRequest struct {
+	Reason string `json:"reason"`
 }
 
 // Category represents a bill category
 type Category struct {
-	ID        string    `json:"id" gorm:"primaryKey"`
-	UserID    string    `json:"user_id" gorm:"not null;index"`
-	Name      string    `json:"name" gorm:"not null" binding:"required"`
-	Color     string    `json:"color"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" binding:"-"` // Read-only, managed by backend
+	ID         string         `json:"id" gorm:"primaryKey"`
+	UserID     string         `json:"user_id" gorm:"not null;index"`
+	Name       string         `json:"name" gorm:"not null" binding:"required"`
+	Color      string         `json:"color"`
+	CreatedAt  time.Time      `json:"created_at" gorm:"autoCreateTime" binding:"-"`  // Read-only, managed by backend
+	ArchivedAt *time.Time     `json:"archived_at,omitempty" binding:"-"`             // Read-only, set via the archive endpoints
+	DeletedAt  gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" binding:"-"` // Read-only, set when moved to the trash
 }
 
 // BillStats represents bill statistics
@@ -50,4 +149,50 @@ type BillStats struct {
 	PaidBills     int     `json:"paid_bills"`
 	UnpaidBills   int     `json:"unpaid_bills"`
 	UpcomingBills int     `json:"upcoming_bills"`
+	PausedBills   int     `json:"paused_bills"`
+	EndedBills    int     `json:"ended_bills"`
+	LateFees      float64 `json:"late_fees"` // Late fees accrued by unpaid bills, included in the due amount
+}
+
+// BillVersion records the amount, recurrence and category of a bill from EffectiveFrom onward.
+// A new version is added whenever an update changes one of these fields.
+type BillVersion struct {
+	ID             string    `json:"id" gorm:"primaryKey"`
+	BillID         string    `json:"bill_id" gorm:"not null;index"`
+	UserID         string    `json:"user_id" gorm:"not null;index"`
+	Amount         float64   `json:"amount" gorm:"not null"`
+	RecurrenceType string    `json:"recurrence_type" gorm:"not null"`
+	RecurrenceDays int       `json:"recurrence_days" gorm:"not null"`
+	CategoryID     *string   `json:"category_id"`
+	EffectiveFrom  time.Time `json:"effective_from" gorm:"not null"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime" binding:"-"` // Read-only, managed by backend
+}
+
+// ForecastOccurrence is a single projected due date of a bill
+type ForecastOccurrence struct {
+	BillID    string    `json:"bill_id"`
+	BillName  string    `json:"bill_name"`
+	DueDate   time.Time `json:"due_date"`
+	Amount    float64   `json:"amount"`              // Amount effective on the due date
+	Skipped   bool      `json:"skipped,omitempty"`   // Waived occurrence; not included in the total
+	Estimated bool      `json:"estimated,omitempty"` // Variable amount without a statement yet
+}
+
+// BillForecast lists the unpaid occurrences of all bills within a date range
+type BillForecast struct {
+	From        time.Time             `json:"from"`
+	To          time.Time             `json:"to"`
+	TotalAmount float64               `json:"total_amount"`
+	Occurrences []*ForecastOccurrence `json:"occurrences"`
+}
+
+// PriceChange describes a change in a bill's amount between two consecutive versions
+type PriceChange struct {
+	BillID        string    `json:"bill_id"`
+	BillName      string    `json:"bill_name"`
+	OldAmount     float64   `json:"old_amount"`
+	NewAmount     float64   `json:"new_amount"`
+	Change        float64   `json:"change"`
+	PercentChange float64   `json:"percent_change"`
+	EffectiveFrom time.Time `json:"effective_from"`
 }
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// UserDataExport is everything stored about a user, returned by the personal data export.
+// Bills and categories in the trash are included; attachment files are downloaded via their download_url.
+type UserDataExport struct {
+	ExportedAt      time.Time            `json:"exported_at"`
+	User            *User                `json:"user"`
+	Preferences     *UserPreferences     `json:"preferences"`
+	Categories      []*Category          `json:"categories"`
+	Tags            []*Tag               `json:"tags"`
+	Payees          []*Payee             `json:"payees"`
+	FundingAccounts []*FundingAccount    `json:"funding_accounts"`
+	Bills           []*Bill              `json:"bills"` // With their loan terms, late fee rule and tag IDs
+	BillVersions    []*BillVersion       `json:"bill_versions"`
+	BillPauses      []*BillPause         `json:"bill_pauses"`
+	BillSkips       []*BillSkip          `json:"bill_skips"`
+	BillStatements  []*BillStatement     `json:"bill_statements"`
+	LateFeeWaivers  []*BillLateFeeWaiver `json:"late_fee_waivers"`
+	Payments        []*Payment           `json:"payments"`
+	Attachments     []*Attachment        `json:"attachments"`
+	Holidays        []*UserHoliday       `json:"holidays"`
+	APITokens       []*APIToken          `json:"api_tokens"`
+	Identities      []*UserIdentity      `json:"identities"` // Linked single sign-on accounts
+	AuditEvents     []*AuditEvent        `json:"audit_events"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Funding account types
+const (
+	FundingAccountChecking   = "checking"
+	FundingAccountSavings    = "savings"
+	FundingAccountCreditCard = "credit_card" // The balance is the available credit
+	FundingAccountCash       = "cash"
+	FundingAccountOther      = "other"
+)
+
+// FundingAccount is a checking account, card or other source bills are paid from
+type FundingAccount struct {
+	ID                  string    `json:"id" gorm:"primaryKey"`
+	UserID              string    `json:"user_id" gorm:"not null;index"`
+	Name                string    `json:"name" gorm:"not null" binding:"required"`
+	Type                string    `json:"type" gorm:"default:checking" binding:"omitempty,oneof=checking savings credit_card cash other"` // One of the FundingAccount type constants
+	CurrentBalance      float64   `json:"current_balance"`                                                                                // Balance as last entered by the user
+	BalanceUpdatedAt    time.Time `json:"balance_updated_at" binding:"-"`                                                                 // Read-only, set when current_balance changes
+	LowBalanceThreshold float64   `json:"low_balance_threshold"`                                                                          // Projections warn when the balance would drop below this
+	Notes               string    `json:"notes"`
+	CreatedAt           time.Time `json:"created_at" gorm:"autoCreateTime" binding:"-"` // Read-only, managed by backend
+	UpdatedAt           time.Time `json:"updated_at" gorm:"autoUpdateTime" binding:"-"` // Read-only, managed by backend
+
+	// Computed fields (not stored in database)
+	Balance float64 `json:"balance" gorm:"-"` // current_balance less the payments from the account dated after balance_updated_at
+}
+
+// ProjectedDay is a day on which bills are due from an account
+type ProjectedDay struct {
+	Date        time.Time             `json:"date"`
+	Occurrences []*ForecastOccurrence `json:"occurrences"`
+	Balance     float64               `json:"balance"` // Projected balance at the end of the day
+}
+
+// AccountProjection projects an account's balance as its bills come due
+type AccountProjection struct {
+	Account           *FundingAccount `json:"account"`
+	Days              []*ProjectedDay `json:"days"`                          // Only days with bills due, oldest first
+	EndingBalance     float64         `json:"ending_balance"`                // Projected balance at the end of the period
+	LowestBalance     float64         `json:"lowest_balance"`                // Lowest projected balance over the period
+	LowestBalanceDate *time.Time      `json:"lowest_balance_date,omitempty"` // Day the lowest balance is reached; unset when nothing is due
+	BelowThresholdOn  *time.Time      `json:"below_threshold_on,omitempty"`  // First day the balance would drop below the account's threshold
+}
+
+// BalanceProjection projects the balances of all accounts over the coming days. Overdue bills are
+// treated as due today.
+type BalanceProjection struct {
+	From       time.Time             `json:"from"`
+	To         time.Time             `json:"to"`
+	Accounts   []*AccountProjection  `json:"accounts"`
+	Unassigned []*ForecastOccurrence `json:"unassigned"` // Bills due in the period that are not paid from any account
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// UserHoliday is a non-business day a user adds on top of the bundled country calendars,
+// e.g. a regional holiday their bank observes
+type UserHoliday struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	UserID    string    `json:"user_id" gorm:"not null;index"`                               // Set automatically from authenticated user
+	Date      string    `json:"date" gorm:"not null" binding:"required,datetime=2006-01-02"` // YYYY-MM-DD
+	Name      string    `json:"name" gorm:"not null" binding:"required"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" binding:"-"` // Read-only, managed by backend
+}
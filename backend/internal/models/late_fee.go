@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// BillLateFeeRule defines the late fee an occurrence of a bill accrues while it is overdue
+type BillLateFeeRule struct {
+	BillID     string    `json:"-" gorm:"primaryKey"`
+	UserID     string    `json:"-" gorm:"not null;index"`
+	FlatFee    float64   `json:"flat_fee" binding:"min=0"`                    // Charged once the grace days have passed
+	Percentage float64   `json:"percentage" binding:"min=0"`                  // Percent of the occurrence amount, charged once the grace days have passed
+	DailyFee   float64   `json:"daily_fee" binding:"min=0"`                   // Charged for each day overdue after the grace days
+	GraceDays  int       `json:"grace_days" binding:"min=0"`                  // Days after the due date before fees apply, independent of bills.payment_grace_days
+	MaxFee     *float64  `json:"max_fee,omitempty" binding:"omitempty,min=0"` // Cap on the fee of a single occurrence
+	CreatedAt  time.Time `json:"-" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"-" gorm:"autoUpdateTime"`
+}
+
+// BillLateFeeWaiver waives the late fee of a single occurrence of a bill
+type BillLateFeeWaiver struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	BillID    string    `json:"bill_id" gorm:"not null;index"` // Set from URL param, not request body
+	UserID    string    `json:"user_id" gorm:"not null;index"` // Set automatically from authenticated user
+	DueDate   time.Time `json:"due_date" gorm:"not null" binding:"required"`
+	Notes     string    `json:"notes"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" binding:"-"` // Read-only, managed by backend
+}
+
+// LateFee is the fee accrued by an overdue occurrence of a bill
+type LateFee struct {
+	DueDate     time.Time `json:"due_date"`
+	DaysOverdue int       `json:"days_overdue"`
+	Amount      float64   `json:"amount"`
+	Waived      bool      `json:"waived,omitempty"`
+	WaiverNotes string    `json:"waiver_notes,omitempty"`
+}
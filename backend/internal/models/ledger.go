@@ -0,0 +1,26 @@
+package models
+
+// LedgerEntry is a payment in the register of all bills, with the bill and category it belongs to
+type LedgerEntry struct {
+	*Payment
+	BillName     string  `json:"bill_name"`
+	CategoryID   *string `json:"category_id"`
+	CategoryName string  `json:"category_name,omitempty"` // Empty when the category is in the trash
+}
+
+// LedgerMonth totals the payments made in a calendar month
+type LedgerMonth struct {
+	Month    string  `json:"month"` // YYYY-MM in the application timezone
+	Total    float64 `json:"total"`
+	Payments int     `json:"payments"`
+}
+
+// PaymentLedger is a page of the payments of all bills. Subtotals cover every matching payment, not
+// just the page, and leave out reversed payments.
+type PaymentLedger struct {
+	Payments    []*LedgerEntry `json:"payments"`
+	Total       int64          `json:"total"`                 // Payments matching the filters across all pages
+	NextCursor  string         `json:"next_cursor,omitempty"` // Pass as ?cursor= to fetch the next page
+	TotalAmount float64        `json:"total_amount"`
+	Months      []*LedgerMonth `json:"months"` // Newest first
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Bill kinds
+const (
+	BillKindStandard = "standard"
+	BillKindLoan     = "loan" // Installment derived from BillLoan terms; completes when paid off
+)
+
+// BillLoan holds the terms of a loan or installment plan bill
+type BillLoan struct {
+	BillID           string    `json:"-" gorm:"primaryKey"`
+	UserID           string    `json:"-" gorm:"not null;index"`
+	Principal        float64   `json:"principal" gorm:"not null" binding:"required,gt=0"`
+	InterestRate     float64   `json:"interest_rate" binding:"min=0"` // Annual percentage rate, e.g. 6.5; 0 for interest-free plans
+	TermPayments     int       `json:"term_payments" gorm:"not null" binding:"required,min=1"`
+	FirstPaymentDate time.Time `json:"first_payment_date" gorm:"not null" binding:"required"`
+	CreatedAt        time.Time `json:"-" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `json:"-" gorm:"autoUpdateTime"`
+}
+
+// AmortizationEntry is one payment of a loan's amortization schedule
+type AmortizationEntry struct {
+	Number    int       `json:"number"`
+	DueDate   time.Time `json:"due_date"`
+	Payment   float64   `json:"payment"`
+	Principal float64   `json:"principal"`
+	Interest  float64   `json:"interest"`
+	Balance   float64   `json:"balance"` // Remaining balance after this payment
+	Paid      bool      `json:"paid"`    // Recorded payment rather than projection
+}
+
+// AmortizationSchedule describes the repayment of a loan bill: recorded payments followed by projected ones
+type AmortizationSchedule struct {
+	BillID            string               `json:"bill_id"`
+	Loan              *BillLoan            `json:"loan"`
+	Installment       float64              `json:"installment"`
+	ExtraPayment      float64              `json:"extra_payment"` // Added to each projected payment
+	RemainingBalance  float64              `json:"remaining_balance"`
+	PaymentsRemaining int                  `json:"payments_remaining"`
+	PayoffDate        *time.Time           `json:"payoff_date,omitempty"`
+	TotalInterest     float64              `json:"total_interest"`
+	TotalPaid         float64              `json:"total_paid"`
+	InterestSaved     float64              `json:"interest_saved"` // Compared with no extra payment
+	PaidOff           bool                 `json:"paid_off"`
+	Entries           []*AmortizationEntry `json:"entries"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Payee is a provider bills are owed to, with the details needed to pay it
+type Payee struct {
+	ID            string    `json:"id" gorm:"primaryKey"`
+	UserID        string    `json:"user_id" gorm:"not null;index"`
+	Name          string    `json:"name" gorm:"not null" binding:"required"`
+	Website       string    `json:"website" binding:"omitempty,url"`
+	Phone         string    `json:"phone"`
+	AccountNumber string    `json:"account_number" gorm:"serializer:encrypted"`
+	PortalURL     string    `json:"portal_url" binding:"omitempty,url"` // Customer portal the bills are paid through
+	Notes         string    `json:"notes" gorm:"serializer:encrypted"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime" binding:"-"` // Read-only, managed by backend
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime" binding:"-"` // Read-only, managed by backend
+}
+
+// PayeeDetails is a payee with everything owed to it
+type PayeeDetails struct {
+	*Payee
+	Bills []*Bill    `json:"bills"` // Bills owed to the payee that are not archived
+	Stats *BillStats `json:"stats"` // Statistics of those bills, including the amount currently due
+}
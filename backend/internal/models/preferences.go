@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Date formats a user can choose to display dates in
+const (
+	DateFormatISO = "YYYY-MM-DD"
+	DateFormatUS  = "MM/DD/YYYY"
+	DateFormatEU  = "DD/MM/YYYY"
+	DateFormatDot = "DD.MM.YYYY"
+)
+
+// UserPreferences are a user's timezone and display settings. Due dates, overdue days and reports are
+// calculated in the user's timezone; the other settings are for clients to format amounts and dates.
+type UserPreferences struct {
+	UserID         string    `json:"-" gorm:"primaryKey"`
+	Timezone       string    `json:"timezone" gorm:"not null"`                                                                          // IANA timezone, e.g. "America/New_York"
+	Locale         string    `json:"locale" gorm:"not null"`                                                                            // BCP 47 language tag, e.g. "en-US"
+	FirstDayOfWeek int       `json:"first_day_of_week" binding:"min=0,max=6"`                                                           // 0 is Sunday, 1 is Monday
+	Currency       string    `json:"currency" gorm:"not null"`                                                                          // ISO 4217 currency code, e.g. "USD"
+	DateFormat     string    `json:"date_format" gorm:"not null" binding:"omitempty,oneof=YYYY-MM-DD MM/DD/YYYY DD/MM/YYYY DD.MM.YYYY"` // One of the DateFormat constants
+	CreatedAt      time.Time `json:"-" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime" binding:"-"` // Read-only, managed by backend
+}
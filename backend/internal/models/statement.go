@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Statement payment statuses
+const (
+	PaymentStatusUnpaid      = "unpaid"
+	PaymentStatusMinimumPaid = "minimum_paid" // At least the minimum payment but less than the statement amount
+	PaymentStatusPaid        = "paid"         // The statement amount was paid in full
+)
+
+// BillStatement holds the amount due for one occurrence of a variable amount bill
+type BillStatement struct {
+	ID             string    `json:"id" gorm:"primaryKey"`
+	BillID         string    `json:"bill_id" gorm:"not null;index"` // Set from URL param, not request body
+	UserID         string    `json:"user_id" gorm:"not null;index"` // Set automatically from authenticated user
+	DueDate        time.Time `json:"due_date" gorm:"not null" binding:"required"`
+	Amount         float64   `json:"amount" gorm:"not null" binding:"min=0"`
+	MinimumPayment *float64  `json:"minimum_payment,omitempty" binding:"omitempty,min=0"`
+	Notes          string    `json:"notes"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime" binding:"-"` // Read-only, managed by backend
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime" binding:"-"` // Read-only, managed by backend
+
+	// Computed fields (not stored in database)
+	PaidAmount    float64 `json:"paid_amount" gorm:"-" binding:"-"`    // Payments made toward this statement
+	PaymentStatus string  `json:"payment_status" gorm:"-" binding:"-"` // One of the PaymentStatus constants
+}
+
+// ImportStatementsRequest adds or replaces several statements of a bill at once
+type ImportStatementsRequest struct {
+	Statements []*BillStatement `json:"statements" binding:"required,min=1,dive"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Tag is a label bills can carry any number of, e.g. "tax-deductible" or "business"
+type Tag struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	UserID    string    `json:"user_id" gorm:"not null;index"`
+	Name      string    `json:"name" gorm:"not null" binding:"required"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" binding:"-"` // Read-only, managed by backend
+}
+
+// BillTag links a bill to one of its tags
+type BillTag struct {
+	BillID string `gorm:"primaryKey"`
+	TagID  string `gorm:"primaryKey"`
+	UserID string `gorm:"not null;index"`
+}
+
+// TagPayments lists the payments of the bills with a tag
+type TagPayments struct {
+	Tag      *Tag           `json:"tag"`
+	Total    float64        `json:"total"`
+	Payments []*LedgerEntry `json:"payments"`
+}
+
+// TagPaymentReport groups a year's payments by tag, e.g. to total tax-deductible expenses.
+// A payment of a bill with several tags is listed under each of them. Reversed payments are left out.
+type TagPaymentReport struct {
+	Year int            `json:"year"`
+	Tags []*TagPayments `json:"tags"`
+}
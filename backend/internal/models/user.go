@@ -6,13 +6,58 @@ import (
 
 // User represents a user account
 type User struct {
-	ID           string    `json:"id" gorm:"primaryKey;type:text"`
-	Username     string    `json:"username" gorm:"uniqueIndex;not null;type:text" binding:"required,min=3,max=50"`
-	Email        string    `json:"email" gorm:"uniqueIndex;not null;type:text" binding:"required,email"`
-	PasswordHash string    `json:"-" gorm:"column:password_hash;not null;type:text"`                     // Never send password hash in JSON
-	Roles        []string  `json:"roles" gorm:"not null;type:text;serializer:json;default:'[\"user\"]'"` // User roles: ["user"], ["admin"], or ["user", "admin"]
-	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime" binding:"-"`                         // Read-only, managed by backend
-	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime" binding:"-"`                         // Read-only, managed by backend
+	ID            string    `json:"id" gorm:"primaryKey;type:text"`
+	Username      string    `json:"username" gorm:"uniqueIndex;not null;type:text" binding:"required,min=3,max=50"`
+	Email         string    `json:"email" gorm:"uniqueIndex;not null;type:text" binding:"required,email"`
+	PasswordHash  string    `json:"-" gorm:"column:password_hash;not null;type:text"`                     // Never send password hash in JSON
+	Roles         []string  `json:"roles" gorm:"not null;type:text;serializer:json;default:'[\"user\"]'"` // User roles: ["user"], ["admin"], or ["user", "admin"]
+	TOTPSecret    string    `json:"-" gorm:"column:totp_secret;not null;type:text;serializer:encrypted"`  // Base32 TOTP secret, set during enrollment
+	TOTPEnabled   bool      `json:"totp_enabled" gorm:"column:totp_enabled;not null"`                     // True once enrollment has been confirmed with a valid code
+	TOTPLastStep  int64     `json:"-" gorm:"column:totp_last_step;not null"`                              // Last accepted TOTP time step, used to reject code replays
+	EmailVerified bool      `json:"email_verified" gorm:"column:email_verified;not null"`                 // True once the user has confirmed ownership of Email
+	TokenVersion  int       `json:"-" gorm:"column:token_version;not null"`                               // Incremented to invalidate all issued session tokens
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime" binding:"-"`                         // Read-only, managed by backend
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime" binding:"-"`                         // Read-only, managed by backend
+
+	DeletionRequestedAt *time.Time `json:"deletion_requested_at,omitempty" binding:"-"` // Read-only, set while the account is scheduled for deletion
+}
+
+// RecoveryCode represents a hashed one-time 2FA recovery code
+type RecoveryCode struct {
+	ID        string     `json:"id" gorm:"primaryKey"`
+	UserID    string     `json:"user_id" gorm:"not null;index"`
+	CodeHash  string     `json:"-" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// User token purposes
+const (
+	UserTokenPasswordReset     = "password_reset"
+	UserTokenEmailVerification = "email_verification"
+)
+
+// UserToken represents a single-use token delivered by email (password reset, email verification)
+type UserToken struct {
+	ID        string     `json:"id" gorm:"primaryKey"`
+	UserID    string     `json:"user_id" gorm:"not null;index"`
+	Purpose   string     `json:"purpose" gorm:"not null"`
+	TokenHash string     `json:"-" gorm:"not null;uniqueIndex"`
+	Email     string     `json:"email" gorm:"not null"` // Address the token was sent to
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// UserIdentity links a local user to an account at an external OpenID Connect provider
+type UserIdentity struct {
+	ID          string     `json:"id" gorm:"primaryKey"`
+	UserID      string     `json:"user_id" gorm:"not null;index"`
+	Issuer      string     `json:"issuer" gorm:"not null"`
+	Subject     string     `json:"subject" gorm:"not null"`
+	Email       string     `json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
 }
 
 // RegisterRequest represents a registration request
@@ -33,3 +78,86 @@ type AuthResponse struct {
 	Token string `json:"token"`
 	User  User   `json:"user"`
 }
+
+// ChangePasswordRequest represents a password change by a logged in user
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
+// ForgotPasswordRequest represents a request for a password reset email
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest represents setting a new password with an emailed reset token
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+// VerifyEmailRequest represents confirming an email address with an emailed token
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// UpdateProfileRequest changes the username and email of a logged in user. Empty fields are left unchanged.
+// Changing the email requires the current password and a new verification of the address.
+type UpdateProfileRequest struct {
+	Username        string `json:"username" binding:"omitempty,min=3,max=50"`
+	Email           string `json:"email" binding:"omitempty,email"`
+	CurrentPassword string `json:"current_password"`
+}
+
+// DeleteAccountRequest confirms a request to delete the logged in user's account
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// AccountDeletionResponse tells the user when their account will be permanently deleted
+type AccountDeletionResponse struct {
+	Message              string    `json:"message"`
+	DeletionScheduledFor time.Time `json:"deletion_scheduled_for"`
+}
+
+// OIDCInfoResponse tells the frontend whether single sign-on is available
+type OIDCInfoResponse struct {
+	Enabled      bool   `json:"enabled"`
+	ProviderName string `json:"provider_name,omitempty"`
+}
+
+// MFAChallengeResponse is returned by login when the user has 2FA enabled.
+// The pre-auth token must be exchanged together with a TOTP or recovery code for a real token.
+type MFAChallengeResponse struct {
+	MFARequired  bool   `json:"mfa_required"`
+	PreAuthToken string `json:"pre_auth_token"`
+}
+
+// MFAVerifyRequest represents the second login step
+type MFAVerifyRequest struct {
+	PreAuthToken string `json:"pre_auth_token" binding:"required"`
+	Code         string `json:"code"`          // TOTP code from the authenticator app
+	RecoveryCode string `json:"recovery_code"` // One-time recovery code, used instead of Code
+}
+
+// TOTPSetupResponse contains the data needed to add the account to an authenticator app
+type TOTPSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TOTPCodeRequest represents a request carrying a TOTP code
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TOTPDisableRequest represents a request to disable 2FA
+type TOTPDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// RecoveryCodesResponse contains freshly generated recovery codes. They are only shown once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
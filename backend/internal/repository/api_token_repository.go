@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APITokenRepository defines the interface for personal access token data operations
+type APITokenRepository interface {
+	Create(scopedDB *gorm.DB, token *models.APIToken) error
+	List(scopedDB *gorm.DB) ([]*models.APIToken, error)
+	Delete(scopedDB *gorm.DB, id string) error
+	GetByHash(tokenHash string) (*models.APIToken, error)
+	TouchLastUsed(id string, usedAt time.Time) error
+	DeleteForUser(userID string) (int64, error)
+}
+
+// apiTokenRepository implements APITokenRepository
+type apiTokenRepository struct {
+	db *gorm.DB // Only used for token lookup during authentication, before a scoped DB exists
+}
+
+// NewAPITokenRepository creates a new API token repository
+func NewAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &apiTokenRepository{db: db}
+}
+
+// Create creates a new API token
+func (r *apiTokenRepository) Create(scopedDB *gorm.DB, token *models.APIToken) error {
+	if token.ID == "" {
+		token.ID = uuid.New().String()
+	}
+	token.CreatedAt = time.Now()
+
+	return scopedDB.Session(&gorm.Session{}).Create(token).Error
+}
+
+// List retrieves all API tokens
+func (r *apiTokenRepository) List(scopedDB *gorm.DB) ([]*models.APIToken, error) {
+	var tokens []*models.APIToken
+	if err := scopedDB.Session(&gorm.Session{}).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Delete revokes an API token by ID
+func (r *apiTokenRepository) Delete(scopedDB *gorm.DB, id string) error {
+	result := scopedDB.Session(&gorm.Session{}).Delete(&models.APIToken{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("api token not found")
+	}
+	return nil
+}
+
+// GetByHash retrieves an API token by the hash of its plaintext value
+func (r *apiTokenRepository) GetByHash(tokenHash string) (*models.APIToken, error) {
+	var token models.APIToken
+	if err := r.db.First(&token, "token_hash = ?", tokenHash).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("api token not found")
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// TouchLastUsed records when a token was last used
+func (r *apiTokenRepository) TouchLastUsed(id string, usedAt time.Time) error {
+	return r.db.Model(&models.APIToken{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
+
+// DeleteForUser revokes every API token of a user and returns how many were revoked
+func (r *apiTokenRepository) DeleteForUser(userID string) (int64, error) {
+	result := r.db.Where("user_id = ?", userID).Delete(&models.APIToken{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AttachmentRepository defines the interface for attachment data operations
+type AttachmentRepository interface {
+	Create(scopedDB *gorm.DB, attachment *models.Attachment) error
+	Get(scopedDB *gorm.DB, id string) (*models.Attachment, error)
+	ListForBill(scopedDB *gorm.DB, billID string) ([]*models.Attachment, error)
+	ListForPayment(scopedDB *gorm.DB, paymentID string) ([]*models.Attachment, error)
+	Delete(scopedDB *gorm.DB, id string) error
+	ListOrphaned() ([]*models.Attachment, error)
+	DeleteOrphaned(id string) error
+}
+
+// attachmentRepository implements AttachmentRepository
+type attachmentRepository struct {
+	db *gorm.DB // Only used by the cleanup job that runs across all tenants
+}
+
+// NewAttachmentRepository creates a new attachment repository
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+// Create creates a new attachment
+func (r *attachmentRepository) Create(scopedDB *gorm.DB, attachment *models.Attachment) error {
+	if attachment.ID == "" {
+		attachment.ID = uuid.New().String()
+	}
+	return scopedDB.Session(&gorm.Session{}).Create(attachment).Error
+}
+
+// Get retrieves an attachment by ID
+func (r *attachmentRepository) Get(scopedDB *gorm.DB, id string) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := scopedDB.Session(&gorm.Session{}).First(&attachment, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("attachment not found")
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// ListForBill retrieves the attachments of a bill itself, oldest first. Attachments of its payments are not included.
+func (r *attachmentRepository) ListForBill(scopedDB *gorm.DB, billID string) ([]*models.Attachment, error) {
+	var attachments []*models.Attachment
+	if err := scopedDB.Session(&gorm.Session{}).Where("bill_id = ? AND payment_id IS NULL", billID).
+		Order("created_at ASC").
+		Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// ListForPayment retrieves the attachments of a payment, oldest first
+func (r *attachmentRepository) ListForPayment(scopedDB *gorm.DB, paymentID string) ([]*models.Attachment, error) {
+	var attachments []*models.Attachment
+	if err := scopedDB.Session(&gorm.Session{}).Where("payment_id = ?", paymentID).
+		Order("created_at ASC").
+		Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// Delete deletes an attachment by ID
+func (r *attachmentRepository) Delete(scopedDB *gorm.DB, id string) error {
+	result := scopedDB.Session(&gorm.Session{}).Delete(&models.Attachment{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("attachment not found")
+	}
+	return nil
+}
+
+// ListOrphaned retrieves attachments whose bill, payment or user no longer exists, across all users.
+// Bills in the trash still exist; their attachments are kept until the bill is purged.
+func (r *attachmentRepository) ListOrphaned() ([]*models.Attachment, error) {
+	var attachments []*models.Attachment
+	if err := r.db.
+		Where("bill_id NOT IN (SELECT id FROM bills)").
+		Or("payment_id IS NOT NULL AND payment_id NOT IN (SELECT id FROM payments)").
+		Or("user_id NOT IN (SELECT id FROM users)").
+		Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// DeleteOrphaned deletes an orphaned attachment once its stored file has been removed
+func (r *attachmentRepository) DeleteOrphaned(id string) error {
+	return r.db.Delete(&models.Attachment{}, "id = ?", id).Error
+}
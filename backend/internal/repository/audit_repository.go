@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditFilter narrows an audit event listing
+type AuditFilter struct {
+	UserID string    // Admin view only; ignored for scoped listings
+	Action string    // Exact action match
+	Before time.Time // Only events created strictly before this time
+	Limit  int
+}
+
+// AuditRepository defines the interface for audit event data operations.
+// Events are append-only: there is no update, and deletion only happens through retention.
+type AuditRepository interface {
+	Create(event *models.AuditEvent) error
+	List(scopedDB *gorm.DB, filter AuditFilter) ([]*models.AuditEvent, error)
+	ListAll(filter AuditFilter) ([]*models.AuditEvent, error)
+	DeleteBefore(cutoff time.Time) (int64, error)
+}
+
+// auditRepository implements AuditRepository
+type auditRepository struct {
+	db *gorm.DB // Events are written during login and by admins, and the admin view spans all users
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// Create appends an audit event
+func (r *auditRepository) Create(event *models.AuditEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	return r.db.Create(event).Error
+}
+
+// List retrieves the current tenant's audit events, newest first
+func (r *auditRepository) List(scopedDB *gorm.DB, filter AuditFilter) ([]*models.AuditEvent, error) {
+	filter.UserID = ""
+	return r.find(scopedDB.Session(&gorm.Session{}), filter)
+}
+
+// ListAll retrieves audit events across all users, newest first
+func (r *auditRepository) ListAll(filter AuditFilter) ([]*models.AuditEvent, error) {
+	return r.find(r.db, filter)
+}
+
+// DeleteBefore removes events older than cutoff and returns how many were deleted
+func (r *auditRepository) DeleteBefore(cutoff time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", cutoff).Delete(&models.AuditEvent{})
+	return result.RowsAffected, result.Error
+}
+
+// find applies filter to query
+func (r *auditRepository) find(query *gorm.DB, filter AuditFilter) ([]*models.AuditEvent, error) {
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if !filter.Before.IsZero() {
+		query = query.Where("created_at < ?", filter.Before)
+	}
+
+	var events []*models.AuditEvent
+	if err := query.Order("created_at DESC").Limit(filter.Limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"github.com/cryptk/williams/internal/models"
+	"gorm.io/gorm"
+)
+
+// BillLoanRepository defines the interface for loan terms data operations.
+// Loan terms are removed together with their bill.
+type BillLoanRepository interface {
+	Save(scopedDB *gorm.DB, loan *models.BillLoan) error
+	Get(scopedDB *gorm.DB, billID string) (*models.BillLoan, error)
+	ListAll(scopedDB *gorm.DB) ([]*models.BillLoan, error)
+	Delete(scopedDB *gorm.DB, billID string) error
+}
+
+// billLoanRepository implements BillLoanRepository
+type billLoanRepository struct{}
+
+// NewBillLoanRepository creates a new bill loan repository
+func NewBillLoanRepository() BillLoanRepository {
+	return &billLoanRepository{}
+}
+
+// Save creates or replaces the loan terms of a bill
+func (r *billLoanRepository) Save(scopedDB *gorm.DB, loan *models.BillLoan) error {
+	existing, err := r.Get(scopedDB, loan.BillID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return scopedDB.Session(&gorm.Session{}).Create(loan).Error
+	}
+	loan.CreatedAt = existing.CreatedAt
+	return scopedDB.Session(&gorm.Session{}).Model(&models.BillLoan{}).
+		Where("bill_id = ?", loan.BillID).
+		Select("principal", "interest_rate", "term_payments", "first_payment_date", "updated_at").
+		Updates(loan).Error
+}
+
+// Get retrieves the loan terms of a bill, or nil if the bill is not a loan
+func (r *billLoanRepository) Get(scopedDB *gorm.DB, billID string) (*models.BillLoan, error) {
+	var loans []*models.BillLoan
+	if err := scopedDB.Session(&gorm.Session{}).Where("bill_id = ?", billID).Limit(1).Find(&loans).Error; err != nil {
+		return nil, err
+	}
+	if len(loans) == 0 {
+		return nil, nil
+	}
+	return loans[0], nil
+}
+
+// ListAll retrieves the loan terms of every bill
+func (r *billLoanRepository) ListAll(scopedDB *gorm.DB) ([]*models.BillLoan, error) {
+	var loans []*models.BillLoan
+	if err := scopedDB.Session(&gorm.Session{}).Find(&loans).Error; err != nil {
+		return nil, err
+	}
+	return loans, nil
+}
+
+// Delete removes the loan terms of a bill, if any
+func (r *billLoanRepository) Delete(scopedDB *gorm.DB, billID string) error {
+	return scopedDB.Session(&gorm.Session{}).Delete(&models.BillLoan{}, "bill_id = ?", billID).Error
+}
@@ -1,7 +1,8 @@
 package repository
 
 import (
-	"fmt"
+	"errors"
+	"time"
 
 	"github.com/cryptk/williams/internal/models"
 	"github.com/cryptk/williams/pkg/utils"
@@ -9,22 +10,46 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrBillNotFound is returned for a bill that does not exist or belongs to another user
+var ErrBillNotFound = errors.New("bill not found")
+
+// BillFilter narrows, orders and pages a bill listing
+type BillFilter struct {
+	ListOptions
+	CategoryID string   // Exact category match
+	TagID      string   // Bills carrying this tag
+	PayeeID    string   // Bills owed to this payee
+	AccountID  string   // Bills paid from this funding account
+	MinAmount  *float64 // Inclusive bounds on the current amount
+	MaxAmount  *float64
+	Sort       Sort    // By name, amount or created_at; defaults to name
+	Cursor     *Cursor // Continue after this bill
+	Limit      int     // Zero returns every match
+}
+
 // BillRepository defines the interface for bill data operations
 type BillRepository interface {
 	Create(scopedDB *gorm.DB, bill *models.Bill) error
 	Get(scopedDB *gorm.DB, id string) (*models.Bill, error)
-	List(scopedDB *gorm.DB) ([]*models.Bill, error)
+	List(scopedDB *gorm.DB, opts ListOptions) ([]*models.Bill, error)
+	Search(scopedDB *gorm.DB, filter BillFilter) ([]*models.Bill, int64, error)
 	Update(scopedDB *gorm.DB, bill *models.Bill) error
 	Delete(scopedDB *gorm.DB, id string) error
-	GetStats(scopedDB *gorm.DB) (*models.BillStats, error)
+	SetArchived(scopedDB *gorm.DB, id string, archivedAt *time.Time) error
+	ListDeleted(scopedDB *gorm.DB) ([]*models.Bill, error)
+	Restore(scopedDB *gorm.DB, id string) error
+	PurgeDeleted(cutoff time.Time) (int64, error)
+	ListAutopayUserIDs() ([]string, error)
 }
 
 // billRepository implements BillRepository
-type billRepository struct{}
+type billRepository struct {
+	db *gorm.DB // Only used by background jobs that run across all tenants
+}
 
 // NewBillRepository creates a new bill repository
-func NewBillRepository() BillRepository {
-	return &billRepository{}
+func NewBillRepository(db *gorm.DB) BillRepository {
+	return &billRepository{db: db}
 }
 
 // Create creates a new bill
@@ -43,79 +68,140 @@ func (r *billRepository) Get(scopedDB *gorm.DB, id string) (*models.Bill, error)
 	var bill models.Bill
 	if err := scopedDB.Session(&gorm.Session{}).First(&bill, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("bill not found")
+			return nil, ErrBillNotFound
 		}
 		return nil, err
 	}
 	return &bill, nil
 }
 
-// List retrieves all bills
-func (r *billRepository) List(scopedDB *gorm.DB) ([]*models.Bill, error) {
+// List retrieves all bills that are not in the trash
+func (r *billRepository) List(scopedDB *gorm.DB, opts ListOptions) ([]*models.Bill, error) {
 	var bills []*models.Bill
-	if err := scopedDB.Session(&gorm.Session{}).Order("name ASC").Find(&bills).Error; err != nil {
+	if err := applyListOptions(scopedDB.Session(&gorm.Session{}), opts).Order("name ASC").Find(&bills).Error; err != nil {
 		return nil, err
 	}
 	return bills, nil
 }
 
+// Search retrieves the bills matching filter that are not in the trash, along with how many match
+// regardless of the cursor and limit
+func (r *billRepository) Search(scopedDB *gorm.DB, filter BillFilter) ([]*models.Bill, int64, error) {
+	query := filterBills(scopedDB, filter)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	column := filter.Sort.Field
+	if column == "" {
+		column = "name"
+	}
+	var value any
+	if filter.Cursor != nil {
+		var err error
+		if value, err = cursorValue(filter.Cursor, column, "created_at"); err != nil {
+			return nil, 0, err
+		}
+	}
+	query = applySort(query, column, filter.Sort.Desc, filter.Cursor, value)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var bills []*models.Bill
+	if err := query.Find(&bills).Error; err != nil {
+		return nil, 0, err
+	}
+	return bills, total, nil
+}
+
+// filterBills returns a query of the bills matching the stored-field filters, ignoring the sort,
+// cursor and limit
+func filterBills(scopedDB *gorm.DB, filter BillFilter) *gorm.DB {
+	query := applyListOptions(scopedDB.Session(&gorm.Session{}), filter.ListOptions).Model(&models.Bill{})
+	if filter.CategoryID != "" {
+		query = query.Where("category_id = ?", filter.CategoryID)
+	}
+	if filter.TagID != "" {
+		query = query.Where("id IN (?)", scopedDB.Session(&gorm.Session{}).Model(&models.BillTag{}).Select("bill_id").Where("tag_id = ?", filter.TagID))
+	}
+	if filter.PayeeID != "" {
+		query = query.Where("payee_id = ?", filter.PayeeID)
+	}
+	if filter.AccountID != "" {
+		query = query.Where("account_id = ?", filter.AccountID)
+	}
+	if filter.MinAmount != nil {
+		query = query.Where("amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("amount <= ?", *filter.MaxAmount)
+	}
+	return query
+}
+
 // Update updates an existing bill
 func (r *billRepository) Update(scopedDB *gorm.DB, bill *models.Bill) error {
 	// Fetch the existing bill to preserve CreatedAt
 	var existing models.Bill
 	if err := scopedDB.Session(&gorm.Session{}).First(&existing, "id = ?", bill.ID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return fmt.Errorf("bill not found")
+			return ErrBillNotFound
 		}
 		return err
 	}
 
-	// Preserve CreatedAt and UserID from existing record, set UpdatedAt to now
+	// Preserve CreatedAt, UserID and archive state from existing record, set UpdatedAt to now
 	bill.CreatedAt = existing.CreatedAt
 	bill.UserID = existing.UserID
+	bill.ArchivedAt = existing.ArchivedAt
+	bill.DeletedAt = existing.DeletedAt
 	bill.UpdatedAt = utils.NowInAppTimezone()
 
 	return scopedDB.Session(&gorm.Session{}).Save(bill).Error
 }
 
-// Delete deletes a bill by ID
+// Delete moves a bill to the trash. Its payments are kept until the bill is purged.
 func (r *billRepository) Delete(scopedDB *gorm.DB, id string) error {
 	result := scopedDB.Session(&gorm.Session{}).Delete(&models.Bill{}, "id = ?", id)
 	if result.Error != nil {
 		return result.Error
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("bill not found")
+		return ErrBillNotFound
 	}
 	return nil
 }
 
-// GetStats calculates bill statistics
-func (r *billRepository) GetStats(scopedDB *gorm.DB) (*models.BillStats, error) {
-	var stats models.BillStats
+// SetArchived archives a bill, or unarchives it when archivedAt is nil
+func (r *billRepository) SetArchived(scopedDB *gorm.DB, id string, archivedAt *time.Time) error {
+	return setArchived(scopedDB, &models.Bill{}, "bill", id, archivedAt)
+}
 
-	// Total bills count
-	var totalCount int64
-	if err := scopedDB.Session(&gorm.Session{}).Model(&models.Bill{}).Count(&totalCount).Error; err != nil {
-		return nil, err
-	}
-	stats.TotalBills = int(totalCount)
+// ListDeleted retrieves the bills in the trash
+func (r *billRepository) ListDeleted(scopedDB *gorm.DB) ([]*models.Bill, error) {
+	return listDeleted[models.Bill](scopedDB)
+}
 
-	// Total amount - use Session to get fresh query builder without inherited clauses
-	type Result struct {
-		Total float64
-	}
-	var result Result
-	if err := scopedDB.Session(&gorm.Session{}).Model(&models.Bill{}).Select("COALESCE(SUM(amount), 0) as total").Scan(&result).Error; err != nil {
-		return nil, err
-	}
-	stats.TotalAmount = result.Total
+// Restore moves a bill out of the trash
+func (r *billRepository) Restore(scopedDB *gorm.DB, id string) error {
+	return restoreDeleted(scopedDB, &models.Bill{}, "bill", id)
+}
 
-	// Note: Paid/Unpaid/Upcoming stats are now calculated in the service layer
-	// since is_paid is a computed field based on payments and grace period
-	stats.PaidBills = 0
-	stats.UnpaidBills = 0
-	stats.UpcomingBills = 0
+// PurgeDeleted permanently deletes bills that were moved to the trash before cutoff, along with their payments
+func (r *billRepository) PurgeDeleted(cutoff time.Time) (int64, error) {
+	return purgeDeleted(r.db, &models.Bill{}, cutoff)
+}
 
-	return &stats, nil
+// ListAutopayUserIDs retrieves the users that have active autopay bills
+func (r *billRepository) ListAutopayUserIDs() ([]string, error) {
+	var userIDs []string
+	if err := r.db.Model(&models.Bill{}).
+		Where("autopay = ? AND archived_at IS NULL", true).
+		Distinct().Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+	return userIDs, nil
 }
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BillScheduleRepository defines the interface for bill pause and skip data operations
+type BillScheduleRepository interface {
+	CreatePause(scopedDB *gorm.DB, pause *models.BillPause) error
+	ListPauses(scopedDB *gorm.DB, billID string) ([]*models.BillPause, error)
+	ListAllPauses(scopedDB *gorm.DB) ([]*models.BillPause, error)
+	DeletePause(scopedDB *gorm.DB, billID, id string) (*models.BillPause, error)
+	CreateSkip(scopedDB *gorm.DB, skip *models.BillSkip) error
+	ListSkips(scopedDB *gorm.DB, billID string) ([]*models.BillSkip, error)
+	ListAllSkips(scopedDB *gorm.DB) ([]*models.BillSkip, error)
+	DeleteSkip(scopedDB *gorm.DB, billID, id string) (*models.BillSkip, error)
+}
+
+// billScheduleRepository implements BillScheduleRepository
+type billScheduleRepository struct{}
+
+// NewBillScheduleRepository creates a new bill schedule repository
+func NewBillScheduleRepository() BillScheduleRepository {
+	return &billScheduleRepository{}
+}
+
+// CreatePause creates a new bill pause
+func (r *billScheduleRepository) CreatePause(scopedDB *gorm.DB, pause *models.BillPause) error {
+	if pause.ID == "" {
+		pause.ID = uuid.New().String()
+	}
+	return scopedDB.Session(&gorm.Session{}).Create(pause).Error
+}
+
+// ListPauses retrieves the pauses of a bill, oldest first
+func (r *billScheduleRepository) ListPauses(scopedDB *gorm.DB, billID string) ([]*models.BillPause, error) {
+	var pauses []*models.BillPause
+	if err := scopedDB.Session(&gorm.Session{}).Where("bill_id = ?", billID).
+		Order("start_date ASC").
+		Find(&pauses).Error; err != nil {
+		return nil, err
+	}
+	return pauses, nil
+}
+
+// ListAllPauses retrieves the pauses of every bill, grouped by bill and oldest first
+func (r *billScheduleRepository) ListAllPauses(scopedDB *gorm.DB) ([]*models.BillPause, error) {
+	var pauses []*models.BillPause
+	if err := scopedDB.Session(&gorm.Session{}).
+		Order("bill_id ASC, start_date ASC").
+		Find(&pauses).Error; err != nil {
+		return nil, err
+	}
+	return pauses, nil
+}
+
+// DeletePause deletes a pause of a bill and returns it
+func (r *billScheduleRepository) DeletePause(scopedDB *gorm.DB, billID, id string) (*models.BillPause, error) {
+	var pause models.BillPause
+	if err := scopedDB.Session(&gorm.Session{}).First(&pause, "id = ? AND bill_id = ?", id, billID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("pause not found")
+		}
+		return nil, err
+	}
+	if err := scopedDB.Session(&gorm.Session{}).Delete(&models.BillPause{}, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &pause, nil
+}
+
+// CreateSkip creates a new bill skip
+func (r *billScheduleRepository) CreateSkip(scopedDB *gorm.DB, skip *models.BillSkip) error {
+	if skip.ID == "" {
+		skip.ID = uuid.New().String()
+	}
+	return scopedDB.Session(&gorm.Session{}).Create(skip).Error
+}
+
+// ListSkips retrieves the skipped occurrences of a bill, oldest first
+func (r *billScheduleRepository) ListSkips(scopedDB *gorm.DB, billID string) ([]*models.BillSkip, error) {
+	var skips []*models.BillSkip
+	if err := scopedDB.Session(&gorm.Session{}).Where("bill_id = ?", billID).
+		Order("due_date ASC").
+		Find(&skips).Error; err != nil {
+		return nil, err
+	}
+	return skips, nil
+}
+
+// ListAllSkips retrieves the skipped occurrences of every bill, grouped by bill and oldest first
+func (r *billScheduleRepository) ListAllSkips(scopedDB *gorm.DB) ([]*models.BillSkip, error) {
+	var skips []*models.BillSkip
+	if err := scopedDB.Session(&gorm.Session{}).
+		Order("bill_id ASC, due_date ASC").
+		Find(&skips).Error; err != nil {
+		return nil, err
+	}
+	return skips, nil
+}
+
+// DeleteSkip deletes a skipped occurrence of a bill and returns it
+func (r *billScheduleRepository) DeleteSkip(scopedDB *gorm.DB, billID, id string) (*models.BillSkip, error) {
+	var skip models.BillSkip
+	if err := scopedDB.Session(&gorm.Session{}).First(&skip, "id = ? AND bill_id = ?", id, billID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("skip not found")
+		}
+		return nil, err
+	}
+	if err := scopedDB.Session(&gorm.Session{}).Delete(&models.BillSkip{}, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &skip, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BillStatementRepository defines the interface for bill statement data operations
+type BillStatementRepository interface {
+	Create(scopedDB *gorm.DB, statement *models.BillStatement) error
+	Update(scopedDB *gorm.DB, statement *models.BillStatement) error
+	List(scopedDB *gorm.DB, billID string) ([]*models.BillStatement, error)
+	ListAll(scopedDB *gorm.DB) ([]*models.BillStatement, error)
+	Delete(scopedDB *gorm.DB, billID, id string) (*models.BillStatement, error)
+}
+
+// billStatementRepository implements BillStatementRepository
+type billStatementRepository struct{}
+
+// NewBillStatementRepository creates a new bill statement repository
+func NewBillStatementRepository() BillStatementRepository {
+	return &billStatementRepository{}
+}
+
+// Create creates a new bill statement
+func (r *billStatementRepository) Create(scopedDB *gorm.DB, statement *models.BillStatement) error {
+	if statement.ID == "" {
+		statement.ID = uuid.New().String()
+	}
+	return scopedDB.Session(&gorm.Session{}).Create(statement).Error
+}
+
+// Update replaces the amounts and notes of an existing bill statement
+func (r *billStatementRepository) Update(scopedDB *gorm.DB, statement *models.BillStatement) error {
+	result := scopedDB.Session(&gorm.Session{}).Model(&models.BillStatement{}).
+		Where("id = ? AND bill_id = ?", statement.ID, statement.BillID).
+		Select("due_date", "amount", "minimum_payment", "notes", "updated_at").
+		Updates(statement)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("statement not found")
+	}
+	return nil
+}
+
+// List retrieves the statements of a bill, oldest first
+func (r *billStatementRepository) List(scopedDB *gorm.DB, billID string) ([]*models.BillStatement, error) {
+	var statements []*models.BillStatement
+	if err := scopedDB.Session(&gorm.Session{}).Where("bill_id = ?", billID).
+		Order("due_date ASC").
+		Find(&statements).Error; err != nil {
+		return nil, err
+	}
+	return statements, nil
+}
+
+// ListAll retrieves the statements of every bill, grouped by bill and oldest first
+func (r *billStatementRepository) ListAll(scopedDB *gorm.DB) ([]*models.BillStatement, error) {
+	var statements []*models.BillStatement
+	if err := scopedDB.Session(&gorm.Session{}).
+		Order("bill_id ASC, due_date ASC").
+		Find(&statements).Error; err != nil {
+		return nil, err
+	}
+	return statements, nil
+}
+
+// Delete deletes a statement of a bill and returns it
+func (r *billStatementRepository) Delete(scopedDB *gorm.DB, billID, id string) (*models.BillStatement, error) {
+	var statement models.BillStatement
+	if err := scopedDB.Session(&gorm.Session{}).First(&statement, "id = ? AND bill_id = ?", id, billID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("statement not found")
+		}
+		return nil, err
+	}
+	if err := scopedDB.Session(&gorm.Session{}).Delete(&models.BillStatement{}, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"github.com/cryptk/williams/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BillVersionRepository defines the interface for bill history data operations.
+// Versions are never updated; they are removed together with their bill.
+type BillVersionRepository interface {
+	Create(scopedDB *gorm.DB, version *models.BillVersion) error
+	List(scopedDB *gorm.DB, billID string) ([]*models.BillVersion, error)
+	ListAll(scopedDB *gorm.DB) ([]*models.BillVersion, error)
+}
+
+// billVersionRepository implements BillVersionRepository
+type billVersionRepository struct{}
+
+// NewBillVersionRepository creates a new bill version repository
+func NewBillVersionRepository() BillVersionRepository {
+	return &billVersionRepository{}
+}
+
+// Create creates a new bill version
+func (r *billVersionRepository) Create(scopedDB *gorm.DB, version *models.BillVersion) error {
+	if version.ID == "" {
+		version.ID = uuid.New().String()
+	}
+	return scopedDB.Session(&gorm.Session{}).Create(version).Error
+}
+
+// List retrieves the versions of a bill, oldest first
+func (r *billVersionRepository) List(scopedDB *gorm.DB, billID string) ([]*models.BillVersion, error) {
+	var versions []*models.BillVersion
+	if err := scopedDB.Session(&gorm.Session{}).Where("bill_id = ?", billID).
+		Order("effective_from ASC, created_at ASC").
+		Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// ListAll retrieves the versions of every bill, grouped by bill and oldest first
+func (r *billVersionRepository) ListAll(scopedDB *gorm.DB) ([]*models.BillVersion, error) {
+	var versions []*models.BillVersion
+	if err := scopedDB.Session(&gorm.Session{}).
+		Order("bill_id ASC, effective_from ASC, created_at ASC").
+		Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
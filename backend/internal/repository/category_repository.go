@@ -1,24 +1,32 @@
 package repository
 
 import (
-	"fmt"
+	"errors"
+	"time"
 
 	"github.com/cryptk/williams/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// ErrCategoryNotFound is returned for a category that does not exist or belongs to another user
+var ErrCategoryNotFound = errors.New("category not found")
+
 // CategoryRepository defines the interface for category data operations
 type CategoryRepository interface {
 	Create(scopedDB *gorm.DB, category *models.Category) error
-	List(scopedDB *gorm.DB) ([]*models.Category, error)
+	List(scopedDB *gorm.DB, opts ListOptions) ([]*models.Category, error)
 	Delete(scopedDB *gorm.DB, id string) error
 	CreateDefaults(userID string) error
+	SetArchived(scopedDB *gorm.DB, id string, archivedAt *time.Time) error
+	ListDeleted(scopedDB *gorm.DB) ([]*models.Category, error)
+	Restore(scopedDB *gorm.DB, id string) error
+	PurgeDeleted(cutoff time.Time) (int64, error)
 }
 
 // categoryRepository implements CategoryRepository
 type categoryRepository struct {
-	db *gorm.DB // Only used for CreateDefaults (unauthenticated user registration)
+	db *gorm.DB // Only used for CreateDefaults (unauthenticated user registration) and PurgeDeleted (background job)
 }
 
 // NewCategoryRepository creates a new category repository
@@ -34,23 +42,23 @@ func (r *categoryRepository) Create(scopedDB *gorm.DB, category *models.Category
 	return scopedDB.Session(&gorm.Session{}).Create(category).Error
 }
 
-// List retrieves all categories
-func (r *categoryRepository) List(scopedDB *gorm.DB) ([]*models.Category, error) {
+// List retrieves all categories that are not in the trash
+func (r *categoryRepository) List(scopedDB *gorm.DB, opts ListOptions) ([]*models.Category, error) {
 	var categories []*models.Category
-	if err := scopedDB.Session(&gorm.Session{}).Order("name ASC").Find(&categories).Error; err != nil {
+	if err := applyListOptions(scopedDB.Session(&gorm.Session{}), opts).Order("name ASC").Find(&categories).Error; err != nil {
 		return nil, err
 	}
 	return categories, nil
 }
 
-// Delete deletes a category by ID
+// Delete moves a category to the trash. Bills keep their category_id until it is purged.
 func (r *categoryRepository) Delete(scopedDB *gorm.DB, id string) error {
 	result := scopedDB.Session(&gorm.Session{}).Delete(&models.Category{}, "id = ?", id)
 	if result.Error != nil {
 		return result.Error
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("category not found")
+		return ErrCategoryNotFound
 	}
 	return nil
 }
@@ -73,3 +81,24 @@ func (r *categoryRepository) CreateDefaults(userID string) error {
 
 	return nil
 }
+
+// SetArchived archives a category, or unarchives it when archivedAt is nil
+func (r *categoryRepository) SetArchived(scopedDB *gorm.DB, id string, archivedAt *time.Time) error {
+	return setArchived(scopedDB, &models.Category{}, "category", id, archivedAt)
+}
+
+// ListDeleted retrieves the categories in the trash
+func (r *categoryRepository) ListDeleted(scopedDB *gorm.DB) ([]*models.Category, error) {
+	return listDeleted[models.Category](scopedDB)
+}
+
+// Restore moves a category out of the trash
+func (r *categoryRepository) Restore(scopedDB *gorm.DB, id string) error {
+	return restoreDeleted(scopedDB, &models.Category{}, "category", id)
+}
+
+// PurgeDeleted permanently deletes categories that were moved to the trash before cutoff.
+// Bills that used them fall back to no category.
+func (r *categoryRepository) PurgeDeleted(cutoff time.Time) (int64, error) {
+	return purgeDeleted(r.db, &models.Category{}, cutoff)
+}
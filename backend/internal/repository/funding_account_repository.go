@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FundingAccountRepository defines the interface for funding account data operations
+type FundingAccountRepository interface {
+	Create(scopedDB *gorm.DB, account *models.FundingAccount) error
+	Get(scopedDB *gorm.DB, id string) (*models.FundingAccount, error)
+	List(scopedDB *gorm.DB) ([]*models.FundingAccount, error)
+	Update(scopedDB *gorm.DB, account *models.FundingAccount) error
+	Delete(scopedDB *gorm.DB, id string) error
+}
+
+// fundingAccountRepository implements FundingAccountRepository
+type fundingAccountRepository struct{}
+
+// NewFundingAccountRepository creates a new funding account repository
+func NewFundingAccountRepository() FundingAccountRepository {
+	return &fundingAccountRepository{}
+}
+
+// Create creates a new funding account
+func (r *fundingAccountRepository) Create(scopedDB *gorm.DB, account *models.FundingAccount) error {
+	if account.ID == "" {
+		account.ID = uuid.New().String()
+	}
+	account.CreatedAt = utils.NowInAppTimezone()
+	account.UpdatedAt = utils.NowInAppTimezone()
+
+	return scopedDB.Session(&gorm.Session{}).Create(account).Error
+}
+
+// Get retrieves a funding account by ID
+func (r *fundingAccountRepository) Get(scopedDB *gorm.DB, id string) (*models.FundingAccount, error) {
+	var account models.FundingAccount
+	if err := scopedDB.Session(&gorm.Session{}).First(&account, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("account not found")
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// List retrieves all funding accounts ordered by name
+func (r *fundingAccountRepository) List(scopedDB *gorm.DB) ([]*models.FundingAccount, error) {
+	var accounts []*models.FundingAccount
+	if err := scopedDB.Session(&gorm.Session{}).Order("name ASC").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// Update updates an existing funding account
+func (r *fundingAccountRepository) Update(scopedDB *gorm.DB, account *models.FundingAccount) error {
+	account.UpdatedAt = utils.NowInAppTimezone()
+
+	result := scopedDB.Session(&gorm.Session{}).Model(&models.FundingAccount{}).Where("id = ?", account.ID).
+		Updates(map[string]any{
+			"name":                  account.Name,
+			"type":                  account.Type,
+			"current_balance":       account.CurrentBalance,
+			"balance_updated_at":    account.BalanceUpdatedAt,
+			"low_balance_threshold": account.LowBalanceThreshold,
+			"notes":                 account.Notes,
+			"updated_at":            account.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("account not found")
+	}
+	return nil
+}
+
+// Delete deletes a funding account. Bills, including those in the trash, and payments that used it
+// are kept without an account.
+func (r *fundingAccountRepository) Delete(scopedDB *gorm.DB, id string) error {
+	return scopedDB.Session(&gorm.Session{}).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Bill{}).Where("account_id = ?", id).
+			UpdateColumn("account_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Payment{}).Where("account_id = ?", id).
+			UpdateColumn("account_id", nil).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.FundingAccount{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("account not found")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// HolidayRepository defines the interface for user-defined holiday data operations
+type HolidayRepository interface {
+	Create(scopedDB *gorm.DB, holiday *models.UserHoliday) error
+	List(scopedDB *gorm.DB) ([]*models.UserHoliday, error)
+	Delete(scopedDB *gorm.DB, id string) error
+}
+
+// holidayRepository implements HolidayRepository
+type holidayRepository struct{}
+
+// NewHolidayRepository creates a new holiday repository
+func NewHolidayRepository() HolidayRepository {
+	return &holidayRepository{}
+}
+
+// Create creates a new user holiday
+func (r *holidayRepository) Create(scopedDB *gorm.DB, holiday *models.UserHoliday) error {
+	if holiday.ID == "" {
+		holiday.ID = uuid.New().String()
+	}
+	return scopedDB.Session(&gorm.Session{}).Create(holiday).Error
+}
+
+// List retrieves the user's holidays in date order
+func (r *holidayRepository) List(scopedDB *gorm.DB) ([]*models.UserHoliday, error) {
+	var holidays []*models.UserHoliday
+	if err := scopedDB.Session(&gorm.Session{}).Order("date ASC").Find(&holidays).Error; err != nil {
+		return nil, err
+	}
+	return holidays, nil
+}
+
+// Delete deletes a user holiday by ID
+func (r *holidayRepository) Delete(scopedDB *gorm.DB, id string) error {
+	result := scopedDB.Session(&gorm.Session{}).Delete(&models.UserHoliday{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("holiday not found")
+	}
+	return nil
+}
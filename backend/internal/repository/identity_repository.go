@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IdentityRepository defines the interface for external identity link data operations
+type IdentityRepository interface {
+	Create(identity *models.UserIdentity) error
+	GetBySubject(issuer, subject string) (*models.UserIdentity, error)
+	TouchLastLogin(id, email string) error
+}
+
+// identityRepository implements IdentityRepository
+type identityRepository struct {
+	db *gorm.DB // Identities are resolved during SSO login, before a scoped DB exists
+}
+
+// NewIdentityRepository creates a new identity repository
+func NewIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+// Create links a local user to an external identity
+func (r *identityRepository) Create(identity *models.UserIdentity) error {
+	if identity.ID == "" {
+		identity.ID = uuid.New().String()
+	}
+	now := time.Now()
+	identity.CreatedAt = now
+	identity.LastLoginAt = &now
+
+	return r.db.Create(identity).Error
+}
+
+// GetBySubject retrieves an identity by issuer and subject
+func (r *identityRepository) GetBySubject(issuer, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	if err := r.db.First(&identity, "issuer = ? AND subject = ?", issuer, subject).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("identity not found")
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// TouchLastLogin records a successful login through an identity and refreshes the email seen at the provider
+func (r *identityRepository) TouchLastLogin(id, email string) error {
+	return r.db.Model(&models.UserIdentity{}).Where("id = ?", id).Updates(map[string]any{
+		"last_login_at": time.Now(),
+		"email":         email,
+	}).Error
+}
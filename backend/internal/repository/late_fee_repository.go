@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LateFeeRepository defines the interface for late fee rule and waiver data operations.
+// Rules and waivers are removed together with their bill.
+type LateFeeRepository interface {
+	SaveRule(scopedDB *gorm.DB, rule *models.BillLateFeeRule) error
+	GetRule(scopedDB *gorm.DB, billID string) (*models.BillLateFeeRule, error)
+	ListAllRules(scopedDB *gorm.DB) ([]*models.BillLateFeeRule, error)
+	DeleteRule(scopedDB *gorm.DB, billID string) error
+	CreateWaiver(scopedDB *gorm.DB, waiver *models.BillLateFeeWaiver) error
+	ListWaivers(scopedDB *gorm.DB, billID string) ([]*models.BillLateFeeWaiver, error)
+	ListAllWaivers(scopedDB *gorm.DB) ([]*models.BillLateFeeWaiver, error)
+	DeleteWaiver(scopedDB *gorm.DB, billID, id string) (*models.BillLateFeeWaiver, error)
+}
+
+// lateFeeRepository implements LateFeeRepository
+type lateFeeRepository struct{}
+
+// NewLateFeeRepository creates a new late fee repository
+func NewLateFeeRepository() LateFeeRepository {
+	return &lateFeeRepository{}
+}
+
+// SaveRule creates or replaces the late fee rule of a bill
+func (r *lateFeeRepository) SaveRule(scopedDB *gorm.DB, rule *models.BillLateFeeRule) error {
+	existing, err := r.GetRule(scopedDB, rule.BillID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return scopedDB.Session(&gorm.Session{}).Create(rule).Error
+	}
+	rule.CreatedAt = existing.CreatedAt
+	return scopedDB.Session(&gorm.Session{}).Model(&models.BillLateFeeRule{}).
+		Where("bill_id = ?", rule.BillID).
+		Select("flat_fee", "percentage", "daily_fee", "grace_days", "max_fee", "updated_at").
+		Updates(rule).Error
+}
+
+// GetRule retrieves the late fee rule of a bill, or nil if it has none
+func (r *lateFeeRepository) GetRule(scopedDB *gorm.DB, billID string) (*models.BillLateFeeRule, error) {
+	var rules []*models.BillLateFeeRule
+	if err := scopedDB.Session(&gorm.Session{}).Where("bill_id = ?", billID).Limit(1).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return rules[0], nil
+}
+
+// ListAllRules retrieves the late fee rules of every bill
+func (r *lateFeeRepository) ListAllRules(scopedDB *gorm.DB) ([]*models.BillLateFeeRule, error) {
+	var rules []*models.BillLateFeeRule
+	if err := scopedDB.Session(&gorm.Session{}).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// DeleteRule removes the late fee rule of a bill, if any
+func (r *lateFeeRepository) DeleteRule(scopedDB *gorm.DB, billID string) error {
+	return scopedDB.Session(&gorm.Session{}).Delete(&models.BillLateFeeRule{}, "bill_id = ?", billID).Error
+}
+
+// CreateWaiver creates a new late fee waiver
+func (r *lateFeeRepository) CreateWaiver(scopedDB *gorm.DB, waiver *models.BillLateFeeWaiver) error {
+	if waiver.ID == "" {
+		waiver.ID = uuid.New().String()
+	}
+	return scopedDB.Session(&gorm.Session{}).Create(waiver).Error
+}
+
+// ListWaivers retrieves the late fee waivers of a bill, oldest first
+func (r *lateFeeRepository) ListWaivers(scopedDB *gorm.DB, billID string) ([]*models.BillLateFeeWaiver, error) {
+	var waivers []*models.BillLateFeeWaiver
+	if err := scopedDB.Session(&gorm.Session{}).Where("bill_id = ?", billID).
+		Order("due_date ASC").
+		Find(&waivers).Error; err != nil {
+		return nil, err
+	}
+	return waivers, nil
+}
+
+// ListAllWaivers retrieves the late fee waivers of every bill, grouped by bill and oldest first
+func (r *lateFeeRepository) ListAllWaivers(scopedDB *gorm.DB) ([]*models.BillLateFeeWaiver, error) {
+	var waivers []*models.BillLateFeeWaiver
+	if err := scopedDB.Session(&gorm.Session{}).
+		Order("bill_id ASC, due_date ASC").
+		Find(&waivers).Error; err != nil {
+		return nil, err
+	}
+	return waivers, nil
+}
+
+// DeleteWaiver deletes a late fee waiver of a bill and returns it
+func (r *lateFeeRepository) DeleteWaiver(scopedDB *gorm.DB, billID, id string) (*models.BillLateFeeWaiver, error) {
+	var waiver models.BillLateFeeWaiver
+	if err := scopedDB.Session(&gorm.Session{}).First(&waiver, "id = ? AND bill_id = ?", id, billID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("waiver not found")
+		}
+		return nil, err
+	}
+	if err := scopedDB.Session(&gorm.Session{}).Delete(&models.BillLateFeeWaiver{}, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &waiver, nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Reference describes a foreign key on a record that must point at a row owned by the same tenant.
+// Foreign key constraints only prove the row exists, not who it belongs to.
+type Reference struct {
+	Field  string  // JSON field name, used in error messages
+	Model  any     // Pointer to the referenced model, e.g. &models.Category{}
+	ID     *string // Referenced ID; nil means the reference is unset
+	Stored *string // ID the record already references; it may be kept after that row is moved to the trash
+}
+
+// ReferenceError is returned when a referenced record is not visible to the current tenant
+type ReferenceError struct {
+	Field string
+	ID    string
+}
+
+// Error implements error
+func (e *ReferenceError) Error() string {
+	return fmt.Sprintf("%s %s not found", e.Field, e.ID)
+}
+
+// VerifyOwnership checks that every set reference resolves through the tenant-scoped DB.
+// Another tenant's record is reported the same way as a missing one so IDs cannot be probed. A reference
+// to a row in the trash is only accepted if it is unchanged from the stored one.
+func VerifyOwnership(scopedDB *gorm.DB, refs ...Reference) error {
+	for _, ref := range refs {
+		if ref.ID == nil {
+			continue
+		}
+
+		query := scopedDB.Session(&gorm.Session{}).Model(ref.Model)
+		if ref.Stored != nil && *ref.Stored == *ref.ID {
+			query = query.Unscoped()
+		}
+		var count int64
+		if err := query.Where("id = ?", *ref.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return &ReferenceError{Field: ref.Field, ID: *ref.ID}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor is returned for a cursor that was not issued for the listing
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Sort orders a listing by a single field, with the record ID breaking ties
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort reads a sort parameter such as "amount" or "-amount" (descending). An empty parameter
+// returns def.
+func ParseSort(param string, def Sort, fields ...string) (Sort, error) {
+	if param == "" {
+		return def, nil
+	}
+	sort := Sort{Field: strings.TrimPrefix(param, "-"), Desc: strings.HasPrefix(param, "-")}
+	for _, field := range fields {
+		if sort.Field == field {
+			return sort, nil
+		}
+	}
+	return sort, fmt.Errorf("sort must be one of %s, prefixed with - for descending order", strings.Join(fields, ", "))
+}
+
+// Cursor is the position after the last record of a page: the value the listing is sorted by and the
+// record's ID. Clients receive it as an opaque string.
+type Cursor struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// NewCursor returns the cursor following a record
+func NewCursor(value any, id string) *Cursor {
+	cursor := &Cursor{ID: id}
+	switch v := value.(type) {
+	case string:
+		cursor.Value = v
+	case float64:
+		cursor.Value = strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		cursor.Value = v.Format(time.RFC3339Nano)
+	case *time.Time:
+		if v != nil {
+			cursor.Value = v.Format(time.RFC3339Nano)
+		}
+	}
+	return cursor
+}
+
+// Encode returns the opaque form of the cursor
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Float returns the cursor value of a listing sorted by an amount
+func (c *Cursor) Float() (float64, error) {
+	return strconv.ParseFloat(c.Value, 64)
+}
+
+// Time returns the cursor value of a listing sorted by a date. Records without a date have an empty
+// value and a nil time.
+func (c *Cursor) Time() (*time.Time, error) {
+	if c.Value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, c.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// DecodeCursor parses a cursor returned with a previous page
+func DecodeCursor(encoded string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// cursorValue converts the cursor value to the type of a sort column so it compares correctly in SQL
+func cursorValue(cursor *Cursor, column string, timeColumns ...string) (any, error) {
+	for _, timeColumn := range timeColumns {
+		if column == timeColumn {
+			t, err := cursor.Time()
+			if err != nil || t == nil {
+				return nil, ErrInvalidCursor
+			}
+			return *t, nil
+		}
+	}
+	if column == "amount" {
+		v, err := cursor.Float()
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return v, nil
+	}
+	return cursor.Value, nil
+}
+
+// applySort orders query by column and then ID, continuing after cursor if one is given. column must
+// come from a fixed list of sortable columns, never from user input.
+func applySort(query *gorm.DB, column string, desc bool, cursor *Cursor, value any) *gorm.DB {
+	direction, comparison := "ASC", ">"
+	if desc {
+		direction, comparison = "DESC", "<"
+	}
+	if cursor != nil {
+		query = query.Where(
+			fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", column, comparison, column, comparison),
+			value, value, cursor.ID,
+		)
+	}
+	return query.Order(fmt.Sprintf("%s %s, id %s", column, direction, direction))
+}
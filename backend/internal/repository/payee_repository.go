@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PayeeRepository defines the interface for payee data operations
+type PayeeRepository interface {
+	Create(scopedDB *gorm.DB, payee *models.Payee) error
+	Get(scopedDB *gorm.DB, id string) (*models.Payee, error)
+	List(scopedDB *gorm.DB) ([]*models.Payee, error)
+	Update(scopedDB *gorm.DB, payee *models.Payee) error
+	Delete(scopedDB *gorm.DB, id string) error
+}
+
+// payeeRepository implements PayeeRepository
+type payeeRepository struct{}
+
+// NewPayeeRepository creates a new payee repository
+func NewPayeeRepository() PayeeRepository {
+	return &payeeRepository{}
+}
+
+// Create creates a new payee
+func (r *payeeRepository) Create(scopedDB *gorm.DB, payee *models.Payee) error {
+	if payee.ID == "" {
+		payee.ID = uuid.New().String()
+	}
+	payee.CreatedAt = utils.NowInAppTimezone()
+	payee.UpdatedAt = utils.NowInAppTimezone()
+
+	return scopedDB.Session(&gorm.Session{}).Create(payee).Error
+}
+
+// Get retrieves a payee by ID
+func (r *payeeRepository) Get(scopedDB *gorm.DB, id string) (*models.Payee, error) {
+	var payee models.Payee
+	if err := scopedDB.Session(&gorm.Session{}).First(&payee, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("payee not found")
+		}
+		return nil, err
+	}
+	return &payee, nil
+}
+
+// List retrieves all payees ordered by name
+func (r *payeeRepository) List(scopedDB *gorm.DB) ([]*models.Payee, error) {
+	var payees []*models.Payee
+	if err := scopedDB.Session(&gorm.Session{}).Order("name ASC").Find(&payees).Error; err != nil {
+		return nil, err
+	}
+	return payees, nil
+}
+
+// Update updates an existing payee. Fields are selected explicitly so empty values are written and
+// encrypted columns go through their serializer, which map based updates skip.
+func (r *payeeRepository) Update(scopedDB *gorm.DB, payee *models.Payee) error {
+	payee.UpdatedAt = utils.NowInAppTimezone()
+
+	result := scopedDB.Session(&gorm.Session{}).Model(payee).
+		Select("name", "website", "phone", "account_number", "portal_url", "notes", "updated_at").
+		Updates(payee)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("payee not found")
+	}
+	return nil
+}
+
+// Delete deletes a payee. Bills owed to it, including those in the trash, are left without a payee.
+func (r *payeeRepository) Delete(scopedDB *gorm.DB, id string) error {
+	if err := scopedDB.Session(&gorm.Session{}).Unscoped().Model(&models.Bill{}).
+		Where("payee_id = ?", id).
+		UpdateColumn("payee_id", nil).Error; err != nil {
+		return err
+	}
+	result := scopedDB.Session(&gorm.Session{}).Delete(&models.Payee{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("payee not found")
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package repository
 
 import (
-	"fmt"
+	"errors"
+	"slices"
+	"time"
 
 	"github.com/cryptk/williams/internal/models"
 	"github.com/cryptk/williams/pkg/utils"
@@ -9,12 +11,40 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrPaymentNotFound is returned for a payment that does not exist or belongs to another user
+var ErrPaymentNotFound = errors.New("payment not found")
+
+// maxBillIDsPerQuery bounds the bill IDs bound into one query, keeping well under the parameter limits
+// of every supported database
+const maxBillIDsPerQuery = 500
+
+// PaymentFilter narrows, orders and pages a payment listing
+type PaymentFilter struct {
+	BillID     string     // Payments of one bill; otherwise of every bill not in the trash
+	CategoryID string     // Payments of the bills currently in this category
+	TagID      string     // Payments of the bills carrying this tag
+	PayeeID    string     // Payments of the bills owed to this payee
+	AccountID  string     // Payments made from this funding account
+	From       *time.Time // Only payments made at or after this time
+	To         *time.Time // Only payments made before this time
+	MinAmount  *float64   // Inclusive bounds on the amount
+	MaxAmount  *float64
+	Sort       Sort    // By payment_date or amount; defaults to newest first
+	Cursor     *Cursor // Continue after this payment
+	Limit      int     // Zero returns every match
+}
+
 // PaymentRepository defines the interface for payment data operations
 type PaymentRepository interface {
 	Create(scopedDB *gorm.DB, payment *models.Payment) error
+	Get(scopedDB *gorm.DB, id string) (*models.Payment, error)
 	List(scopedDB *gorm.DB, billID string) ([]*models.Payment, error)
-	GetLatest(scopedDB *gorm.DB, billID string) (*models.Payment, error)
+	Search(scopedDB *gorm.DB, filter PaymentFilter) ([]*models.Payment, int64, error)
+	GetLatestForBills(scopedDB *gorm.DB, billIDs []string) (map[string]*models.Payment, error)
+	ListForBills(scopedDB *gorm.DB, billIDs []string) (map[string][]*models.Payment, error)
+	ListForAccountsSince(scopedDB *gorm.DB, since time.Time) (map[string][]*models.Payment, error)
 	Delete(scopedDB *gorm.DB, id string) error
+	Reverse(scopedDB *gorm.DB, id string, reversedAt time.Time, reason string) error
 }
 
 // paymentRepository implements PaymentRepository
@@ -42,6 +72,18 @@ func (r *paymentRepository) Create(scopedDB *gorm.DB, payment *models.Payment) e
 	return scopedDB.Session(&gorm.Session{}).Create(payment).Error
 }
 
+// Get retrieves a payment by ID
+func (r *paymentRepository) Get(scopedDB *gorm.DB, id string) (*models.Payment, error) {
+	var payment models.Payment
+	if err := scopedDB.Session(&gorm.Session{}).First(&payment, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
 // List retrieves all payments for a specific bill
 func (r *paymentRepository) List(scopedDB *gorm.DB, billID string) ([]*models.Payment, error) {
 	var payments []*models.Payment
@@ -53,16 +95,123 @@ func (r *paymentRepository) List(scopedDB *gorm.DB, billID string) ([]*models.Pa
 	return payments, nil
 }
 
-// GetLatest retrieves the most recent payment for a bill
-func (r *paymentRepository) GetLatest(scopedDB *gorm.DB, billID string) (*models.Payment, error) {
-	var payment models.Payment
-	if err := scopedDB.Session(&gorm.Session{}).Where("bill_id = ?", billID).Order("payment_date DESC").First(&payment).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, nil
+// Search retrieves the payments matching filter, along with how many match regardless of the cursor
+// and limit
+func (r *paymentRepository) Search(scopedDB *gorm.DB, filter PaymentFilter) ([]*models.Payment, int64, error) {
+	query := scopedDB.Session(&gorm.Session{}).Model(&models.Payment{})
+	if filter.BillID != "" {
+		query = query.Where("bill_id = ?", filter.BillID)
+	} else {
+		query = query.Where("bill_id IN (?)", scopedDB.Session(&gorm.Session{}).Model(&models.Bill{}).Select("id"))
+	}
+	if filter.CategoryID != "" {
+		query = query.Where("bill_id IN (?)", scopedDB.Session(&gorm.Session{}).Model(&models.Bill{}).Select("id").Where("category_id = ?", filter.CategoryID))
+	}
+	if filter.TagID != "" {
+		query = query.Where("bill_id IN (?)", scopedDB.Session(&gorm.Session{}).Model(&models.BillTag{}).Select("bill_id").Where("tag_id = ?", filter.TagID))
+	}
+	if filter.PayeeID != "" {
+		query = query.Where("bill_id IN (?)", scopedDB.Session(&gorm.Session{}).Model(&models.Bill{}).Select("id").Where("payee_id = ?", filter.PayeeID))
+	}
+	if filter.AccountID != "" {
+		query = query.Where("account_id = ?", filter.AccountID)
+	}
+	if filter.From != nil {
+		query = query.Where("payment_date >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("payment_date < ?", *filter.To)
+	}
+	if filter.MinAmount != nil {
+		query = query.Where("amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("amount <= ?", *filter.MaxAmount)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	sort := filter.Sort
+	if sort.Field == "" {
+		sort = Sort{Field: "payment_date", Desc: true}
+	}
+	var value any
+	if filter.Cursor != nil {
+		var err error
+		if value, err = cursorValue(filter.Cursor, sort.Field, "payment_date"); err != nil {
+			return nil, 0, err
 		}
+	}
+	query = applySort(query, sort.Field, sort.Desc, filter.Cursor, value)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var payments []*models.Payment
+	if err := query.Find(&payments).Error; err != nil {
+		return nil, 0, err
+	}
+	return payments, total, nil
+}
+
+// GetLatestForBills retrieves the most recent payment of each of the given bills, ignoring reversed
+// payments, keyed by bill ID. Bills without payments are left out. Each batch of bills is fetched in a
+// single query ranking payments with a window function, which SQLite, MySQL 8 and PostgreSQL support.
+func (r *paymentRepository) GetLatestForBills(scopedDB *gorm.DB, billIDs []string) (map[string]*models.Payment, error) {
+	latest := make(map[string]*models.Payment, len(billIDs))
+	for batch := range slices.Chunk(billIDs, maxBillIDsPerQuery) {
+		ranked := scopedDB.Session(&gorm.Session{}).Model(&models.Payment{}).
+			Select("*, ROW_NUMBER() OVER (PARTITION BY bill_id ORDER BY payment_date DESC, id DESC) AS payment_rank").
+			Where("bill_id IN ? AND reversed_at IS NULL", batch)
+
+		var payments []*models.Payment
+		if err := scopedDB.Session(&gorm.Session{}).Table("(?) AS ranked", ranked).
+			Where("payment_rank = 1").
+			Find(&payments).Error; err != nil {
+			return nil, err
+		}
+		for _, payment := range payments {
+			latest[payment.BillID] = payment
+		}
+	}
+	return latest, nil
+}
+
+// ListForBills retrieves all payments of the given bills keyed by bill ID, newest first like List
+func (r *paymentRepository) ListForBills(scopedDB *gorm.DB, billIDs []string) (map[string][]*models.Payment, error) {
+	byBill := make(map[string][]*models.Payment, len(billIDs))
+	for batch := range slices.Chunk(billIDs, maxBillIDsPerQuery) {
+		var payments []*models.Payment
+		if err := scopedDB.Session(&gorm.Session{}).Where("bill_id IN ?", batch).
+			Order("payment_date DESC").
+			Find(&payments).Error; err != nil {
+			return nil, err
+		}
+		for _, payment := range payments {
+			byBill[payment.BillID] = append(byBill[payment.BillID], payment)
+		}
+	}
+	return byBill, nil
+}
+
+// ListForAccountsSince retrieves the payments made from any funding account after since, keyed by
+// account ID. Reversed payments are left out.
+func (r *paymentRepository) ListForAccountsSince(scopedDB *gorm.DB, since time.Time) (map[string][]*models.Payment, error) {
+	var payments []*models.Payment
+	if err := scopedDB.Session(&gorm.Session{}).
+		Where("account_id IS NOT NULL AND reversed_at IS NULL AND payment_date > ?", since).
+		Order("payment_date ASC").
+		Find(&payments).Error; err != nil {
 		return nil, err
 	}
-	return &payment, nil
+	byAccount := make(map[string][]*models.Payment)
+	for _, payment := range payments {
+		byAccount[*payment.AccountID] = append(byAccount[*payment.AccountID], payment)
+	}
+	return byAccount, nil
 }
 
 // Delete deletes a payment by ID
@@ -71,7 +220,7 @@ func (r *paymentRepository) Delete(scopedDB *gorm.DB, id string) error {
 	var payment models.Payment
 	if err := scopedDB.Session(&gorm.Session{}).Where("id = ?", id).First(&payment).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return fmt.Errorf("payment not found")
+			return ErrPaymentNotFound
 		}
 		return err
 	}
@@ -79,3 +228,17 @@ func (r *paymentRepository) Delete(scopedDB *gorm.DB, id string) error {
 	// Delete the payment
 	return scopedDB.Session(&gorm.Session{}).Delete(&models.Payment{}, "id = ?", id).Error
 }
+
+// Reverse marks a payment as reversed
+func (r *paymentRepository) Reverse(scopedDB *gorm.DB, id string, reversedAt time.Time, reason string) error {
+	result := scopedDB.Session(&gorm.Session{}).Model(&models.Payment{}).
+		Where("id = ? AND reversed_at IS NULL", id).
+		Updates(map[string]any{"reversed_at": reversedAt, "reversal_reason": reason})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPaymentNotFound
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package repository_test
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cryptk/williams/internal/api/middleware"
+	"github.com/cryptk/williams/internal/config"
+	"github.com/cryptk/williams/internal/database"
+	"github.com/cryptk/williams/internal/encryption"
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	// Every query is logged at debug level, which would drown out the results
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	cipher, err := encryption.New(&config.EncryptionConfig{Key: base64.StdEncoding.EncodeToString(key)})
+	if err != nil {
+		panic(err)
+	}
+	encryption.Use(cipher)
+
+	os.Exit(m.Run())
+}
+
+// newTestDB opens a migrated SQLite database that is removed when the test ends
+func newTestDB(tb testing.TB) *database.DB {
+	tb.Helper()
+	db, err := database.New(&config.DatabaseConfig{
+		Driver: "sqlite",
+		DSN:    filepath.Join(tb.TempDir(), "williams.db"),
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { db.Close() })
+	if err := db.RunMigrations(); err != nil {
+		tb.Fatal(err)
+	}
+	return db
+}
+
+// seedPayments creates a user with the given number of bills, each with paymentsPerBill monthly payments,
+// and returns the user ID and bill IDs. Every tenth bill has no payments and the latest payment of every
+// fourth bill is reversed, so the lookups have to skip it.
+func seedPayments(tb testing.TB, db *database.DB, bills, paymentsPerBill int) (string, []string) {
+	tb.Helper()
+	user := &models.User{
+		ID:           uuid.New().String(),
+		Username:     "user-" + uuid.New().String()[:8],
+		Email:        uuid.New().String() + "@example.com",
+		PasswordHash: "x",
+		Roles:        []string{"user"},
+	}
+	if err := db.Create(user).Error; err != nil {
+		tb.Fatal(err)
+	}
+
+	start := time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+	billIDs := make([]string, 0, bills)
+	billRows := make([]*models.Bill, 0, bills)
+	var paymentRows []*models.Payment
+	for i := range bills {
+		bill := &models.Bill{
+			ID:             uuid.New().String(),
+			UserID:         user.ID,
+			Name:           fmt.Sprintf("Bill %d", i),
+			Amount:         100,
+			RecurrenceDays: 15,
+			RecurrenceType: "fixed_date",
+			Kind:           models.BillKindStandard,
+		}
+		billIDs = append(billIDs, bill.ID)
+		billRows = append(billRows, bill)
+		if i%10 == 9 {
+			continue
+		}
+		for n := range paymentsPerBill {
+			payment := &models.Payment{
+				ID:          uuid.New().String(),
+				BillID:      bill.ID,
+				UserID:      user.ID,
+				Amount:      100,
+				PaymentDate: start.AddDate(0, n, 0),
+			}
+			if i%4 == 3 && n == paymentsPerBill-1 {
+				reversedAt := payment.PaymentDate.AddDate(0, 0, 2)
+				payment.ReversedAt = &reversedAt
+				payment.ReversalReason = "insufficient funds"
+			}
+			paymentRows = append(paymentRows, payment)
+		}
+	}
+	if err := db.CreateInBatches(billRows, 200).Error; err != nil {
+		tb.Fatal(err)
+	}
+	if len(paymentRows) > 0 {
+		if err := db.CreateInBatches(paymentRows, 200).Error; err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return user.ID, billIDs
+}
+
+// latestPaymentPerBill looks up the latest payment of each bill with a query per bill, the way bill
+// status was calculated before GetLatestForBills
+func latestPaymentPerBill(scopedDB *gorm.DB, billIDs []string) (map[string]*models.Payment, error) {
+	latest := make(map[string]*models.Payment, len(billIDs))
+	for _, billID := range billIDs {
+		var payment models.Payment
+		err := scopedDB.Session(&gorm.Session{}).Where("bill_id = ? AND reversed_at IS NULL", billID).
+			Order("payment_date DESC").
+			First(&payment).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		latest[billID] = &payment
+	}
+	return latest, nil
+}
+
+// countQueries counts the SELECT statements run on db from now on. Subqueries are built with a dry run
+// and are not counted.
+func countQueries(tb testing.TB, db *database.DB) *atomic.Int64 {
+	tb.Helper()
+	var queries atomic.Int64
+	if err := db.Callback().Query().After("gorm:query").Register("test:count_queries", func(tx *gorm.DB) {
+		if !tx.DryRun {
+			queries.Add(1)
+		}
+	}); err != nil {
+		tb.Fatal(err)
+	}
+	return &queries
+}
+
+func TestGetLatestForBillsMatchesPerBillLookup(t *testing.T) {
+	db := newTestDB(t)
+	// More bills than fit in one batch
+	userID, billIDs := seedPayments(t, db, 1200, 3)
+	_, otherBillIDs := seedPayments(t, db, 20, 3)
+	scopedDB := db.Scopes(middleware.TenantScoped(userID))
+	repo := repository.NewPaymentRepository()
+
+	want, err := latestPaymentPerBill(scopedDB, billIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	queries := countQueries(t, db)
+	got, err := repo.GetLatestForBills(scopedDB, append(billIDs, otherBillIDs...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := queries.Load(); n != 3 {
+		t.Errorf("GetLatestForBills ran %d queries for %d bills, want 3", n, len(billIDs)+len(otherBillIDs))
+	}
+	if len(got) != len(want) {
+		t.Fatalf("GetLatestForBills returned %d bills, want %d", len(got), len(want))
+	}
+	for billID, payment := range want {
+		if got[billID] == nil || got[billID].ID != payment.ID {
+			t.Errorf("latest payment of bill %s: got %+v, want %s", billID, got[billID], payment.ID)
+		}
+	}
+	for _, billID := range otherBillIDs {
+		if got[billID] != nil {
+			t.Errorf("GetLatestForBills returned a payment of another user's bill %s", billID)
+		}
+	}
+}
+
+// BenchmarkLatestPayment compares looking up the latest payment of every bill with a query per bill
+// against GetLatestForBills. Run with:
+//
+//	go test ./internal/repository -run '^$' -bench LatestPayment
+func BenchmarkLatestPayment(b *testing.B) {
+	for _, bills := range []int{1000, 2500} {
+		db := newTestDB(b)
+		userID, billIDs := seedPayments(b, db, bills, 4)
+		scopedDB := db.Scopes(middleware.TenantScoped(userID))
+		repo := repository.NewPaymentRepository()
+		queries := countQueries(b, db)
+
+		lookups := []struct {
+			name   string
+			lookup func(*gorm.DB, []string) (map[string]*models.Payment, error)
+		}{
+			{"per_bill", latestPaymentPerBill},
+			{"batched", repo.GetLatestForBills},
+		}
+		for _, lookup := range lookups {
+			b.Run(fmt.Sprintf("%s/bills=%d", lookup.name, bills), func(b *testing.B) {
+				queries.Store(0)
+				for b.Loop() {
+					if _, err := lookup.lookup(scopedDB, billIDs); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(queries.Load())/float64(b.N), "queries/op")
+			})
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"github.com/cryptk/williams/internal/models"
+	"gorm.io/gorm"
+)
+
+// PreferenceRepository defines the interface for user preference data operations
+type PreferenceRepository interface {
+	Get(scopedDB *gorm.DB) (*models.UserPreferences, error)
+	Save(scopedDB *gorm.DB, preferences *models.UserPreferences) error
+}
+
+// preferenceRepository implements PreferenceRepository
+type preferenceRepository struct{}
+
+// NewPreferenceRepository creates a new preference repository
+func NewPreferenceRepository() PreferenceRepository {
+	return &preferenceRepository{}
+}
+
+// Get retrieves the user's preferences, or nil if they have not saved any
+func (r *preferenceRepository) Get(scopedDB *gorm.DB) (*models.UserPreferences, error) {
+	var preferences []*models.UserPreferences
+	if err := scopedDB.Session(&gorm.Session{}).Limit(1).Find(&preferences).Error; err != nil {
+		return nil, err
+	}
+	if len(preferences) == 0 {
+		return nil, nil
+	}
+	return preferences[0], nil
+}
+
+// Save creates or replaces the user's preferences
+func (r *preferenceRepository) Save(scopedDB *gorm.DB, preferences *models.UserPreferences) error {
+	existing, err := r.Get(scopedDB)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return scopedDB.Session(&gorm.Session{}).Create(preferences).Error
+	}
+	preferences.CreatedAt = existing.CreatedAt
+	return scopedDB.Session(&gorm.Session{}).Model(&models.UserPreferences{}).
+		Select("timezone", "locale", "first_day_of_week", "currency", "date_format", "updated_at").
+		Updates(preferences).Error
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RecoveryCodeRepository defines the interface for 2FA recovery code data operations
+type RecoveryCodeRepository interface {
+	Replace(userID string, codeHashes []string) error
+	Consume(userID, codeHash string) (bool, error)
+	CountUnused(userID string) (int64, error)
+	DeleteAll(userID string) error
+}
+
+// recoveryCodeRepository implements RecoveryCodeRepository
+type recoveryCodeRepository struct {
+	db *gorm.DB // Recovery codes are used during login, before a scoped DB exists
+}
+
+// NewRecoveryCodeRepository creates a new recovery code repository
+func NewRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &recoveryCodeRepository{db: db}
+}
+
+// Replace deletes all existing recovery codes for a user and stores the given hashes
+func (r *recoveryCodeRepository) Replace(userID string, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		codes := make([]models.RecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, models.RecoveryCode{
+				ID:        uuid.New().String(),
+				UserID:    userID,
+				CodeHash:  hash,
+				CreatedAt: now,
+			})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// Consume marks an unused recovery code as used. Returns false if no matching unused code exists.
+// The conditional update makes this safe against two concurrent logins using the same code.
+func (r *recoveryCodeRepository) Consume(userID, codeHash string) (bool, error) {
+	result := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountUnused returns the number of recovery codes the user has left
+func (r *recoveryCodeRepository) CountUnused(userID string) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// DeleteAll removes all recovery codes for a user
+func (r *recoveryCodeRepository) DeleteAll(userID string) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cryptk/williams/internal/encryption"
+	"gorm.io/gorm"
+)
+
+// reencryptBatchSize is the number of rows read at a time while re-encrypting
+const reencryptBatchSize = 500
+
+// encryptedColumns lists the columns of each table stored with the encrypted serializer
+var encryptedColumns = []struct {
+	table   string
+	columns []string
+}{
+	{"bills", []string{"notes", "autopay_account", "autopay_notes"}},
+	{"payments", []string{"notes"}},
+	{"payees", []string{"account_number", "notes"}},
+	{"users", []string{"totp_secret"}},
+}
+
+// Reencrypt rewrites every encrypted column of every user that is plaintext, in an older format or
+// sealed with a key other than the cipher's primary key. It returns the number of rows rewritten.
+// Rows are read directly from their tables so bills in the trash are included.
+func Reencrypt(db *gorm.DB, cipher *encryption.Cipher) (int64, error) {
+	var rewritten int64
+	for _, table := range encryptedColumns {
+		n, err := reencryptTable(db, cipher, table.table, table.columns)
+		if err != nil {
+			return rewritten, fmt.Errorf("failed to re-encrypt %s: %w", table.table, err)
+		}
+		rewritten += n
+	}
+	return rewritten, nil
+}
+
+// reencryptTable re-encrypts the given columns of one table, walking it in batches ordered by ID
+func reencryptTable(db *gorm.DB, cipher *encryption.Cipher, table string, columns []string) (int64, error) {
+	var rewritten int64
+	lastID := ""
+	for {
+		var rows []map[string]any
+		if err := db.Table(table).
+			Select(append([]string{"id"}, columns...)).
+			Where("id > ?", lastID).
+			Order("id ASC").
+			Limit(reencryptBatchSize).
+			Find(&rows).Error; err != nil {
+			return rewritten, err
+		}
+
+		for _, row := range rows {
+			lastID = fmt.Sprint(row["id"])
+			updates := make(map[string]any)
+			for _, column := range columns {
+				stored := columnString(row[column])
+				if !cipher.NeedsRotation(stored) {
+					continue
+				}
+				plaintext, err := cipher.Decrypt(stored)
+				if err != nil {
+					return rewritten, fmt.Errorf("failed to decrypt %s of %s: %w", column, lastID, err)
+				}
+				if updates[column], err = cipher.Encrypt(plaintext); err != nil {
+					return rewritten, err
+				}
+			}
+			if len(updates) == 0 {
+				continue
+			}
+			if err := db.Table(table).Where("id = ?", lastID).UpdateColumns(updates).Error; err != nil {
+				return rewritten, err
+			}
+			rewritten++
+		}
+
+		if len(rows) < reencryptBatchSize {
+			return rewritten, nil
+		}
+	}
+}
+
+// columnString returns a text column read into a map as a string
+func columnString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TagRepository defines the interface for tag data operations
+type TagRepository interface {
+	Create(scopedDB *gorm.DB, tag *models.Tag) error
+	Get(scopedDB *gorm.DB, id string) (*models.Tag, error)
+	GetByName(scopedDB *gorm.DB, name string) (*models.Tag, error)
+	List(scopedDB *gorm.DB) ([]*models.Tag, error)
+	Update(scopedDB *gorm.DB, tag *models.Tag) error
+	Delete(scopedDB *gorm.DB, id string) error
+	SetBillTags(scopedDB *gorm.DB, billID, userID string, tagIDs []string) error
+	ListBillTags(scopedDB *gorm.DB, billID string) ([]string, error)
+	ListAllBillTags(scopedDB *gorm.DB) ([]*models.BillTag, error)
+}
+
+// tagRepository implements TagRepository
+type tagRepository struct{}
+
+// NewTagRepository creates a new tag repository
+func NewTagRepository() TagRepository {
+	return &tagRepository{}
+}
+
+// Create creates a new tag
+func (r *tagRepository) Create(scopedDB *gorm.DB, tag *models.Tag) error {
+	if tag.ID == "" {
+		tag.ID = uuid.New().String()
+	}
+	return scopedDB.Session(&gorm.Session{}).Create(tag).Error
+}
+
+// Get retrieves a tag by ID
+func (r *tagRepository) Get(scopedDB *gorm.DB, id string) (*models.Tag, error) {
+	var tag models.Tag
+	if err := scopedDB.Session(&gorm.Session{}).First(&tag, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("tag not found")
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetByName retrieves a tag by name, ignoring case. It returns nil if there is none.
+func (r *tagRepository) GetByName(scopedDB *gorm.DB, name string) (*models.Tag, error) {
+	var tag models.Tag
+	if err := scopedDB.Session(&gorm.Session{}).First(&tag, "LOWER(name) = LOWER(?)", name).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// List retrieves all tags ordered by name
+func (r *tagRepository) List(scopedDB *gorm.DB) ([]*models.Tag, error) {
+	var tags []*models.Tag
+	if err := scopedDB.Session(&gorm.Session{}).Order("name ASC").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// Update renames or recolors a tag
+func (r *tagRepository) Update(scopedDB *gorm.DB, tag *models.Tag) error {
+	result := scopedDB.Session(&gorm.Session{}).Model(&models.Tag{}).Where("id = ?", tag.ID).
+		Updates(map[string]any{"name": tag.Name, "color": tag.Color})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("tag not found")
+	}
+	return nil
+}
+
+// Delete deletes a tag and removes it from every bill
+func (r *tagRepository) Delete(scopedDB *gorm.DB, id string) error {
+	if err := scopedDB.Session(&gorm.Session{}).Delete(&models.BillTag{}, "tag_id = ?", id).Error; err != nil {
+		return err
+	}
+	result := scopedDB.Session(&gorm.Session{}).Delete(&models.Tag{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("tag not found")
+	}
+	return nil
+}
+
+// SetBillTags replaces the tags of a bill
+func (r *tagRepository) SetBillTags(scopedDB *gorm.DB, billID, userID string, tagIDs []string) error {
+	return scopedDB.Session(&gorm.Session{}).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.BillTag{}, "bill_id = ?", billID).Error; err != nil {
+			return err
+		}
+		if len(tagIDs) == 0 {
+			return nil
+		}
+		links := make([]*models.BillTag, 0, len(tagIDs))
+		for _, tagID := range tagIDs {
+			links = append(links, &models.BillTag{BillID: billID, TagID: tagID, UserID: userID})
+		}
+		return tx.Create(links).Error
+	})
+}
+
+// ListBillTags retrieves the tag IDs of a bill
+func (r *tagRepository) ListBillTags(scopedDB *gorm.DB, billID string) ([]string, error) {
+	var tagIDs []string
+	if err := scopedDB.Session(&gorm.Session{}).Model(&models.BillTag{}).
+		Where("bill_id = ?", billID).
+		Pluck("tag_id", &tagIDs).Error; err != nil {
+		return nil, err
+	}
+	return tagIDs, nil
+}
+
+// ListAllBillTags retrieves the tags of every bill
+func (r *tagRepository) ListAllBillTags(scopedDB *gorm.DB) ([]*models.BillTag, error) {
+	var links []*models.BillTag
+	if err := scopedDB.Session(&gorm.Session{}).Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
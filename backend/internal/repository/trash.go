@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ListOptions controls which records a list query returns
+type ListOptions struct {
+	IncludeArchived bool // Archived records are hidden unless requested
+}
+
+// applyListOptions filters out archived records unless they were requested
+func applyListOptions(query *gorm.DB, opts ListOptions) *gorm.DB {
+	if !opts.IncludeArchived {
+		query = query.Where("archived_at IS NULL")
+	}
+	return query
+}
+
+// setArchived sets or clears archived_at on a record that is not in the trash
+func setArchived(scopedDB *gorm.DB, model any, name, id string, archivedAt *time.Time) error {
+	result := scopedDB.Session(&gorm.Session{}).Model(model).Where("id = ?", id).Update("archived_at", archivedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%s not found", name)
+	}
+	return nil
+}
+
+// listDeleted retrieves the records in the trash, most recently deleted first
+func listDeleted[T any](scopedDB *gorm.DB) ([]*T, error) {
+	var records []*T
+	if err := scopedDB.Session(&gorm.Session{}).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// restoreDeleted moves a record out of the trash
+func restoreDeleted(scopedDB *gorm.DB, model any, name, id string) error {
+	result := scopedDB.Session(&gorm.Session{}).Unscoped().Model(model).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%s not found in trash", name)
+	}
+	return nil
+}
+
+// purgeDeleted permanently deletes records that have been in the trash since before cutoff.
+// Foreign keys cascade to dependent rows such as payments.
+func purgeDeleted(db *gorm.DB, model any, cutoff time.Time) (int64, error) {
+	result := db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Delete(model)
+	return result.RowsAffected, result.Error
+}
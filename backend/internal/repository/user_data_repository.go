@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+	"gorm.io/gorm"
+)
+
+// UserDataRepository defines the interface for operations on everything stored about a user
+type UserDataRepository interface {
+	Export(scopedDB *gorm.DB, export *models.UserDataExport) error
+	ListDeletionDue(cutoff time.Time) ([]*models.User, error)
+	Delete(userID string) error
+}
+
+// userDataRepository implements UserDataRepository
+type userDataRepository struct {
+	db *gorm.DB
+}
+
+// NewUserDataRepository creates a new user data repository
+func NewUserDataRepository(db *gorm.DB) UserDataRepository {
+	return &userDataRepository{db: db}
+}
+
+// Export fills export with the user's records, oldest first. Bills and categories in the trash are
+// included, and each bill carries its loan terms, late fee rule and tag IDs.
+func (r *userDataRepository) Export(scopedDB *gorm.DB, export *models.UserDataExport) error {
+	records := []struct {
+		name string
+		dest any
+	}{
+		{"categories", &export.Categories},
+		{"tags", &export.Tags},
+		{"payees", &export.Payees},
+		{"funding accounts", &export.FundingAccounts},
+		{"bills", &export.Bills},
+		{"bill versions", &export.BillVersions},
+		{"bill pauses", &export.BillPauses},
+		{"bill skips", &export.BillSkips},
+		{"bill statements", &export.BillStatements},
+		{"late fee waivers", &export.LateFeeWaivers},
+		{"payments", &export.Payments},
+		{"attachments", &export.Attachments},
+		{"holidays", &export.Holidays},
+		{"api tokens", &export.APITokens},
+		{"identities", &export.Identities},
+		{"audit events", &export.AuditEvents},
+	}
+	for _, record := range records {
+		if err := scopedDB.Session(&gorm.Session{}).Unscoped().Order("created_at ASC").Find(record.dest).Error; err != nil {
+			return fmt.Errorf("failed to export %s: %w", record.name, err)
+		}
+	}
+
+	var loans []*models.BillLoan
+	if err := scopedDB.Session(&gorm.Session{}).Find(&loans).Error; err != nil {
+		return fmt.Errorf("failed to export bill loans: %w", err)
+	}
+	var rules []*models.BillLateFeeRule
+	if err := scopedDB.Session(&gorm.Session{}).Find(&rules).Error; err != nil {
+		return fmt.Errorf("failed to export late fee rules: %w", err)
+	}
+	var billTags []*models.BillTag
+	if err := scopedDB.Session(&gorm.Session{}).Find(&billTags).Error; err != nil {
+		return fmt.Errorf("failed to export bill tags: %w", err)
+	}
+
+	bills := make(map[string]*models.Bill, len(export.Bills))
+	for _, bill := range export.Bills {
+		bill.TagIDs = []string{}
+		bills[bill.ID] = bill
+	}
+	for _, loan := range loans {
+		if bill, ok := bills[loan.BillID]; ok {
+			bill.Loan = loan
+		}
+	}
+	for _, rule := range rules {
+		if bill, ok := bills[rule.BillID]; ok {
+			bill.LateFeeRule = rule
+		}
+	}
+	for _, billTag := range billTags {
+		if bill, ok := bills[billTag.BillID]; ok {
+			bill.TagIDs = append(bill.TagIDs, billTag.TagID)
+		}
+	}
+	return nil
+}
+
+// ListDeletionDue retrieves the users whose account deletion was requested before cutoff
+func (r *userDataRepository) ListDeletionDue(cutoff time.Time) ([]*models.User, error) {
+	var users []*models.User
+	if err := r.db.Where("deletion_requested_at IS NOT NULL AND deletion_requested_at < ?", cutoff).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// Delete permanently deletes a user whose account deletion has been requested. Foreign keys cascade to
+// their bills, payments and other records; categories have no foreign key to users and are deleted
+// explicitly. Audit events are kept, and attachments are left for the cleanup job to remove together
+// with their stored files.
+func (r *userDataRepository) Delete(userID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND deletion_requested_at IS NOT NULL", userID).Delete(&models.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("user not found or not scheduled for deletion")
+		}
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.Category{}).Error; err != nil {
+			return fmt.Errorf("failed to delete categories: %w", err)
+		}
+		return nil
+	})
+}
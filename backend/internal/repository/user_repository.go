@@ -19,6 +19,7 @@ type UserRepository interface {
 	GetByUsername(username string) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
 	Update(user *models.User) error
+	AdvanceTOTPStep(id string, step int64) (bool, error)
 	Count() (int64, error)
 }
 
@@ -159,6 +160,18 @@ func (r *userRepository) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
 
+// AdvanceTOTPStep records the last accepted TOTP time step for a user.
+// Returns false if an equal or later step has already been recorded (i.e. the code is being replayed).
+func (r *userRepository) AdvanceTOTPStep(id string, step int64) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", id, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
 // Count returns the total number of users
 func (r *userRepository) Count() (int64, error) {
 	var count int64
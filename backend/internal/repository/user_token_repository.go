@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserTokenRepository defines the interface for single-use emailed token data operations
+type UserTokenRepository interface {
+	Create(token *models.UserToken) error
+	Consume(purpose, tokenHash string) (*models.UserToken, error)
+	DeleteForUser(userID, purpose string) error
+}
+
+// userTokenRepository implements UserTokenRepository
+type userTokenRepository struct {
+	db *gorm.DB // Tokens are redeemed by unauthenticated users (e.g. forgotten password)
+}
+
+// NewUserTokenRepository creates a new user token repository
+func NewUserTokenRepository(db *gorm.DB) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+// Create stores a new token
+func (r *userTokenRepository) Create(token *models.UserToken) error {
+	if token.ID == "" {
+		token.ID = uuid.New().String()
+	}
+	token.CreatedAt = time.Now()
+
+	return r.db.Create(token).Error
+}
+
+// Consume looks up an unused, unexpired token and marks it as used.
+// The conditional update guarantees a token can only be redeemed once, even under concurrent requests.
+func (r *userTokenRepository) Consume(purpose, tokenHash string) (*models.UserToken, error) {
+	var token models.UserToken
+	if err := r.db.First(&token, "purpose = ? AND token_hash = ?", purpose, tokenHash).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("token not found")
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if token.UsedAt != nil || now.After(token.ExpiresAt) {
+		return nil, fmt.Errorf("token has expired or already been used")
+	}
+
+	result := r.db.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", token.ID).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("token has expired or already been used")
+	}
+
+	token.UsedAt = &now
+	return &token, nil
+}
+
+// DeleteForUser removes all tokens of a purpose for a user, invalidating any outstanding links
+func (r *userTokenRepository) DeleteForUser(userID, purpose string) error {
+	return r.db.Where("user_id = ? AND purpose = ?", userID, purpose).Delete(&models.UserToken{}).Error
+}
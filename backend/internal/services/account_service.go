@@ -0,0 +1,515 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cryptk/williams/internal/mailer"
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// passwordResetTTL is how long a password reset link stays valid
+	passwordResetTTL = time.Hour
+	// emailVerificationTTL is how long an email verification link stays valid
+	emailVerificationTTL = 24 * time.Hour
+	// accountDeletionInterval is how often accounts past their deletion grace period are deleted
+	accountDeletionInterval = time.Hour
+)
+
+// AccountService handles password management, email verification, profile changes, personal data
+// export and account deletion
+type AccountService struct {
+	authService   *AuthService
+	userRepo      repository.UserRepository
+	userTokenRepo repository.UserTokenRepository
+	userDataRepo  repository.UserDataRepository
+	preferences   *PreferenceService
+	mailer        mailer.Mailer
+	audit         *AuditService
+	publicURL     string
+	deletionGrace time.Duration
+}
+
+// NewAccountService creates a new account service
+func NewAccountService(authService *AuthService, userRepo repository.UserRepository, userTokenRepo repository.UserTokenRepository, userDataRepo repository.UserDataRepository, preferences *PreferenceService, m mailer.Mailer, audit *AuditService, publicURL string, deletionGrace time.Duration) *AccountService {
+	return &AccountService{
+		authService:   authService,
+		userRepo:      userRepo,
+		userTokenRepo: userTokenRepo,
+		userDataRepo:  userDataRepo,
+		preferences:   preferences,
+		mailer:        m,
+		audit:         audit,
+		publicURL:     strings.TrimRight(publicURL, "/"),
+		deletionGrace: deletionGrace,
+	}
+}
+
+// =============================================================================
+// Password Methods
+// =============================================================================
+
+// ChangePassword changes the password of a logged in user after checking the current one.
+// All existing sessions and API tokens are revoked; a fresh token for the current session is returned.
+func (s *AccountService) ChangePassword(ctx context.Context, userID string, req *models.ChangePasswordRequest) (string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.authService.CheckPassword(ctx, user, req.CurrentPassword); err != nil {
+		return "", err
+	}
+
+	revoked, err := s.setPassword(user, req.NewPassword)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := s.authService.generateToken(user)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	log.Info().Str("user_id", user.ID).Msg("Password changed")
+	s.audit.Record(ctx, &models.AuditEvent{
+		UserID:     &user.ID,
+		Action:     models.AuditActionPasswordChange,
+		EntityType: "user",
+		EntityID:   user.ID,
+		Details:    fmt.Sprintf("api_tokens_revoked=%d", revoked),
+	})
+	return token, nil
+}
+
+// RequestPasswordReset emails a password reset link if an account exists for the address.
+// It deliberately reports success either way so the endpoint cannot be used to discover accounts.
+func (s *AccountService) RequestPasswordReset(email string) error {
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		log.Debug().Msg("Password reset requested for unknown email")
+		return nil
+	}
+
+	token, err := s.issueToken(user, models.UserTokenPasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\n"+
+		"Someone requested a password reset for your Williams account. "+
+		"If this was you, open the link below within %d minutes to choose a new password:\n\n"+
+		"%s/reset-password?token=%s\n\n"+
+		"If you did not request this, you can ignore this email.\n",
+		user.Username, int(passwordResetTTL.Minutes()), s.publicURL, token)
+
+	if err := s.mailer.Send(user.Email, "Reset your Williams password", body); err != nil {
+		return err
+	}
+
+	log.Info().Str("user_id", user.ID).Msg("Password reset email sent")
+	return nil
+}
+
+// ResetPassword sets a new password using an emailed reset token and revokes all sessions and API tokens
+func (s *AccountService) ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error {
+	token, err := s.userTokenRepo.Consume(models.UserTokenPasswordReset, hashToken(req.Token))
+	if err != nil {
+		return errors.New("invalid or expired reset token")
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		return errors.New("invalid or expired reset token")
+	}
+
+	// The link must have been sent to the address currently on the account
+	if token.Email != user.Email {
+		return errors.New("invalid or expired reset token")
+	}
+
+	// Receiving the reset link proves ownership of the address
+	user.EmailVerified = true
+
+	revoked, err := s.setPassword(user, req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Str("user_id", user.ID).Msg("Password reset via email")
+	s.audit.Record(ctx, &models.AuditEvent{
+		UserID:     &user.ID,
+		ActorID:    &user.ID,
+		Action:     models.AuditActionPasswordReset,
+		EntityType: "user",
+		EntityID:   user.ID,
+		Details:    fmt.Sprintf("api_tokens_revoked=%d", revoked),
+	})
+	return nil
+}
+
+// setPassword hashes and stores a new password, bumps the token version so that every
+// previously issued session token is rejected, revokes every API token and invalidates outstanding
+// reset links. It returns how many API tokens were revoked.
+func (s *AccountService) setPassword(user *models.User, password string) (int64, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user.PasswordHash = string(hashedPassword)
+	user.TokenVersion++
+	if err := s.userRepo.Update(user); err != nil {
+		return 0, fmt.Errorf("failed to update password: %w", err)
+	}
+
+	// API tokens do not carry the token version, so a token created by whoever knew the old
+	// password would otherwise outlive the change
+	revoked, err := s.authService.apiTokenRepo.DeleteForUser(user.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke api tokens: %w", err)
+	}
+
+	if err := s.userTokenRepo.DeleteForUser(user.ID, models.UserTokenPasswordReset); err != nil {
+		log.Warn().Err(err).Str("user_id", user.ID).Msg("Failed to invalidate outstanding password reset tokens")
+	}
+	return revoked, nil
+}
+
+// =============================================================================
+// Email Verification Methods
+// =============================================================================
+
+// SendVerificationEmail emails a link confirming that the user owns their email address
+func (s *AccountService) SendVerificationEmail(user *models.User) error {
+	if err := s.userTokenRepo.DeleteForUser(user.ID, models.UserTokenEmailVerification); err != nil {
+		return fmt.Errorf("failed to invalidate previous verification tokens: %w", err)
+	}
+
+	token, err := s.issueToken(user, models.UserTokenEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\n"+
+		"Please confirm your email address for Williams by opening the link below within %d hours:\n\n"+
+		"%s/verify-email?token=%s\n",
+		user.Username, int(emailVerificationTTL.Hours()), s.publicURL, token)
+
+	if err := s.mailer.Send(user.Email, "Confirm your email address", body); err != nil {
+		return err
+	}
+
+	log.Info().Str("user_id", user.ID).Msg("Verification email sent")
+	return nil
+}
+
+// ResendVerificationEmail sends a new verification link to a logged in user
+func (s *AccountService) ResendVerificationEmail(userID string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return errors.New("email address is already verified")
+	}
+	return s.SendVerificationEmail(user)
+}
+
+// VerifyEmail marks the user's email as verified using an emailed token
+func (s *AccountService) VerifyEmail(ctx context.Context, req *models.VerifyEmailRequest) (*models.User, error) {
+	token, err := s.userTokenRepo.Consume(models.UserTokenEmailVerification, hashToken(req.Token))
+	if err != nil {
+		return nil, errors.New("invalid or expired verification token")
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		return nil, errors.New("invalid or expired verification token")
+	}
+
+	// A link sent to a previous address must not verify the current one
+	if token.Email != user.Email {
+		return nil, errors.New("invalid or expired verification token")
+	}
+
+	user.EmailVerified = true
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	log.Info().Str("user_id", user.ID).Msg("Email address verified")
+	s.audit.Record(ctx, &models.AuditEvent{
+		UserID:     &user.ID,
+		ActorID:    &user.ID,
+		Action:     models.AuditActionEmailVerified,
+		EntityType: "user",
+		EntityID:   user.ID,
+		Details:    "email=" + user.Email,
+	})
+	return user, nil
+}
+
+// =============================================================================
+// Profile Methods
+// =============================================================================
+
+// UpdateProfile changes the username and email of a logged in user. Changing the email requires the
+// current password; the new address must be verified again and the previous one is told of the change.
+func (s *AccountService) UpdateProfile(ctx context.Context, userID string, req *models.UpdateProfileRequest) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	before := *user
+
+	username := strings.TrimSpace(req.Username)
+	if username != "" && username != user.Username {
+		if existing, err := s.userRepo.GetByUsername(username); err == nil && existing.ID != user.ID {
+			return nil, errors.New("username already exists")
+		}
+		user.Username = username
+	}
+
+	email := strings.TrimSpace(req.Email)
+	emailChanged := email != "" && email != user.Email
+	if emailChanged {
+		if req.CurrentPassword == "" {
+			return nil, errors.New("current password is required to change the email address")
+		}
+		if err := s.authService.CheckPassword(ctx, user, req.CurrentPassword); err != nil {
+			return nil, err
+		}
+		if existing, err := s.userRepo.GetByEmail(email); err == nil && existing.ID != user.ID {
+			return nil, errors.New("email already exists")
+		}
+		user.Email = email
+		user.EmailVerified = false
+	}
+
+	if user.Username == before.Username && !emailChanged {
+		return user, nil
+	}
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	if emailChanged {
+		// Reset links sent to the previous address must not be usable any more
+		if err := s.userTokenRepo.DeleteForUser(user.ID, models.UserTokenPasswordReset); err != nil {
+			log.Warn().Err(err).Str("user_id", user.ID).Msg("Failed to invalidate outstanding password reset tokens")
+		}
+		// Failing to send either email should not fail the change; the user can request a new link
+		if err := s.SendVerificationEmail(user); err != nil {
+			log.Error().Err(err).Str("user_id", user.ID).Msg("Failed to send verification email")
+		}
+		body := fmt.Sprintf("Hi %s,\n\n"+
+			"The email address of your Williams account was changed to %s. "+
+			"If you did not make this change, reset your password and contact your administrator.\n",
+			user.Username, user.Email)
+		if err := s.mailer.Send(before.Email, "Your Williams email address was changed", body); err != nil {
+			log.Error().Err(err).Str("user_id", user.ID).Msg("Failed to send email change notice")
+		}
+	}
+
+	log.Info().Str("user_id", user.ID).Msg("Profile updated")
+	s.audit.Record(ctx, &models.AuditEvent{
+		UserID:     &user.ID,
+		Action:     models.AuditActionProfileUpdate,
+		EntityType: "user",
+		EntityID:   user.ID,
+		Changes:    auditDiff(&before, user),
+	})
+	return user, nil
+}
+
+// =============================================================================
+// Data Export Methods
+// =============================================================================
+
+// ExportData collects everything stored about the user: their account, preferences, bills with their
+// history, payments and the records they refer to, attachment metadata, API tokens, linked sign-on
+// accounts and audit history. Encrypted fields are decrypted.
+func (s *AccountService) ExportData(scopedDB *gorm.DB, userID string) (*models.UserDataExport, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	preferences, err := s.preferences.Get(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &models.UserDataExport{
+		ExportedAt:  time.Now(),
+		User:        user,
+		Preferences: preferences,
+	}
+	if err := s.userDataRepo.Export(scopedDB, export); err != nil {
+		return nil, err
+	}
+	setDownloadURL(export.Attachments...)
+
+	log.Info().Str("user_id", user.ID).Msg("Personal data exported")
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionDataExport,
+		EntityType: "user",
+		EntityID:   user.ID,
+	})
+	return export, nil
+}
+
+// =============================================================================
+// Account Deletion Methods
+// =============================================================================
+
+// RequestDeletion schedules the user's account for permanent deletion once the password is confirmed,
+// returning when it will be deleted. Until the grace period has passed the user can still log in and
+// restore the account; without a grace period it is deleted immediately.
+func (s *AccountService) RequestDeletion(ctx context.Context, userID string, req *models.DeleteAccountRequest) (time.Time, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if user.DeletionRequestedAt != nil {
+		return time.Time{}, errors.New("account deletion has already been requested")
+	}
+	if err := s.authService.CheckPassword(ctx, user, req.Password); err != nil {
+		return time.Time{}, err
+	}
+
+	now := time.Now()
+	user.DeletionRequestedAt = &now
+	if err := s.userRepo.Update(user); err != nil {
+		return time.Time{}, fmt.Errorf("failed to request account deletion: %w", err)
+	}
+
+	scheduledFor := now.Add(s.deletionGrace)
+	log.Info().Str("user_id", user.ID).Time("deletion_scheduled_for", scheduledFor).Msg("Account deletion requested")
+	s.audit.Record(ctx, &models.AuditEvent{
+		UserID:     &user.ID,
+		Action:     models.AuditActionDeletionRequest,
+		EntityType: "user",
+		EntityID:   user.ID,
+		Details:    "deletion_scheduled_for=" + scheduledFor.UTC().Format(time.RFC3339),
+	})
+
+	if s.deletionGrace <= 0 {
+		return now, s.deleteUser(ctx, user)
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\n"+
+		"Your Williams account and all of its bills and payments will be permanently deleted on %s. "+
+		"To keep your account, log in and restore it before then:\n\n"+
+		"%s/login\n",
+		user.Username, scheduledFor.UTC().Format("January 2, 2006 15:04 MST"), s.publicURL)
+	if err := s.mailer.Send(user.Email, "Your Williams account is scheduled for deletion", body); err != nil {
+		log.Error().Err(err).Str("user_id", user.ID).Msg("Failed to send account deletion notice")
+	}
+	return scheduledFor, nil
+}
+
+// CancelDeletion restores an account that is scheduled for deletion
+func (s *AccountService) CancelDeletion(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletionRequestedAt == nil {
+		return nil, errors.New("account is not scheduled for deletion")
+	}
+
+	user.DeletionRequestedAt = nil
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("failed to restore account: %w", err)
+	}
+
+	log.Info().Str("user_id", user.ID).Msg("Account deletion cancelled")
+	s.audit.Record(ctx, &models.AuditEvent{
+		UserID:     &user.ID,
+		Action:     models.AuditActionDeletionCancel,
+		EntityType: "user",
+		EntityID:   user.ID,
+	})
+	return user, nil
+}
+
+// StartDeletionPurge deletes accounts whose grace period has passed now and then periodically until
+// ctx is cancelled
+func (s *AccountService) StartDeletionPurge(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(accountDeletionInterval)
+		defer ticker.Stop()
+		for {
+			s.purgeDeletions(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// purgeDeletions permanently deletes the accounts whose deletion was requested before the grace period
+func (s *AccountService) purgeDeletions(ctx context.Context) {
+	users, err := s.userDataRepo.ListDeletionDue(time.Now().Add(-s.deletionGrace))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list accounts due for deletion")
+		return
+	}
+	for _, user := range users {
+		if err := s.deleteUser(ctx, user); err != nil {
+			log.Error().Err(err).Str("user_id", user.ID).Msg("Failed to delete account")
+		}
+	}
+}
+
+// deleteUser permanently deletes a user and everything they own. The audit trail of the account is kept
+// until it expires under the audit retention period.
+func (s *AccountService) deleteUser(ctx context.Context, user *models.User) error {
+	if err := s.userDataRepo.Delete(user.ID); err != nil {
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+
+	log.Info().Str("user_id", user.ID).Msg("Account deleted")
+	s.audit.Record(ctx, &models.AuditEvent{
+		UserID:     &user.ID,
+		Action:     models.AuditActionUserDelete,
+		EntityType: "user",
+		EntityID:   user.ID,
+	})
+	return nil
+}
+
+// =============================================================================
+// Private Helper Methods
+// =============================================================================
+
+// issueToken creates and stores a single-use token, returning its plaintext value
+func (s *AccountService) issueToken(user *models.User, purpose string, ttl time.Duration) (string, error) {
+	plaintext, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.userTokenRepo.Create(&models.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashToken(plaintext),
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return "", fmt.Errorf("failed to store token: %w", err)
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,273 @@
+package services_test
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cryptk/williams/internal/api/middleware"
+	"github.com/cryptk/williams/internal/models"
+)
+
+// testMailer keeps the emails it is asked to send so tests can follow the links in them
+type testMailer struct {
+	mu     sync.Mutex
+	bodies []string
+}
+
+func (m *testMailer) Send(to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bodies = append(m.bodies, body)
+	return nil
+}
+
+var linkToken = regexp.MustCompile(`token=(\S+)`)
+
+// lastToken returns the token of the link in the most recently sent email
+func (m *testMailer) lastToken(t *testing.T) string {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.bodies) == 0 {
+		t.Fatal("no email was sent")
+	}
+	match := linkToken.FindStringSubmatch(m.bodies[len(m.bodies)-1])
+	if match == nil {
+		t.Fatalf("email has no token link: %s", m.bodies[len(m.bodies)-1])
+	}
+	return match[1]
+}
+
+const testPassword = "Passw0rd!234"
+
+// register creates a user through the auth service
+func (env *testEnv) register(t *testing.T, username string) *models.User {
+	t.Helper()
+	user, err := env.auth.Register(context.Background(), &models.RegisterRequest{
+		Username: username,
+		Email:    username + "@example.com",
+		Password: testPassword,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// requestReset emails a password reset link to the user and returns its token
+func (env *testEnv) requestReset(t *testing.T, user *models.User) string {
+	t.Helper()
+	if err := env.accounts.RequestPasswordReset(user.Email); err != nil {
+		t.Fatal(err)
+	}
+	return env.mail.lastToken(t)
+}
+
+// requestVerification emails a verification link to the user and returns its token
+func (env *testEnv) requestVerification(t *testing.T, user *models.User) string {
+	t.Helper()
+	if err := env.accounts.SendVerificationEmail(user); err != nil {
+		t.Fatal(err)
+	}
+	return env.mail.lastToken(t)
+}
+
+// expireTokens moves the expiry of every emailed token of the user into the past
+func (env *testEnv) expireTokens(t *testing.T, user *models.User) {
+	t.Helper()
+	if err := env.db.Model(&models.UserToken{}).Where("user_id = ?", user.ID).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResetPasswordRevokesSessionsAndAPITokens(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := env.register(t, "alice")
+	session, _, err := env.auth.Login(ctx, &models.LoginRequest{Username: "alice", Password: testPassword})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scopedDB := env.db.Scopes(middleware.TenantScoped(user.ID))
+	apiToken, _, err := env.auth.CreateAPIToken(scopedDB, user.ID, &models.CreateAPITokenRequest{Name: "script", Scope: models.APITokenScopeReadWrite})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := env.requestReset(t, user)
+	if err := env.accounts.ResetPassword(ctx, &models.ResetPasswordRequest{Token: token, NewPassword: "N3w-Passw0rd!"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The auth middleware rejects sessions whose version no longer matches the user's
+	claims, err := env.auth.ValidateToken(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := env.auth.GetUserByID(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.TokenVersion == stored.TokenVersion {
+		t.Error("session from before the reset is still valid")
+	}
+	if _, _, err := env.auth.ValidateAPIToken(apiToken); err == nil {
+		t.Error("API token from before the reset is still valid")
+	}
+	if _, _, err := env.auth.Login(ctx, &models.LoginRequest{Username: "alice", Password: "N3w-Passw0rd!"}); err != nil {
+		t.Errorf("login with the new password: %v", err)
+	}
+	if err := env.accounts.ResetPassword(ctx, &models.ResetPasswordRequest{Token: token, NewPassword: "An0ther-Passw0rd!"}); err == nil {
+		t.Error("reset token could be used twice")
+	}
+
+	var event models.AuditEvent
+	if err := env.db.Where("user_id = ? AND action = ?", user.ID, models.AuditActionPasswordReset).First(&event).Error; err != nil {
+		t.Fatal(err)
+	}
+	if event.Details != "api_tokens_revoked=1" {
+		t.Errorf("audit details: got %q, want %q", event.Details, "api_tokens_revoked=1")
+	}
+}
+
+func TestChangePasswordRevokesAPITokens(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.register(t, "alice")
+	scopedDB := env.db.Scopes(middleware.TenantScoped(user.ID))
+	apiToken, _, err := env.auth.CreateAPIToken(scopedDB, user.ID, &models.CreateAPITokenRequest{Name: "script", Scope: models.APITokenScopeRead})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.accounts.ChangePassword(context.Background(), user.ID, &models.ChangePasswordRequest{
+		CurrentPassword: testPassword,
+		NewPassword:     "N3w-Passw0rd!",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := env.auth.ValidateAPIToken(apiToken); err == nil {
+		t.Error("API token from before the password change is still valid")
+	}
+}
+
+func TestResetPasswordRejectsInvalidTokens(t *testing.T) {
+	tests := []struct {
+		name  string
+		token func(t *testing.T, env *testEnv, user *models.User) string
+	}{
+		{"unknown", func(t *testing.T, env *testEnv, user *models.User) string {
+			return "not-a-token"
+		}},
+		{"expired", func(t *testing.T, env *testEnv, user *models.User) string {
+			token := env.requestReset(t, user)
+			env.expireTokens(t, user)
+			return token
+		}},
+		{"verification token", func(t *testing.T, env *testEnv, user *models.User) string {
+			return env.requestVerification(t, user)
+		}},
+		{"superseded by a password change", func(t *testing.T, env *testEnv, user *models.User) string {
+			token := env.requestReset(t, user)
+			if _, err := env.accounts.ChangePassword(context.Background(), user.ID, &models.ChangePasswordRequest{
+				CurrentPassword: testPassword,
+				NewPassword:     "N3w-Passw0rd!",
+			}); err != nil {
+				t.Fatal(err)
+			}
+			return token
+		}},
+		{"sent to a previous email address", func(t *testing.T, env *testEnv, user *models.User) string {
+			token := env.requestReset(t, user)
+			if err := env.db.Model(&models.User{}).Where("id = ?", user.ID).Update("email", "new@example.com").Error; err != nil {
+				t.Fatal(err)
+			}
+			return token
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			user := env.register(t, "alice")
+			token := tt.token(t, env, user)
+
+			err := env.accounts.ResetPassword(context.Background(), &models.ResetPasswordRequest{Token: token, NewPassword: "Hijack3d-Passw0rd!"})
+			if err == nil {
+				t.Fatal("password was reset")
+			}
+			if _, _, err := env.auth.Login(context.Background(), &models.LoginRequest{Username: "alice", Password: "Hijack3d-Passw0rd!"}); err == nil {
+				t.Error("login with the rejected password succeeded")
+			}
+		})
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := env.register(t, "alice")
+	token := env.requestVerification(t, user)
+
+	verified, err := env.accounts.VerifyEmail(ctx, &models.VerifyEmailRequest{Token: token})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verified.EmailVerified {
+		t.Error("email is not verified")
+	}
+	if _, err := env.accounts.VerifyEmail(ctx, &models.VerifyEmailRequest{Token: token}); err == nil {
+		t.Error("verification token could be used twice")
+	}
+}
+
+func TestVerifyEmailRejectsInvalidTokens(t *testing.T) {
+	tests := []struct {
+		name  string
+		token func(t *testing.T, env *testEnv, user *models.User) string
+	}{
+		{"unknown", func(t *testing.T, env *testEnv, user *models.User) string {
+			return "not-a-token"
+		}},
+		{"expired", func(t *testing.T, env *testEnv, user *models.User) string {
+			token := env.requestVerification(t, user)
+			env.expireTokens(t, user)
+			return token
+		}},
+		{"password reset token", func(t *testing.T, env *testEnv, user *models.User) string {
+			return env.requestReset(t, user)
+		}},
+		{"superseded by a newer link", func(t *testing.T, env *testEnv, user *models.User) string {
+			token := env.requestVerification(t, user)
+			env.requestVerification(t, user)
+			return token
+		}},
+		{"sent to a previous email address", func(t *testing.T, env *testEnv, user *models.User) string {
+			token := env.requestVerification(t, user)
+			if err := env.db.Model(&models.User{}).Where("id = ?", user.ID).Update("email", "new@example.com").Error; err != nil {
+				t.Fatal(err)
+			}
+			return token
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			user := env.register(t, "alice")
+			token := tt.token(t, env, user)
+
+			if _, err := env.accounts.VerifyEmail(context.Background(), &models.VerifyEmailRequest{Token: token}); err == nil {
+				t.Fatal("email was verified")
+			}
+			stored, err := env.auth.GetUserByID(user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.EmailVerified {
+				t.Error("email is marked verified")
+			}
+		})
+	}
+}
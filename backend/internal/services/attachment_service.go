@@ -0,0 +1,254 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cryptk/williams/internal/config"
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
+	"github.com/cryptk/williams/internal/storage"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// attachmentCleanupInterval is how often files of deleted bills and payments are removed
+const attachmentCleanupInterval = time.Hour
+
+// maxFileNameLength bounds the stored name of an uploaded file
+const maxFileNameLength = 255
+
+// AttachmentService handles files attached to bills and payments
+type AttachmentService struct {
+	repo        repository.AttachmentRepository
+	billRepo    repository.BillRepository
+	paymentRepo repository.PaymentRepository
+	store       storage.Store
+	audit       *AuditService
+	config      *config.AttachmentsConfig
+}
+
+// NewAttachmentService creates a new attachment service
+func NewAttachmentService(repo repository.AttachmentRepository, billRepo repository.BillRepository, paymentRepo repository.PaymentRepository, store storage.Store, audit *AuditService, cfg *config.AttachmentsConfig) *AttachmentService {
+	return &AttachmentService{
+		repo:        repo,
+		billRepo:    billRepo,
+		paymentRepo: paymentRepo,
+		store:       store,
+		audit:       audit,
+		config:      cfg,
+	}
+}
+
+// MaxSize returns the largest accepted file in bytes
+func (s *AttachmentService) MaxSize() int64 {
+	return s.config.MaxSize
+}
+
+// Upload stores a file and attaches it to attachment.BillID, or to attachment.PaymentID when set.
+// The content type is detected from the file contents and must be one of the allowed types.
+func (s *AttachmentService) Upload(ctx context.Context, scopedDB *gorm.DB, attachment *models.Attachment, r io.Reader) error {
+	if err := s.verifyParent(scopedDB, attachment.BillID, attachment.PaymentID); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, s.config.MaxSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if int64(len(data)) > s.config.MaxSize {
+		return fmt.Errorf("file exceeds the maximum size of %d bytes", s.config.MaxSize)
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("file is empty")
+	}
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil || !slices.Contains(s.config.AllowedTypes, contentType) {
+		return fmt.Errorf("file type %s is not allowed", contentType)
+	}
+
+	attachment.ID = uuid.New().String()
+	attachment.FileName = cleanFileName(attachment.FileName)
+	attachment.ContentType = contentType
+	attachment.Size = int64(len(data))
+	attachment.StorageKey = attachment.UserID + "/" + attachment.ID
+
+	if err := s.store.Put(ctx, attachment.StorageKey, bytes.NewReader(data), attachment.Size, contentType); err != nil {
+		return fmt.Errorf("failed to store file: %w", err)
+	}
+	if err := s.repo.Create(scopedDB, attachment); err != nil {
+		// Don't leave an unreferenced file behind
+		if deleteErr := s.store.Delete(ctx, attachment.StorageKey); deleteErr != nil {
+			log.Error().Err(deleteErr).Str("attachment_id", attachment.ID).Msg("Failed to remove stored file of unsaved attachment")
+		}
+		return err
+	}
+	setDownloadURL(attachment)
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionAttachmentCreate,
+		EntityType: "attachment",
+		EntityID:   attachment.ID,
+		Changes:    auditDiff(nil, attachment),
+	})
+	return nil
+}
+
+// ListForBill retrieves the attachments of a bill
+func (s *AttachmentService) ListForBill(scopedDB *gorm.DB, billID string) ([]*models.Attachment, error) {
+	if err := s.verifyParent(scopedDB, billID, nil); err != nil {
+		return nil, err
+	}
+	attachments, err := s.repo.ListForBill(scopedDB, billID)
+	if err != nil {
+		return nil, err
+	}
+	setDownloadURL(attachments...)
+	return attachments, nil
+}
+
+// ListForPayment retrieves the attachments of a payment of a bill
+func (s *AttachmentService) ListForPayment(scopedDB *gorm.DB, billID, paymentID string) ([]*models.Attachment, error) {
+	if err := s.verifyParent(scopedDB, billID, &paymentID); err != nil {
+		return nil, err
+	}
+	attachments, err := s.repo.ListForPayment(scopedDB, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	setDownloadURL(attachments...)
+	return attachments, nil
+}
+
+// Open returns an attachment and the contents of its file. The caller must close the reader.
+// Attachments of bills in the trash or of deleted payments cannot be downloaded.
+func (s *AttachmentService) Open(ctx context.Context, scopedDB *gorm.DB, id string) (*models.Attachment, io.ReadCloser, error) {
+	attachment, err := s.repo.Get(scopedDB, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.verifyParent(scopedDB, attachment.BillID, attachment.PaymentID); err != nil {
+		return nil, nil, fmt.Errorf("attachment not found")
+	}
+	file, err := s.store.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read stored file: %w", err)
+	}
+	return attachment, file, nil
+}
+
+// Delete removes an attachment and its stored file
+func (s *AttachmentService) Delete(ctx context.Context, scopedDB *gorm.DB, id string) error {
+	attachment, err := s.repo.Get(scopedDB, id)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Delete(ctx, attachment.StorageKey); err != nil {
+		return fmt.Errorf("failed to delete stored file: %w", err)
+	}
+	if err := s.repo.Delete(scopedDB, id); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionAttachmentDelete,
+		EntityType: "attachment",
+		EntityID:   id,
+		Changes:    auditDiff(attachment, nil),
+	})
+	return nil
+}
+
+// StartCleanup removes the files of deleted bills, payments and users now and then periodically until
+// ctx is cancelled
+func (s *AttachmentService) StartCleanup(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(attachmentCleanupInterval)
+		defer ticker.Stop()
+		for {
+			s.cleanupOrphaned(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// cleanupOrphaned deletes attachments whose bill, payment or user no longer exists, along with their files
+func (s *AttachmentService) cleanupOrphaned(ctx context.Context) {
+	attachments, err := s.repo.ListOrphaned()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list orphaned attachments")
+		return
+	}
+
+	removed := 0
+	for _, attachment := range attachments {
+		if err := s.store.Delete(ctx, attachment.StorageKey); err != nil {
+			log.Error().Err(err).Str("attachment_id", attachment.ID).Msg("Failed to delete stored file of orphaned attachment")
+			continue
+		}
+		if err := s.repo.DeleteOrphaned(attachment.ID); err != nil {
+			log.Error().Err(err).Str("attachment_id", attachment.ID).Msg("Failed to delete orphaned attachment")
+			continue
+		}
+		removed++
+	}
+	if removed > 0 {
+		log.Info().Int("attachments", removed).Msg("Removed attachments of deleted bills and payments")
+	}
+}
+
+// verifyParent ensures a bill, and the payment if given, exist and belong to the user
+func (s *AttachmentService) verifyParent(scopedDB *gorm.DB, billID string, paymentID *string) error {
+	if _, err := s.billRepo.Get(scopedDB, billID); err != nil {
+		return err
+	}
+	if paymentID == nil {
+		return nil
+	}
+	payment, err := s.paymentRepo.Get(scopedDB, *paymentID)
+	if err != nil {
+		return err
+	}
+	if payment.BillID != billID {
+		return repository.ErrPaymentNotFound
+	}
+	return nil
+}
+
+// setDownloadURL sets the tenant-scoped URL attachments are downloaded from
+func setDownloadURL(attachments ...*models.Attachment) {
+	for _, attachment := range attachments {
+		attachment.DownloadURL = "/api/v1/attachments/" + attachment.ID + "/download"
+	}
+}
+
+// cleanFileName strips any directory from an uploaded file name and bounds its length in bytes
+func cleanFileName(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if len(name) > maxFileNameLength {
+		// Cut on a rune boundary so the name stays valid UTF-8
+		cut := maxFileNameLength
+		for cut > 0 && !utf8.RuneStart(name[cut]) {
+			cut--
+		}
+		name = name[:cut]
+	}
+	return name
+}
@@ -0,0 +1,188 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cryptk/williams/internal/models"
+	"gorm.io/gorm"
+)
+
+// storedFiles returns the paths of the files in the attachment store, relative to its directory
+func (env *testEnv) storedFiles(t *testing.T) []string {
+	t.Helper()
+	var files []string
+	err := filepath.WalkDir(env.files, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(env.files, path)
+		files = append(files, filepath.ToSlash(rel))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// failAttachmentCreate makes saving attachments fail from now on
+func (env *testEnv) failAttachmentCreate(t *testing.T) {
+	t.Helper()
+	if err := env.db.Callback().Create().Before("gorm:create").Register("test:fail_attachment_create", func(tx *gorm.DB) {
+		if tx.Statement.Table == "attachments" {
+			tx.AddError(errors.New("disk full"))
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUploadRejectsInvalidFiles(t *testing.T) {
+	maxSize := int(newTestEnv(t).attachments.MaxSize())
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"larger than the maximum size", strings.Repeat("a", maxSize+1)},
+		{"empty", ""},
+		{"png image", "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"},
+		{"pdf document", "%PDF-1.7\n"},
+		{"html page", "<!DOCTYPE html><html><script>alert(1)</script></html>"},
+		{"executable", "MZ\x90\x00\x03\x00\x00\x00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			tn := env.newTenant(t, "alice")
+			before := env.storedFiles(t)
+
+			attachment := &models.Attachment{UserID: tn.userID, BillID: tn.bill.ID, FileName: "upload.txt"}
+			if err := env.attachments.Upload(context.Background(), tn.db, attachment, strings.NewReader(tt.content)); err == nil {
+				t.Fatal("file was accepted")
+			}
+			attachments, err := env.attachments.ListForBill(tn.db, tn.bill.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(attachments) != 1 {
+				t.Errorf("got %d attachments on the bill, want only the existing one", len(attachments))
+			}
+			if after := env.storedFiles(t); len(after) != len(before) {
+				t.Errorf("stored files: got %v, want %v", after, before)
+			}
+		})
+	}
+}
+
+func TestUploadAcceptsFileOfMaximumSize(t *testing.T) {
+	env := newTestEnv(t)
+	tn := env.newTenant(t, "alice")
+	content := strings.Repeat("a", int(env.attachments.MaxSize()))
+
+	attachment := &models.Attachment{UserID: tn.userID, BillID: tn.bill.ID, FileName: "notes.txt"}
+	if err := env.attachments.Upload(context.Background(), tn.db, attachment, strings.NewReader(content)); err != nil {
+		t.Fatal(err)
+	}
+	if attachment.Size != env.attachments.MaxSize() || attachment.ContentType != "text/plain" {
+		t.Errorf("got size %d and type %q, want %d and text/plain", attachment.Size, attachment.ContentType, env.attachments.MaxSize())
+	}
+}
+
+func TestUploadRemovesFileOfUnsavedAttachment(t *testing.T) {
+	env := newTestEnv(t)
+	tn := env.newTenant(t, "alice")
+	before := env.storedFiles(t)
+
+	env.failAttachmentCreate(t)
+	attachment := &models.Attachment{UserID: tn.userID, BillID: tn.bill.ID, FileName: "receipt.txt"}
+	if err := env.attachments.Upload(context.Background(), tn.db, attachment, strings.NewReader("receipt")); err == nil {
+		t.Fatal("upload succeeded")
+	}
+	if after := env.storedFiles(t); len(after) != len(before) {
+		t.Errorf("stored files: got %v, want %v", after, before)
+	}
+}
+
+func TestUploadCleansFileNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		want     string
+	}{
+		{"unix path", "../../etc/passwd", "passwd"},
+		{"windows path", `C:\Users\alice\receipt.txt`, "receipt.txt"},
+		{"surrounding spaces", "  receipt.txt ", "receipt.txt"},
+		{"empty", "", "attachment"},
+		{"directory only", "uploads/", "uploads"},
+		{"root", "/", "attachment"},
+		{"long name", strings.Repeat("a", 300) + ".txt", strings.Repeat("a", 255)},
+		// Each é is two bytes, so 255 bytes would end in the middle of one
+		{"long multi-byte name", strings.Repeat("é", 200), strings.Repeat("é", 127)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			tn := env.newTenant(t, "alice")
+
+			attachment := &models.Attachment{UserID: tn.userID, BillID: tn.bill.ID, FileName: tt.fileName}
+			if err := env.attachments.Upload(context.Background(), tn.db, attachment, strings.NewReader("receipt")); err != nil {
+				t.Fatal(err)
+			}
+			if attachment.FileName != tt.want {
+				t.Errorf("got %q, want %q", attachment.FileName, tt.want)
+			}
+			if len(attachment.FileName) > 255 || !utf8.ValidString(attachment.FileName) {
+				t.Errorf("stored name is not valid UTF-8 of at most 255 bytes: %q", attachment.FileName)
+			}
+		})
+	}
+}
+
+func TestCleanupRemovesAttachmentsOfDeletedRecords(t *testing.T) {
+	env := newTestEnv(t)
+	tn := env.newTenant(t, "alice")
+	other := env.newTenant(t, "bob")
+
+	// Deleting the payment row orphans its attachment, as the account deletion cascade does
+	if err := env.db.Exec("DELETE FROM payments WHERE id = ?", tn.payment.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	orphan := filepath.Join(env.files, filepath.FromSlash(tn.paymentAttachment.StorageKey))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	env.attachments.StartCleanup(ctx)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var count int64
+		if err := env.db.Model(&models.Attachment{}).Where("id = ?", tn.paymentAttachment.ID).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("orphaned attachment was not removed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := os.Stat(orphan); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("file of the orphaned attachment was not removed: %v", err)
+	}
+	files := env.storedFiles(t)
+	for _, attachment := range []*models.Attachment{tn.billAttachment, other.billAttachment, other.paymentAttachment} {
+		if _, err := os.Stat(filepath.Join(env.files, filepath.FromSlash(attachment.StorageKey))); err != nil {
+			t.Errorf("file of attachment %s was removed, stored files: %v", attachment.FileName, files)
+		}
+	}
+}
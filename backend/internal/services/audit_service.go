@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/cryptk/williams/internal/config"
+	"github.com/cryptk/williams/internal/encryption"
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// auditRetentionInterval is how often expired audit events are purged
+const auditRetentionInterval = time.Hour
+
+// auditIgnoredFields are excluded from before/after diffs: IDs are already on the event, timestamps
+// change on every write and the rest are computed, not stored
+var auditIgnoredFields = map[string]bool{
+	"id":                true,
+	"user_id":           true,
+	"created_at":        true,
+	"updated_at":        true,
+	"is_paid":           true,
+	"next_due_date":     true,
+	"last_paid_date":    true,
+	"nominal_due_date":  true,
+	"status":            true,
+	"tags":              true,
+	"payee":             true,
+	"balance":           true,
+	"current_statement": true,
+	"estimated_amount":  true,
+	"payment_status":    true,
+	"paid_amount":       true,
+	"late_fees":         true,
+	"late_fee_total":    true,
+}
+
+// auditRedacted replaces a sensitive value in an audit diff
+const auditRedacted = "[redacted]"
+
+// RequestMeta describes who made a request and from where. The API layer attaches it to the
+// request context; services read it back when recording audit events.
+type RequestMeta struct {
+	ActorID   string
+	IPAddress string
+	UserAgent string
+}
+
+type requestMetaKey struct{}
+
+// WithRequestMeta returns a copy of ctx carrying meta
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+// RequestMetaFrom returns the request metadata stored in ctx, or an empty value
+func RequestMetaFrom(ctx context.Context) RequestMeta {
+	if ctx == nil {
+		return RequestMeta{}
+	}
+	meta, _ := ctx.Value(requestMetaKey{}).(RequestMeta)
+	return meta
+}
+
+// AuditService records and retrieves the security audit log
+type AuditService struct {
+	repo      repository.AuditRepository
+	retention time.Duration
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(repo repository.AuditRepository, cfg *config.AuditConfig) *AuditService {
+	return &AuditService{
+		repo:      repo,
+		retention: cfg.Retention,
+	}
+}
+
+// =============================================================================
+// Recording Methods
+// =============================================================================
+
+// Record appends an audit event, filling in the actor, IP address and user agent from ctx.
+// Failures are logged rather than returned so auditing never blocks the action being audited.
+func (s *AuditService) Record(ctx context.Context, event *models.AuditEvent) {
+	meta := RequestMetaFrom(ctx)
+	if event.ActorID == nil && meta.ActorID != "" {
+		event.ActorID = &meta.ActorID
+	}
+	if event.UserID == nil {
+		event.UserID = event.ActorID
+	}
+	event.IPAddress = meta.IPAddress
+	event.UserAgent = meta.UserAgent
+
+	if err := s.repo.Create(event); err != nil {
+		log.Error().Err(err).Str("action", event.Action).Msg("Failed to record audit event")
+	}
+}
+
+// RecordScoped records an event for a tenant-scoped operation, taking request metadata from the
+// scoped DB's context
+func (s *AuditService) RecordScoped(scopedDB *gorm.DB, event *models.AuditEvent) {
+	s.Record(scopedDB.Statement.Context, event)
+}
+
+// =============================================================================
+// Query Methods
+// =============================================================================
+
+// List retrieves the current user's own audit history
+func (s *AuditService) List(scopedDB *gorm.DB, filter repository.AuditFilter) ([]*models.AuditEvent, error) {
+	return s.repo.List(scopedDB, filter)
+}
+
+// ListAll retrieves audit events across all users, for admins
+func (s *AuditService) ListAll(filter repository.AuditFilter) ([]*models.AuditEvent, error) {
+	return s.repo.ListAll(filter)
+}
+
+// =============================================================================
+// Retention Methods
+// =============================================================================
+
+// StartRetention purges expired events now and then periodically until ctx is cancelled
+func (s *AuditService) StartRetention(ctx context.Context) {
+	if s.retention <= 0 {
+		log.Info().Msg("Audit log retention disabled, events are kept forever")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(auditRetentionInterval)
+		defer ticker.Stop()
+		for {
+			s.purgeExpired()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// purgeExpired deletes events older than the retention period
+func (s *AuditService) purgeExpired() {
+	deleted, err := s.repo.DeleteBefore(time.Now().Add(-s.retention))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to purge expired audit events")
+		return
+	}
+	if deleted > 0 {
+		log.Info().Int64("deleted", deleted).Msg("Purged expired audit events")
+	}
+}
+
+// =============================================================================
+// Private Helper Methods
+// =============================================================================
+
+// auditDiff compares the JSON representations of two records and returns the fields that differ.
+// Either side may be nil, for creations and deletions.
+func auditDiff(before, after any) map[string]models.AuditChange {
+	beforeFields := auditFields(before)
+	afterFields := auditFields(after)
+
+	changes := make(map[string]models.AuditChange)
+	for field, oldValue := range beforeFields {
+		if newValue := afterFields[field]; !reflect.DeepEqual(oldValue, newValue) {
+			changes[field] = models.AuditChange{Before: oldValue, After: newValue}
+		}
+	}
+	for field, newValue := range afterFields {
+		if _, seen := beforeFields[field]; !seen && newValue != nil {
+			changes[field] = models.AuditChange{Before: nil, After: newValue}
+		}
+	}
+	redacted := auditRedactedFields(before)
+	for field := range auditRedactedFields(after) {
+		redacted[field] = true
+	}
+	for field, change := range changes {
+		if redacted[field] {
+			changes[field] = models.AuditChange{Before: redactAuditValue(change.Before), After: redactAuditValue(change.After)}
+		}
+	}
+	return changes
+}
+
+// auditFields flattens a record into its JSON fields, dropping ignored ones
+func auditFields(record any) map[string]any {
+	fields := map[string]any{}
+	if record == nil {
+		return fields
+	}
+	if v := reflect.ValueOf(record); v.Kind() == reflect.Pointer && v.IsNil() {
+		return fields
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fields
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fields
+	}
+	for field := range auditIgnoredFields {
+		delete(fields, field)
+	}
+	return fields
+}
+
+// auditRedactedFields returns the JSON names of a record's fields that are encrypted at rest: a change
+// to them is recorded, but not the values
+func auditRedactedFields(record any) map[string]bool {
+	fields := map[string]bool{}
+	if record == nil {
+		return fields
+	}
+	t := reflect.TypeOf(record)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+
+	for _, field := range reflect.VisibleFields(t) {
+		if !strings.Contains(field.Tag.Get("gorm"), "serializer:"+encryption.SerializerName) {
+			continue
+		}
+		if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+// redactAuditValue hides a sensitive value while still showing whether it was set
+func redactAuditValue(value any) any {
+	if value == nil || value == "" {
+		return value
+	}
+	return auditRedacted
+}
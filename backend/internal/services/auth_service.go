@@ -1,44 +1,92 @@
 package services
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
 	"time"
 
+	"github.com/cryptk/williams/internal/config"
 	"github.com/cryptk/williams/internal/models"
 	"github.com/cryptk/williams/internal/repository"
+	"github.com/cryptk/williams/pkg/ratelimit"
+	"github.com/cryptk/williams/pkg/totp"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// mfaAudience marks pre-auth tokens so they can never be used as session tokens
+	mfaAudience = "williams-mfa"
+	// preAuthTokenTTL is how long a user has to complete the second login step
+	preAuthTokenTTL = 5 * time.Minute
+	// totpSkew is the number of 30 second steps of clock drift tolerated in either direction
+	totpSkew = 1
+	// recoveryCodeCount is the number of recovery codes generated when 2FA is enabled
+	recoveryCodeCount = 10
+	// APITokenPrefix identifies personal access tokens in the Authorization header
+	APITokenPrefix = "wlm_"
+	// apiTokenLastUsedInterval limits how often last_used_at is written for busy tokens
+	apiTokenLastUsedInterval = time.Minute
+)
+
+var (
+	// ErrMFARequired is returned by Login when the password is correct but a second factor is needed
+	ErrMFARequired = errors.New("two-factor authentication required")
+	// ErrInvalidMFACode is returned when a TOTP or recovery code does not verify
+	ErrInvalidMFACode = errors.New("invalid two-factor authentication code")
+	// ErrIncorrectPassword is returned when the password re-entered for a sensitive change is wrong
+	ErrIncorrectPassword = errors.New("password is incorrect")
 )
 
 // AuthService handles authentication business logic
 type AuthService struct {
 	userRepo         repository.UserRepository
 	categoryRepo     repository.CategoryRepository
+	recoveryCodeRepo repository.RecoveryCodeRepository
+	apiTokenRepo     repository.APITokenRepository
 	jwtSecret        []byte
 	firstUserIsAdmin bool
+	totpIssuer       string
+	loginLimiter     *ratelimit.Limiter
+	loginLockout     *ratelimit.Lockout
+	audit            *AuditService
 }
 
 // JWTClaims represents the JWT claims structure
 type JWTClaims struct {
-	Roles []string `json:"roles"`
+	Roles        []string `json:"roles"`
+	TokenVersion int      `json:"ver"` // Must match the user's token_version, bumped to revoke all sessions
 	jwt.RegisteredClaims
 }
 
 // NewAuthService creates a new authentication service
-func NewAuthService(userRepo repository.UserRepository, categoryRepo repository.CategoryRepository, jwtSecret string, firstUserIsAdmin bool) *AuthService {
+func NewAuthService(userRepo repository.UserRepository, categoryRepo repository.CategoryRepository, recoveryCodeRepo repository.RecoveryCodeRepository, apiTokenRepo repository.APITokenRepository, limitStore ratelimit.Store, audit *AuditService, cfg *config.AuthConfig) *AuthService {
+	rl := cfg.RateLimit
 	return &AuthService{
 		userRepo:         userRepo,
 		categoryRepo:     categoryRepo,
-		jwtSecret:        []byte(jwtSecret),
-		firstUserIsAdmin: firstUserIsAdmin,
+		recoveryCodeRepo: recoveryCodeRepo,
+		apiTokenRepo:     apiTokenRepo,
+		jwtSecret:        []byte(cfg.JWTSecret),
+		firstUserIsAdmin: cfg.FirstUserIsAdmin,
+		totpIssuer:       cfg.TOTPIssuer,
+		loginLimiter:     ratelimit.NewLimiter(limitStore, "login", rl.UsernameRequests, rl.UsernameWindow),
+		loginLockout:     ratelimit.NewLockout(limitStore, "lockout", rl.LockoutThreshold, rl.LockoutDuration, rl.LockoutMaxDuration),
+		audit:            audit,
 	}
 }
 
 // Register creates a new user account
-func (s *AuthService) Register(req *models.RegisterRequest) (*models.User, error) {
+func (s *AuthService) Register(ctx context.Context, req *models.RegisterRequest) (*models.User, error) {
 	// Check if username already exists
 	if _, err := s.userRepo.GetByUsername(req.Username); err == nil {
 		return nil, errors.New("username already exists")
@@ -96,28 +144,66 @@ func (s *AuthService) Register(req *models.RegisterRequest) (*models.User, error
 	}
 
 	// Create default categories for the new user
-	if err := s.categoryRepo.CreateDefaults(user.ID); err != nil {
+	s.createDefaultCategories(user.ID)
+
+	s.audit.Record(ctx, &models.AuditEvent{
+		UserID:     &user.ID,
+		ActorID:    &user.ID,
+		Action:     models.AuditActionRegister,
+		EntityType: "user",
+		EntityID:   user.ID,
+	})
+
+	return user, nil
+}
+
+// createDefaultCategories creates the starter categories for a new user
+func (s *AuthService) createDefaultCategories(userID string) {
+	if err := s.categoryRepo.CreateDefaults(userID); err != nil {
 		// Log the error but don't fail registration
 		// The user can create categories manually if this fails
-		log.Warn().Err(err).Str("user_id", user.ID).Msg("Failed to create default categories for user")
+		log.Warn().Err(err).Str("user_id", userID).Msg("Failed to create default categories for user")
 	}
-
-	return user, nil
 }
 
-// Login authenticates a user and returns a JWT token
-func (s *AuthService) Login(req *models.LoginRequest) (string, *models.User, error) {
+// Login authenticates a user and returns a JWT token.
+// If the user has 2FA enabled, the returned token is a short-lived pre-auth token and the error is
+// ErrMFARequired; the caller must exchange it via VerifyMFA to obtain a session token.
+func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (string, *models.User, error) {
+	// Throttle and lock out by username, whether or not the account exists, so that
+	// limits cannot be used to discover valid usernames
+	limitKey := strings.ToLower(req.Username)
+	if err := s.loginLimiter.Allow(limitKey); err != nil {
+		return "", nil, err
+	}
+	if err := s.loginLockout.Check(limitKey); err != nil {
+		return "", nil, err
+	}
+
 	// Get user by username
 	user, err := s.userRepo.GetByUsername(req.Username)
 	if err != nil {
+		s.recordLoginFailure(ctx, limitKey, nil, "unknown username")
 		return "", nil, errors.New("invalid username or password")
 	}
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		s.recordLoginFailure(ctx, limitKey, user, "invalid password")
 		return "", nil, errors.New("invalid username or password")
 	}
 
+	// Require the second factor before issuing a session token
+	if user.TOTPEnabled {
+		preAuthToken, err := s.generatePreAuthToken(user)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to generate pre-auth token: %w", err)
+		}
+		return preAuthToken, user, ErrMFARequired
+	}
+
+	s.recordLoginSuccess(ctx, limitKey, user, "password")
+
 	// Generate JWT token
 	token, err := s.generateToken(user)
 	if err != nil {
@@ -127,6 +213,67 @@ func (s *AuthService) Login(req *models.LoginRequest) (string, *models.User, err
 	return token, user, nil
 }
 
+// recordLoginFailure audits a failed login attempt, counts it towards the lockout and locks the
+// username once the threshold is reached. user is nil when the username does not exist.
+func (s *AuthService) recordLoginFailure(ctx context.Context, limitKey string, user *models.User, reason string) {
+	event := &models.AuditEvent{
+		Action:     models.AuditActionLoginFailed,
+		EntityType: "user",
+		Details:    fmt.Sprintf("username=%s reason=%s", limitKey, reason),
+	}
+	if user != nil {
+		event.UserID = &user.ID
+		event.EntityID = user.ID
+	}
+	s.audit.Record(ctx, event)
+
+	lockedFor, err := s.loginLockout.RecordFailure(limitKey)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to record failed login attempt")
+		return
+	}
+	if lockedFor > 0 {
+		log.Warn().Str("username", limitKey).Dur("duration", lockedFor).Msg("Account locked after repeated failed login attempts")
+		s.audit.Record(ctx, &models.AuditEvent{
+			UserID:     event.UserID,
+			Action:     models.AuditActionLockout,
+			EntityType: "user",
+			EntityID:   event.EntityID,
+			Details:    fmt.Sprintf("username=%s duration=%s", limitKey, lockedFor),
+		})
+	}
+}
+
+// recordLoginSuccess audits a completed login and clears the failure history for the username
+func (s *AuthService) recordLoginSuccess(ctx context.Context, limitKey string, user *models.User, method string) {
+	s.audit.Record(ctx, &models.AuditEvent{
+		UserID:     &user.ID,
+		ActorID:    &user.ID,
+		Action:     models.AuditActionLogin,
+		EntityType: "user",
+		EntityID:   user.ID,
+		Details:    "method=" + method,
+	})
+
+	if err := s.loginLockout.RecordSuccess(limitKey); err != nil {
+		log.Error().Err(err).Msg("Failed to reset failed login attempts")
+	}
+}
+
+// CheckPassword re-checks the password of a logged in user before a sensitive change. Wrong passwords
+// count towards the same lockout as failed logins, so a stolen session cannot be used to guess it.
+func (s *AuthService) CheckPassword(ctx context.Context, user *models.User, password string) error {
+	limitKey := strings.ToLower(user.Username)
+	if err := s.loginLockout.Check(limitKey); err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		s.recordLoginFailure(ctx, limitKey, user, "invalid password confirmation")
+		return ErrIncorrectPassword
+	}
+	return nil
+}
+
 // ValidateToken validates a JWT token and returns the user ID
 func (s *AuthService) ValidateToken(tokenString string) (*JWTClaims, error) {
 	claims := &JWTClaims{}
@@ -163,6 +310,12 @@ func (s *AuthService) ValidateToken(tokenString string) (*JWTClaims, error) {
 		return nil, errors.New("invalid token")
 	}
 
+	// Session tokens never carry an audience. Audience-bound tokens (2FA pre-auth, SSO flow state)
+	// are signed with the same secret but must not grant API access.
+	if len(claims.Audience) > 0 {
+		return nil, errors.New("token is not a session token")
+	}
+
 	// Token is valid, return the user ID from claims
 	// In the future we might want to return more information from the claims
 	// such as roles or permissions.
@@ -173,7 +326,8 @@ func (s *AuthService) ValidateToken(tokenString string) (*JWTClaims, error) {
 // generateToken creates a JWT token for a user
 func (s *AuthService) generateToken(user *models.User) (string, error) {
 	claims := JWTClaims{
-		Roles: user.Roles,
+		Roles:        user.Roles,
+		TokenVersion: user.TokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   user.ID,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24 * 7)), // 7 days
@@ -189,3 +343,435 @@ func (s *AuthService) generateToken(user *models.User) (string, error) {
 func (s *AuthService) GetUserByID(id string) (*models.User, error) {
 	return s.userRepo.GetByID(id)
 }
+
+// =============================================================================
+// API Token Methods
+// =============================================================================
+
+// CreateAPIToken creates a personal access token and returns its plaintext value, which is never stored
+func (s *AuthService) CreateAPIToken(scopedDB *gorm.DB, userID string, req *models.CreateAPITokenRequest) (string, *models.APIToken, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return "", nil, errors.New("expires_at must be in the future")
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return "", nil, err
+	}
+	plaintext := APITokenPrefix + secret
+
+	token := &models.APIToken{
+		UserID:      userID,
+		Name:        req.Name,
+		TokenHash:   hashToken(plaintext),
+		TokenPrefix: plaintext[:len(APITokenPrefix)+6],
+		Scope:       req.Scope,
+		ExpiresAt:   req.ExpiresAt,
+	}
+	if err := s.apiTokenRepo.Create(scopedDB, token); err != nil {
+		return "", nil, fmt.Errorf("failed to create api token: %w", err)
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionAPITokenCreate,
+		EntityType: "api_token",
+		EntityID:   token.ID,
+		Details:    fmt.Sprintf("name=%s scope=%s", token.Name, token.Scope),
+	})
+
+	return plaintext, token, nil
+}
+
+// ListAPITokens retrieves all personal access tokens
+func (s *AuthService) ListAPITokens(scopedDB *gorm.DB) ([]*models.APIToken, error) {
+	return s.apiTokenRepo.List(scopedDB)
+}
+
+// RevokeAPIToken deletes a personal access token
+func (s *AuthService) RevokeAPIToken(scopedDB *gorm.DB, id string) error {
+	if err := s.apiTokenRepo.Delete(scopedDB, id); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionAPITokenRevoke,
+		EntityType: "api_token",
+		EntityID:   id,
+	})
+	return nil
+}
+
+// ValidateAPIToken checks a personal access token and returns it with its owner
+func (s *AuthService) ValidateAPIToken(plaintext string) (*models.APIToken, *models.User, error) {
+	token, err := s.apiTokenRepo.GetByHash(hashToken(plaintext))
+	if err != nil {
+		return nil, nil, errors.New("invalid api token")
+	}
+
+	now := time.Now()
+	if token.ExpiresAt != nil && now.After(*token.ExpiresAt) {
+		return nil, nil, errors.New("api token has expired")
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Only write last_used_at periodically so busy scripts don't cause a write per request
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenLastUsedInterval {
+		if err := s.apiTokenRepo.TouchLastUsed(token.ID, now); err != nil {
+			log.Warn().Err(err).Str("token_id", token.ID).Msg("Failed to update api token last used time")
+		}
+		token.LastUsedAt = &now
+	}
+
+	return token, user, nil
+}
+
+// hashToken hashes a random token for storage and lookup. It is used for personal access tokens and for
+// the single-use tokens emailed for password resets and email verification.
+// Tokens are 256-bit random values, so a fast unsalted hash is sufficient and allows indexed lookup.
+func hashToken(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+// =============================================================================
+// Two-Factor Authentication Methods
+// =============================================================================
+
+// VerifyMFA completes a 2FA login by exchanging a pre-auth token and a TOTP or recovery code for a JWT token
+func (s *AuthService) VerifyMFA(ctx context.Context, req *models.MFAVerifyRequest) (string, *models.User, error) {
+	userID, err := s.validatePreAuthToken(req.PreAuthToken)
+	if err != nil {
+		return "", nil, errors.New("invalid or expired pre-auth token")
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return "", nil, errors.New("invalid or expired pre-auth token")
+	}
+	if !user.TOTPEnabled {
+		return "", nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	// Second factor guesses count towards the same lockout as password guesses
+	limitKey := strings.ToLower(user.Username)
+	if err := s.loginLockout.Check(limitKey); err != nil {
+		return "", nil, err
+	}
+
+	var method string
+	switch {
+	case req.RecoveryCode != "":
+		ok, err := s.recoveryCodeRepo.Consume(user.ID, hashRecoveryCode(req.RecoveryCode))
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to check recovery code: %w", err)
+		}
+		if !ok {
+			s.recordLoginFailure(ctx, limitKey, user, "invalid recovery code")
+			return "", nil, ErrInvalidMFACode
+		}
+		log.Info().Str("user_id", user.ID).Msg("Recovery code used for login")
+		method = "recovery_code"
+	case req.Code != "":
+		if err := s.checkTOTPCode(user, req.Code); err != nil {
+			if errors.Is(err, ErrInvalidMFACode) {
+				s.recordLoginFailure(ctx, limitKey, user, "invalid TOTP code")
+			}
+			return "", nil, err
+		}
+		method = "totp"
+	default:
+		return "", nil, errors.New("code or recovery_code is required")
+	}
+
+	s.recordLoginSuccess(ctx, limitKey, user, method)
+
+	token, err := s.generateToken(user)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return token, user, nil
+}
+
+// SetupTOTP generates a new TOTP secret for the user and returns the enrollment details.
+// 2FA is not enforced until the user confirms the secret with EnableTOTP.
+func (s *AuthService) SetupTOTP(userID string) (*models.TOTPSetupResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("failed to store TOTP secret: %w", err)
+	}
+
+	return &models.TOTPSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(secret, s.totpIssuer, user.Username),
+	}, nil
+}
+
+// EnableTOTP confirms enrollment with a code from the authenticator app and returns the recovery codes
+func (s *AuthService) EnableTOTP(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, errors.New("two-factor authentication setup has not been started")
+	}
+
+	if err := s.checkTOTPCode(user, code); err != nil {
+		return nil, err
+	}
+
+	// Reload so the last accepted step recorded by checkTOTPCode is preserved
+	user, err = s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	user.TOTPEnabled = true
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	codes, err := s.regenerateRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("user_id", user.ID).Msg("Two-factor authentication enabled")
+	s.audit.Record(ctx, &models.AuditEvent{
+		UserID:     &user.ID,
+		Action:     models.AuditActionTOTPEnable,
+		EntityType: "user",
+		EntityID:   user.ID,
+	})
+	return codes, nil
+}
+
+// DisableTOTP turns off 2FA after re-checking the password and a current code
+func (s *AuthService) DisableTOTP(ctx context.Context, userID string, req *models.TOTPDisableRequest) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := s.CheckPassword(ctx, user, req.Password); err != nil {
+		return err
+	}
+	if err := s.confirmTOTPCode(ctx, user, req.Code); err != nil {
+		return err
+	}
+
+	if err := s.clearTOTP(user.ID); err != nil {
+		return err
+	}
+
+	log.Info().Str("user_id", user.ID).Msg("Two-factor authentication disabled")
+	s.audit.Record(ctx, &models.AuditEvent{
+		UserID:     &user.ID,
+		Action:     models.AuditActionTOTPDisable,
+		EntityType: "user",
+		EntityID:   user.ID,
+	})
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after verifying a current TOTP code
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+	if err := s.confirmTOTPCode(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.regenerateRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("user_id", user.ID).Msg("Recovery codes regenerated")
+	s.audit.Record(ctx, &models.AuditEvent{
+		UserID:     &user.ID,
+		Action:     models.AuditActionTOTPRecoveryCodes,
+		EntityType: "user",
+		EntityID:   user.ID,
+	})
+	return codes, nil
+}
+
+// ResetTOTP removes 2FA from a user account. Intended for admins helping a locked-out user.
+func (s *AuthService) ResetTOTP(ctx context.Context, userID string) error {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return err
+	}
+	if err := s.clearTOTP(userID); err != nil {
+		return err
+	}
+
+	// The actor is the admin from the request context; the event belongs to the affected user
+	s.audit.Record(ctx, &models.AuditEvent{
+		UserID:     &userID,
+		Action:     models.AuditActionTOTPReset,
+		EntityType: "user",
+		EntityID:   userID,
+	})
+	return nil
+}
+
+// checkTOTPCode validates a TOTP code and records its time step so it cannot be replayed
+func (s *AuthService) checkTOTPCode(user *models.User, code string) error {
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now(), totpSkew)
+	if !ok || step <= user.TOTPLastStep {
+		return ErrInvalidMFACode
+	}
+
+	// Conditional update so two concurrent requests cannot both accept the same code
+	accepted, err := s.userRepo.AdvanceTOTPStep(user.ID, step)
+	if err != nil {
+		return fmt.Errorf("failed to record TOTP step: %w", err)
+	}
+	if !accepted {
+		return ErrInvalidMFACode
+	}
+	user.TOTPLastStep = step
+	return nil
+}
+
+// confirmTOTPCode re-checks a TOTP code of a logged in user before a 2FA change. Wrong codes count
+// towards the same lockout as failed logins, so a stolen session cannot be used to guess them.
+func (s *AuthService) confirmTOTPCode(ctx context.Context, user *models.User, code string) error {
+	limitKey := strings.ToLower(user.Username)
+	if err := s.loginLockout.Check(limitKey); err != nil {
+		return err
+	}
+	if err := s.checkTOTPCode(user, code); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			s.recordLoginFailure(ctx, limitKey, user, "invalid TOTP confirmation")
+		}
+		return err
+	}
+	return nil
+}
+
+// clearTOTP disables 2FA, wipes the secret and deletes all recovery codes
+func (s *AuthService) clearTOTP(userID string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPLastStep = 0
+	if err := s.userRepo.Update(user); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+	if err := s.recoveryCodeRepo.DeleteAll(userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	return nil
+}
+
+// regenerateRecoveryCodes creates a fresh set of recovery codes, storing only their hashes
+func (s *AuthService) regenerateRecoveryCodes(userID string) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	if err := s.recoveryCodeRepo.Replace(userID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+// generatePreAuthToken creates a short-lived token proving the password step of a 2FA login
+func (s *AuthService) generatePreAuthToken(user *models.User) (string, error) {
+	claims := jwt.RegisteredClaims{
+		Subject:   user.ID,
+		Audience:  jwt.ClaimStrings{mfaAudience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(preAuthTokenTTL)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.jwtSecret)
+}
+
+// validatePreAuthToken validates a pre-auth token and returns the user ID
+func (s *AuthService) validatePreAuthToken(tokenString string) (string, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(
+		tokenString,
+		claims,
+		func(token *jwt.Token) (any, error) { return s.jwtSecret, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(mfaAudience),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(5*time.Second),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return "", err
+	}
+	return claims.Subject, nil
+}
+
+// randomToken returns 32 random bytes encoded as URL-safe base64
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// generateRecoveryCode returns a random recovery code formatted as xxxxx-xxxxx
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 5)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+	code := hex.EncodeToString(buf)
+	return code[:5] + "-" + code[5:], nil
+}
+
+// hashRecoveryCode normalizes and hashes a recovery code.
+// Codes carry 40 bits of randomness and are single use, so a fast hash is sufficient.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,154 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/pkg/ratelimit"
+	"github.com/cryptk/williams/pkg/totp"
+)
+
+// enableTOTP enrolls the user in 2FA and returns the TOTP secret and recovery codes
+func (env *testEnv) enableTOTP(t *testing.T, user *models.User) (string, []string) {
+	t.Helper()
+	setup, err := env.auth.SetupTOTP(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes, err := env.auth.EnableTOTP(context.Background(), user.ID, totpCode(t, setup.Secret, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return setup.Secret, codes
+}
+
+// totpCode returns the code for the current time step moved by offset. Each accepted code must be for
+// a later step than the last one, so tests move forward within the allowed skew.
+func totpCode(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	code, err := totp.Code(secret, totp.Step(time.Now())+offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// wrongTOTPCode returns a code that does not verify for the secret at the current time
+func wrongTOTPCode(t *testing.T, secret string) string {
+	t.Helper()
+	for i := range 1000 {
+		code := fmt.Sprintf("%06d", i)
+		if _, ok := totp.Validate(secret, code, time.Now(), 1); !ok {
+			return code
+		}
+	}
+	t.Fatal("no wrong code found")
+	return ""
+}
+
+func TestTOTPSecretIsEncryptedAtRest(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.register(t, "alice")
+	secret, _ := env.enableTOTP(t, user)
+
+	var stored string
+	if err := env.db.Table("users").Select("totp_secret").Where("id = ?", user.ID).Scan(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored == secret || !strings.HasPrefix(stored, "enc:") {
+		t.Errorf("TOTP secret is stored in plaintext: %q", stored)
+	}
+
+	reloaded, err := env.auth.GetUserByID(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.TOTPSecret != secret {
+		t.Error("TOTP secret does not decrypt to the enrolled secret")
+	}
+}
+
+func TestRegenerateRecoveryCodes(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.register(t, "alice")
+	secret, oldCodes := env.enableTOTP(t, user)
+
+	codes, err := env.auth.RegenerateRecoveryCodes(context.Background(), user.ID, totpCode(t, secret, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) == 0 || codes[0] == oldCodes[0] {
+		t.Errorf("recovery codes were not replaced: %v", codes)
+	}
+
+	var count int64
+	if err := env.db.Model(&models.AuditEvent{}).
+		Where("user_id = ? AND action = ?", user.ID, models.AuditActionTOTPRecoveryCodes).
+		Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("got %d recovery code audit events, want 1", count)
+	}
+}
+
+func TestTOTPConfirmationsCountTowardsLockout(t *testing.T) {
+	tests := []struct {
+		name    string
+		confirm func(t *testing.T, env *testEnv, user *models.User, secret string) error
+	}{
+		{"disable with wrong password", func(t *testing.T, env *testEnv, user *models.User, secret string) error {
+			return env.auth.DisableTOTP(context.Background(), user.ID, &models.TOTPDisableRequest{
+				Password: "wrong-password",
+				Code:     totpCode(t, secret, 1),
+			})
+		}},
+		{"disable with wrong code", func(t *testing.T, env *testEnv, user *models.User, secret string) error {
+			return env.auth.DisableTOTP(context.Background(), user.ID, &models.TOTPDisableRequest{
+				Password: testPassword,
+				Code:     wrongTOTPCode(t, secret),
+			})
+		}},
+		{"regenerate recovery codes with wrong code", func(t *testing.T, env *testEnv, user *models.User, secret string) error {
+			_, err := env.auth.RegenerateRecoveryCodes(context.Background(), user.ID, wrongTOTPCode(t, secret))
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			user := env.register(t, "alice")
+			secret, _ := env.enableTOTP(t, user)
+
+			threshold := 5 // Auth.RateLimit.LockoutThreshold of the test environment
+			for i := range threshold {
+				var limitErr *ratelimit.LimitError
+				if err := tt.confirm(t, env, user, secret); err == nil || errors.As(err, &limitErr) {
+					t.Fatalf("attempt %d: got %v, want a rejected confirmation", i+1, err)
+				}
+			}
+
+			// The account is now locked, for further confirmations and for logging in
+			var limitErr *ratelimit.LimitError
+			if err := tt.confirm(t, env, user, secret); !errors.As(err, &limitErr) {
+				t.Errorf("after %d failures: got %v, want a lockout", threshold, err)
+			}
+			_, _, err := env.auth.Login(context.Background(), &models.LoginRequest{Username: "alice", Password: testPassword})
+			if !errors.As(err, &limitErr) {
+				t.Errorf("login after %d failures: got %v, want a lockout", threshold, err)
+			}
+			stored, err := env.auth.GetUserByID(user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !stored.TOTPEnabled {
+				t.Error("two-factor authentication was disabled")
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/cryptk/williams/internal/repository"
+	"github.com/cryptk/williams/pkg/utils"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// AutopayService periodically records payments for autopay bills that have come due
+type AutopayService struct {
+	billService *BillService
+	billRepo    repository.BillRepository
+	preferences *PreferenceService
+	tenantDB    func(userID string) *gorm.DB // Returns a DB scoped to one user, as the API middleware does
+	interval    time.Duration
+}
+
+// NewAutopayService creates a new autopay service
+func NewAutopayService(billService *BillService, billRepo repository.BillRepository, preferences *PreferenceService, tenantDB func(userID string) *gorm.DB, interval time.Duration) *AutopayService {
+	return &AutopayService{
+		billService: billService,
+		billRepo:    billRepo,
+		preferences: preferences,
+		tenantDB:    tenantDB,
+		interval:    interval,
+	}
+}
+
+// Start runs autopay now and then periodically until ctx is cancelled
+func (s *AutopayService) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		log.Info().Msg("Autopay disabled, payments for autopay bills are not recorded automatically")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			s.run(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// run records due autopay payments for every user with autopay bills. A failure for one user
+// does not stop the others.
+func (s *AutopayService) run(ctx context.Context) {
+	userIDs, err := s.billRepo.ListAutopayUserIDs()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list users with autopay bills")
+		return
+	}
+
+	now := utils.NowInAppTimezone()
+	total := 0
+	for _, userID := range userIDs {
+		// Due dates are calculated in the user's timezone, as they are for API requests
+		scopedDB := s.tenantDB(userID).WithContext(ctx)
+		scopedDB = scopedDB.WithContext(utils.WithLocation(ctx, s.preferences.Location(scopedDB)))
+		recorded, err := s.billService.RunAutopay(scopedDB, now)
+		if err != nil {
+			log.Error().Err(err).Str("user_id", userID).Msg("Failed to run autopay")
+		}
+		total += recorded
+	}
+
+	if total > 0 {
+		log.Info().Int("payments", total).Msg("Recorded autopay payments")
+	}
+}
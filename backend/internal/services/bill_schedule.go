@@ -0,0 +1,184 @@
+package services
+
+import (
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/pkg/holidays"
+	"github.com/cryptk/williams/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// maxScheduleSteps bounds how many occurrences are walked when resolving a bill's schedule
+const maxScheduleSteps = 10000
+
+// maxRollSearchDays is how far from a payment date to look for the scheduled due date it was rolled from
+const maxRollSearchDays = 10
+
+// billSchedules holds the pauses, skips and holiday calendars of every bill of a tenant
+type billSchedules struct {
+	pauses       map[string][]*models.BillPause
+	skips        map[string][]*models.BillSkip
+	holidays     *HolidayService
+	userCalendar *holidays.Calendar
+	loc          *time.Location // The tenant's timezone
+}
+
+// forBill returns the schedule of a single bill
+func (bs *billSchedules) forBill(bill *models.Bill) *billSchedule {
+	return &billSchedule{
+		bill:      bill,
+		pauses:    bs.pauses[bill.ID],
+		skips:     bs.skips[bill.ID],
+		calendars: bs.holidays.calendarsFor(bill, bs.userCalendar),
+		loc:       bs.loc,
+	}
+}
+
+// billSchedule combines a bill with its end conditions, pauses, skipped occurrences and the holiday
+// calendars its due dates are rolled by. Dates are compared by calendar day in the user's timezone.
+type billSchedule struct {
+	bill      *models.Bill
+	pauses    []*models.BillPause
+	skips     []*models.BillSkip
+	calendars []*holidays.Calendar
+	loc       *time.Location
+}
+
+// resolve moves nextDue past skipped and paused occurrences and applies the bill's end conditions.
+// It returns the bill status, the resulting next due date (nil if there is none) and how many
+// occurrences were billed before it. Skipped and paused occurrences do not count as billed.
+func (sc *billSchedule) resolve(nextDue time.Time) (string, *time.Time, int) {
+	status := models.BillStatusActive
+	if sc.pauseOn(utils.NowInAppTimezone()) != nil {
+		status = models.BillStatusPaused
+	}
+
+	// Counting billed occurrences means walking the schedule from the start, so only do it when needed
+	billed := 0
+	if sc.bill.MaxOccurrences != nil {
+		due := sc.firstDueDate()
+		for i := 0; i < maxScheduleSteps && due.Before(nextDue); i++ {
+			if !sc.interrupted(due) {
+				billed++
+			}
+			due = sc.next(due)
+		}
+	}
+
+	due := nextDue
+	for i := 0; i < maxScheduleSteps; i++ {
+		if sc.ended(due, billed) {
+			return models.BillStatusEnded, nil, billed
+		}
+		pause := sc.pauseOn(due)
+		if pause != nil && pause.EndDate == nil {
+			// Paused until further notice
+			return models.BillStatusPaused, nil, billed
+		}
+		if pause == nil && !sc.skipped(due) {
+			return status, &due, billed
+		}
+		due = sc.next(due)
+	}
+	return status, &due, billed
+}
+
+// ended reports whether an occurrence falls after the bill's end date or maximum occurrences
+func (sc *billSchedule) ended(due time.Time, billed int) bool {
+	if sc.bill.EndDate != nil && calendarDay(due, sc.loc).After(calendarDay(*sc.bill.EndDate, sc.loc)) {
+		return true
+	}
+	return sc.bill.MaxOccurrences != nil && billed >= *sc.bill.MaxOccurrences
+}
+
+// interrupted reports whether an occurrence is skipped or falls within a pause
+func (sc *billSchedule) interrupted(due time.Time) bool {
+	return sc.skipped(due) || sc.pauseOn(due) != nil
+}
+
+// skipped reports whether the occurrence on due has been skipped. Skips may name either the
+// scheduled due date or the business day it is rolled to.
+func (sc *billSchedule) skipped(due time.Time) bool {
+	day := calendarDay(due, sc.loc)
+	rolledDay := calendarDay(sc.rolled(due), sc.loc)
+	for _, skip := range sc.skips {
+		skipDay := calendarDay(skip.DueDate, sc.loc)
+		if skipDay.Equal(day) || skipDay.Equal(rolledDay) {
+			return true
+		}
+	}
+	return false
+}
+
+// rolled moves a due date off weekends and holidays according to the bill's business day roll
+func (sc *billSchedule) rolled(due time.Time) time.Time {
+	if sc.bill == nil {
+		return due
+	}
+	return holidays.Roll(due, sc.bill.BusinessDayRoll, sc.calendars...)
+}
+
+// scheduledDate maps a payment date back to the scheduled due date of a fixed-date bill when the
+// payment was made on the rolled business day. Otherwise the payment date is returned unchanged.
+//
+// Without this, a due date rolled into the previous month (or the next) would make the next due
+// date repeat (or skip) an occurrence.
+func (sc *billSchedule) scheduledDate(paymentDate time.Time) time.Time {
+	if sc.bill.RecurrenceType != "fixed_date" || sc.bill.BusinessDayRoll == holidays.RollNone || sc.bill.BusinessDayRoll == "" {
+		return paymentDate
+	}
+	paidDay := calendarDay(paymentDate, sc.loc)
+	latest := paymentDate.AddDate(0, 0, maxRollSearchDays)
+	for due := utils.CalculateNextDueDate(sc.bill.RecurrenceDays, paymentDate.AddDate(0, 0, -maxRollSearchDays), sc.loc); !due.After(latest); {
+		if calendarDay(sc.rolled(due), sc.loc).Equal(paidDay) {
+			return due
+		}
+		due = utils.CalculateNextDueDateAfterPayment(sc.bill.RecurrenceDays, due, sc.loc)
+	}
+	return paymentDate
+}
+
+// pauseOn returns the pause covering t, if any
+func (sc *billSchedule) pauseOn(t time.Time) *models.BillPause {
+	day := calendarDay(t, sc.loc)
+	for _, pause := range sc.pauses {
+		if day.Before(calendarDay(pause.StartDate, sc.loc)) {
+			continue
+		}
+		if pause.EndDate == nil || !day.After(calendarDay(*pause.EndDate, sc.loc)) {
+			return pause
+		}
+	}
+	return nil
+}
+
+// firstDueDate returns the first due date of the bill, before any payments were made
+func (sc *billSchedule) firstDueDate() time.Time {
+	referenceDate := sc.bill.CreatedAt
+	if sc.bill.StartDate != nil {
+		referenceDate = *sc.bill.StartDate
+	}
+	if sc.bill.RecurrenceType == "interval" {
+		return utils.CalculateNextDueDateInterval(sc.bill.RecurrenceDays, referenceDate, sc.loc)
+	}
+	return utils.CalculateNextDueDate(sc.bill.RecurrenceDays, referenceDate, sc.loc)
+}
+
+// next returns the occurrence following due using the bill's current recurrence
+func (sc *billSchedule) next(due time.Time) time.Time {
+	next, _ := nextOccurrence(newBillVersion(sc.bill, sc.bill.CreatedAt), due, sc.loc)
+	return next
+}
+
+// calendarDay truncates t to the start of its date in loc
+func calendarDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// userLocation returns the timezone of the user a scoped DB belongs to, which the API middleware and
+// background jobs attach to its context
+func userLocation(scopedDB *gorm.DB) *time.Location {
+	return utils.LocationFromContext(scopedDB.Statement.Context)
+}
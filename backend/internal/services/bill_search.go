@@ -0,0 +1,254 @@
+package services
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Statuses a bill listing can be filtered by besides the BillStatus constants. They split active
+// bills by whether the current occurrence is paid.
+const (
+	BillFilterStatusPaid   = "paid"
+	BillFilterStatusUnpaid = "unpaid"
+)
+
+// BillSortDueDate sorts bills by next_due_date, which is computed rather than stored
+const BillSortDueDate = "due_date"
+
+// BillListFilter extends the stored-field filter of the repository with the computed status and next
+// due date, and a text search
+type BillListFilter struct {
+	repository.BillFilter
+	Query     string     // Matches the name or notes anywhere, ignoring case. Notes are encrypted, so this runs in memory.
+	Status    string     // One of the BillStatus constants, paid or unpaid
+	DueAfter  *time.Time // Inclusive bounds on the calendar day of next_due_date
+	DueBefore *time.Time
+}
+
+// computed reports whether the filter depends on fields that only exist after enrichment
+func (f *BillListFilter) computed() bool {
+	return f.Query != "" || f.Status != "" || f.DueAfter != nil || f.DueBefore != nil || f.Sort.Field == BillSortDueDate
+}
+
+// matchesQuery reports whether a bill's name or notes contain the search text
+func (f *BillListFilter) matchesQuery(bill *models.Bill) bool {
+	query := strings.ToLower(f.Query)
+	return strings.Contains(strings.ToLower(bill.Name), query) || strings.Contains(strings.ToLower(bill.Notes), query)
+}
+
+// matches reports whether an enriched bill passes the computed filters, comparing due dates by
+// calendar day in loc
+func (f *BillListFilter) matches(bill *models.Bill, loc *time.Location) bool {
+	switch f.Status {
+	case "":
+	case BillFilterStatusPaid, BillFilterStatusUnpaid:
+		if bill.Status != models.BillStatusActive || bill.IsPaid != (f.Status == BillFilterStatusPaid) {
+			return false
+		}
+	default:
+		if bill.Status != f.Status {
+			return false
+		}
+	}
+	if f.DueAfter == nil && f.DueBefore == nil {
+		return true
+	}
+	if bill.NextDueDate == nil {
+		return false
+	}
+	due := calendarDay(*bill.NextDueDate, loc)
+	if f.DueAfter != nil && due.Before(calendarDay(*f.DueAfter, loc)) {
+		return false
+	}
+	return f.DueBefore == nil || !due.After(calendarDay(*f.DueBefore, loc))
+}
+
+// Search retrieves a page of bills. Filters and sorting on stored fields run in SQL so only the page
+// is enriched; searching, filtering by status or due date, or sorting by due date loads every bill
+// matching the stored-field filters first.
+func (s *BillService) Search(scopedDB *gorm.DB, filter BillListFilter) (*models.BillListResponse, error) {
+	if filter.computed() {
+		return s.searchComputed(scopedDB, filter)
+	}
+
+	// Fetch one extra bill to tell whether there is another page
+	query := filter.BillFilter
+	if query.Limit > 0 {
+		query.Limit++
+	}
+	bills, total, err := s.repo.Search(scopedDB, query)
+	if err != nil {
+		return nil, err
+	}
+	more := filter.Limit > 0 && len(bills) > filter.Limit
+	if more {
+		bills = bills[:filter.Limit]
+	}
+	if bills, err = s.enrichWithPaymentStatus(scopedDB, bills); err != nil {
+		return nil, err
+	}
+
+	response := &models.BillListResponse{Bills: bills, Total: total}
+	if more {
+		response.NextCursor = billCursor(bills[len(bills)-1], filter.Sort).Encode()
+	}
+	return response, nil
+}
+
+// searchComputed filters, sorts and pages enriched bills in memory
+func (s *BillService) searchComputed(scopedDB *gorm.DB, filter BillListFilter) (*models.BillListResponse, error) {
+	query := filter.BillFilter
+	query.Sort, query.Cursor, query.Limit = repository.Sort{}, nil, 0
+	bills, _, err := s.repo.Search(scopedDB, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// The search text needs no enrichment, so only the bills it matches are enriched
+	if filter.Query != "" {
+		bills = slices.DeleteFunc(bills, func(bill *models.Bill) bool {
+			return !filter.matchesQuery(bill)
+		})
+	}
+	if bills, err = s.enrichWithPaymentStatus(scopedDB, bills); err != nil {
+		return nil, err
+	}
+
+	matched := []*models.Bill{}
+	loc := userLocation(scopedDB)
+	for _, bill := range bills {
+		if filter.matches(bill, loc) {
+			matched = append(matched, bill)
+		}
+	}
+	slices.SortStableFunc(matched, func(a, b *models.Bill) int {
+		return compareBills(a, b, filter.Sort)
+	})
+	response := &models.BillListResponse{Total: int64(len(matched))}
+
+	if filter.Cursor != nil {
+		after, err := cursorBill(filter.Cursor, filter.Sort.Field)
+		if err != nil {
+			return nil, err
+		}
+		start := len(matched)
+		for i, bill := range matched {
+			if compareBills(bill, after, filter.Sort) > 0 {
+				start = i
+				break
+			}
+		}
+		matched = matched[start:]
+	}
+	if filter.Limit > 0 && len(matched) > filter.Limit {
+		matched = matched[:filter.Limit]
+		response.NextCursor = billCursor(matched[len(matched)-1], filter.Sort).Encode()
+	}
+	response.Bills = matched
+	return response, nil
+}
+
+// compareBills orders bills by a sort field and then ID, like the repository does in SQL. Bills
+// without a next due date sort after those with one.
+func compareBills(a, b *models.Bill, sort repository.Sort) int {
+	var order int
+	switch sort.Field {
+	case "amount":
+		order = cmp.Compare(a.Amount, b.Amount)
+	case "created_at":
+		order = a.CreatedAt.Compare(b.CreatedAt)
+	case BillSortDueDate:
+		switch {
+		case a.NextDueDate == nil && b.NextDueDate == nil:
+		case a.NextDueDate == nil:
+			order = 1
+		case b.NextDueDate == nil:
+			order = -1
+		default:
+			order = a.NextDueDate.Compare(*b.NextDueDate)
+		}
+	default:
+		order = cmp.Compare(a.Name, b.Name)
+	}
+	if order == 0 {
+		order = cmp.Compare(a.ID, b.ID)
+	}
+	if sort.Desc {
+		return -order
+	}
+	return order
+}
+
+// billCursor returns the cursor following a bill in a listing sorted by sort
+func billCursor(bill *models.Bill, sort repository.Sort) *repository.Cursor {
+	switch sort.Field {
+	case "amount":
+		return repository.NewCursor(bill.Amount, bill.ID)
+	case "created_at":
+		return repository.NewCursor(bill.CreatedAt, bill.ID)
+	case BillSortDueDate:
+		return repository.NewCursor(bill.NextDueDate, bill.ID)
+	default:
+		return repository.NewCursor(bill.Name, bill.ID)
+	}
+}
+
+// cursorBill turns a cursor back into a bill holding the sort value, to compare listed bills against
+func cursorBill(cursor *repository.Cursor, field string) (*models.Bill, error) {
+	bill := &models.Bill{ID: cursor.ID}
+	var err error
+	switch field {
+	case "amount":
+		bill.Amount, err = cursor.Float()
+	case "created_at":
+		var createdAt *time.Time
+		if createdAt, err = cursor.Time(); createdAt != nil {
+			bill.CreatedAt = *createdAt
+		}
+	case BillSortDueDate:
+		bill.NextDueDate, err = cursor.Time()
+	default:
+		bill.Name = cursor.Value
+	}
+	if err != nil {
+		return nil, repository.ErrInvalidCursor
+	}
+	return bill, nil
+}
+
+// SearchPayments retrieves a page of payments, of one bill or of every bill not in the trash
+func (s *BillService) SearchPayments(scopedDB *gorm.DB, filter repository.PaymentFilter) (*models.PaymentListResponse, error) {
+	// Verify the bill exists and belongs to the user
+	if filter.BillID != "" {
+		if _, err := s.repo.Get(scopedDB, filter.BillID); err != nil {
+			return nil, err
+		}
+	}
+
+	query := filter
+	if query.Limit > 0 {
+		query.Limit++
+	}
+	payments, total, err := s.paymentRepo.Search(scopedDB, query)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.PaymentListResponse{Payments: payments, Total: total}
+	if filter.Limit > 0 && len(payments) > filter.Limit {
+		response.Payments = payments[:filter.Limit]
+		last := response.Payments[filter.Limit-1]
+		if filter.Sort.Field == "amount" {
+			response.NextCursor = repository.NewCursor(last.Amount, last.ID).Encode()
+		} else {
+			response.NextCursor = repository.NewCursor(last.PaymentDate, last.ID).Encode()
+		}
+	}
+	return response, nil
+}
@@ -2,168 +2,1426 @@ package services
 
 import (
 	"fmt"
+	"reflect"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/cryptk/williams/internal/config"
 	"github.com/cryptk/williams/internal/models"
 	"github.com/cryptk/williams/internal/repository"
+	"github.com/cryptk/williams/pkg/holidays"
 	"github.com/cryptk/williams/pkg/utils"
 	"gorm.io/gorm"
 )
 
+// maxAutopayCatchUp bounds how many missed occurrences of one bill a single autopay run records
+const maxAutopayCatchUp = 24
+
 // BillService handles business logic for bills
 type BillService struct {
-	repo        repository.BillRepository
-	paymentRepo repository.PaymentRepository
-	config      *config.Config
+	repo          repository.BillRepository
+	paymentRepo   repository.PaymentRepository
+	versionRepo   repository.BillVersionRepository
+	scheduleRepo  repository.BillScheduleRepository
+	loanRepo      repository.BillLoanRepository
+	statementRepo repository.BillStatementRepository
+	lateFeeRepo   repository.LateFeeRepository
+	tagRepo       repository.TagRepository
+	payeeRepo     repository.PayeeRepository
+	holidays      *HolidayService
+	audit         *AuditService
+	config        *config.Config
+}
+
+// NewBillService creates a new bill service
+func NewBillService(repo repository.BillRepository, paymentRepo repository.PaymentRepository, versionRepo repository.BillVersionRepository, scheduleRepo repository.BillScheduleRepository, loanRepo repository.BillLoanRepository, statementRepo repository.BillStatementRepository, lateFeeRepo repository.LateFeeRepository, tagRepo repository.TagRepository, payeeRepo repository.PayeeRepository, holidays *HolidayService, audit *AuditService, cfg *config.Config) *BillService {
+	return &BillService{
+		repo:          repo,
+		paymentRepo:   paymentRepo,
+		versionRepo:   versionRepo,
+		scheduleRepo:  scheduleRepo,
+		loanRepo:      loanRepo,
+		statementRepo: statementRepo,
+		lateFeeRepo:   lateFeeRepo,
+		tagRepo:       tagRepo,
+		payeeRepo:     payeeRepo,
+		holidays:      holidays,
+		audit:         audit,
+		config:        cfg,
+	}
+}
+
+// =============================================================================
+// Bill CRUD Methods
+// =============================================================================
+
+// Create creates a new bill
+func (s *BillService) Create(scopedDB *gorm.DB, bill *models.Bill) error {
+	// New bills always start active
+	bill.ArchivedAt = nil
+	bill.DeletedAt = gorm.DeletedAt{}
+	bill.AutopayEnabledAt = nil
+	if bill.Autopay {
+		now := utils.NowInAppTimezone()
+		bill.AutopayEnabledAt = &now
+	}
+
+	if err := s.validateKind(bill, userLocation(scopedDB)); err != nil {
+		return err
+	}
+	if err := validateLateFeeRule(bill.LateFeeRule); err != nil {
+		return err
+	}
+	// Validate recurrence_days based on recurrence_type
+	if err := s.validateRecurrence(bill); err != nil {
+		return err
+	}
+	if err := s.validateReferences(scopedDB, bill, nil); err != nil {
+		return err
+	}
+
+	// The bill and the records stored alongside it are saved together or not at all
+	if err := scopedDB.Transaction(func(tx *gorm.DB) error {
+		if err := s.repo.Create(tx, bill); err != nil {
+			return err
+		}
+		if err := s.saveLoan(tx, bill); err != nil {
+			return err
+		}
+		if err := s.saveLateFeeRule(tx, bill); err != nil {
+			return err
+		}
+		if err := s.saveTags(tx, bill); err != nil {
+			return err
+		}
+
+		// The first version covers the bill from its start (or creation) onward
+		effectiveFrom := bill.CreatedAt
+		if bill.StartDate != nil {
+			effectiveFrom = *bill.StartDate
+		}
+		if err := s.versionRepo.Create(tx, newBillVersion(bill, effectiveFrom)); err != nil {
+			return fmt.Errorf("failed to record bill version: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionBillCreate,
+		EntityType: "bill",
+		EntityID:   bill.ID,
+		Changes:    auditDiff(nil, bill),
+	})
+	return nil
+}
+
+// Get retrieves a bill by ID
+func (s *BillService) Get(scopedDB *gorm.DB, id string) (*models.Bill, error) {
+	bill, err := s.repo.Get(scopedDB, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Calculate next due date, status and is_paid
+	if _, err := s.enrichWithPaymentStatus(scopedDB, []*models.Bill{bill}); err != nil {
+		return nil, err
+	}
+	return bill, nil
+}
+
+// GetStats retrieves statistics of the active bills matching filter. The bills are enriched once and
+// the versions and statements loaded for that also price the unpaid bills, so the number of queries
+// does not grow with the number of bills.
+func (s *BillService) GetStats(scopedDB *gorm.DB, filter repository.BillFilter) (*models.BillStats, error) {
+	filter.IncludeArchived = false
+	filter.Sort, filter.Cursor, filter.Limit = repository.Sort{}, nil, 0
+	bills, _, err := s.repo.Search(scopedDB, filter)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.enrich(scopedDB, bills, true)
+	if err != nil {
+		return nil, err
+	}
+	versions, statements := history.versions, history.statements
+
+	stats := &models.BillStats{TotalBills: len(bills)}
+
+	// Calculate paid/unpaid counts and due amount based on computed is_paid field.
+	// The due amount uses the price in effect on each bill's next due date, or its statement.
+	// Paused, ended and paid off bills are counted separately and never count as unpaid.
+	// Late fees accrued by unpaid bills are added to the due amount.
+	now := utils.NowInAppTimezone()
+	loc := userLocation(scopedDB)
+	for _, bill := range bills {
+		stats.TotalAmount += bill.Amount
+		switch {
+		case bill.Status == models.BillStatusEnded || bill.Status == models.BillStatusPaidOff:
+			stats.EndedBills++
+		case bill.Status == models.BillStatusPaused:
+			stats.PausedBills++
+		case bill.IsPaid:
+			stats.PaidBills++
+		default:
+			stats.UnpaidBills++
+			dueDate, rolledDate := now, now
+			if bill.NextDueDate != nil {
+				dueDate, rolledDate = *bill.NextDueDate, *bill.NextDueDate
+			}
+			if bill.NominalDueDate != nil {
+				dueDate = *bill.NominalDueDate
+			}
+			amount, _ := occurrenceAmount(bill, versions[bill.ID], statements[bill.ID], dueDate, rolledDate, loc)
+			stats.DueAmount += amount + bill.LateFeeTotal
+			stats.LateFees += bill.LateFeeTotal
+		}
+	}
+
+	return stats, nil
+}
+
+// List retrieves all bills matching the stored-field filters that are not in the trash, ignoring the
+// sort, cursor and limit
+func (s *BillService) List(scopedDB *gorm.DB, filter repository.BillFilter) ([]*models.Bill, error) {
+	filter.Sort, filter.Cursor, filter.Limit = repository.Sort{}, nil, 0
+	bills, _, err := s.repo.Search(scopedDB, filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.enrichWithPaymentStatus(scopedDB, bills)
+}
+
+// Update updates an existing bill
+func (s *BillService) Update(scopedDB *gorm.DB, bill *models.Bill) error {
+	if err := s.validateKind(bill, userLocation(scopedDB)); err != nil {
+		return err
+	}
+	if err := validateLateFeeRule(bill.LateFeeRule); err != nil {
+		return err
+	}
+	// Validate recurrence_days based on recurrence_type
+	if err := s.validateRecurrence(bill); err != nil {
+		return err
+	}
+
+	before, err := s.repo.Get(scopedDB, bill.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateReferences(scopedDB, bill, before); err != nil {
+		return err
+	}
+	if before.Loan, err = s.loanRepo.Get(scopedDB, bill.ID); err != nil {
+		return err
+	}
+	if before.LateFeeRule, err = s.lateFeeRepo.GetRule(scopedDB, bill.ID); err != nil {
+		return err
+	}
+	if before.TagIDs, err = s.tagRepo.ListBillTags(scopedDB, bill.ID); err != nil {
+		return err
+	}
+	if bill.TagIDs == nil {
+		bill.TagIDs = before.TagIDs
+	}
+
+	// Autopay only covers occurrences due after it was switched on
+	switch {
+	case !bill.Autopay:
+		bill.AutopayEnabledAt = nil
+	case before.Autopay:
+		bill.AutopayEnabledAt = before.AutopayEnabledAt
+	default:
+		now := utils.NowInAppTimezone()
+		bill.AutopayEnabledAt = &now
+	}
+
+	// The bill and the records stored alongside it are saved together or not at all
+	if err := scopedDB.Transaction(func(tx *gorm.DB) error {
+		if err := s.repo.Update(tx, bill); err != nil {
+			return err
+		}
+		if err := s.saveLoan(tx, bill); err != nil {
+			return err
+		}
+		if err := s.saveLateFeeRule(tx, bill); err != nil {
+			return err
+		}
+		if err := s.saveTags(tx, bill); err != nil {
+			return err
+		}
+
+		// Keep the previous values for occurrences before the change takes effect
+		if billVersionChanged(before, bill) {
+			effectiveFrom := utils.NowInAppTimezone()
+			if bill.EffectiveFrom != nil {
+				effectiveFrom = bill.EffectiveFrom.In(userLocation(scopedDB))
+			}
+			if err := s.versionRepo.Create(tx, newBillVersion(bill, effectiveFrom)); err != nil {
+				return fmt.Errorf("failed to record bill version: %w", err)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionBillUpdate,
+		EntityType: "bill",
+		EntityID:   bill.ID,
+		Changes:    auditDiff(before, bill),
+	})
+	return nil
+}
+
+// Delete moves a bill to the trash
+func (s *BillService) Delete(scopedDB *gorm.DB, id string) error {
+	before, err := s.repo.Get(scopedDB, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(scopedDB, id); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionBillDelete,
+		EntityType: "bill",
+		EntityID:   id,
+		Changes:    auditDiff(before, nil),
+	})
+	return nil
+}
+
+// =============================================================================
+// Archive and Trash Methods
+// =============================================================================
+
+// Archive hides a bill from lists and stats while keeping it and its payments
+func (s *BillService) Archive(scopedDB *gorm.DB, id string) error {
+	now := utils.NowInAppTimezone()
+	if err := s.repo.SetArchived(scopedDB, id, &now); err != nil {
+		return err
+	}
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{Action: models.AuditActionBillArchive, EntityType: "bill", EntityID: id})
+	return nil
+}
+
+// Unarchive returns an archived bill to the active list
+func (s *BillService) Unarchive(scopedDB *gorm.DB, id string) error {
+	if err := s.repo.SetArchived(scopedDB, id, nil); err != nil {
+		return err
+	}
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{Action: models.AuditActionBillUnarchive, EntityType: "bill", EntityID: id})
+	return nil
+}
+
+// ListTrash retrieves the bills in the trash
+func (s *BillService) ListTrash(scopedDB *gorm.DB) ([]*models.Bill, error) {
+	return s.repo.ListDeleted(scopedDB)
+}
+
+// Restore moves a bill out of the trash
+func (s *BillService) Restore(scopedDB *gorm.DB, id string) error {
+	if err := s.repo.Restore(scopedDB, id); err != nil {
+		return err
+	}
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{Action: models.AuditActionBillRestore, EntityType: "bill", EntityID: id})
+	return nil
+}
+
+// =============================================================================
+// Bill Schedule Methods
+// =============================================================================
+
+// Pause suspends a recurring bill. Occurrences within the pause are not billed.
+func (s *BillService) Pause(scopedDB *gorm.DB, pause *models.BillPause) error {
+	if err := s.verifyRecurring(scopedDB, pause.BillID); err != nil {
+		return err
+	}
+	loc := userLocation(scopedDB)
+	pause.StartDate = pause.StartDate.In(loc)
+	if pause.EndDate != nil {
+		endDate := pause.EndDate.In(loc)
+		if endDate.Before(pause.StartDate) {
+			return fmt.Errorf("end_date must not be before start_date")
+		}
+		pause.EndDate = &endDate
+	}
+	if err := s.scheduleRepo.CreatePause(scopedDB, pause); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionBillPause,
+		EntityType: "bill",
+		EntityID:   pause.BillID,
+		Changes:    auditDiff(nil, pause),
+	})
+	return nil
+}
+
+// ListPauses retrieves the pauses of a bill
+func (s *BillService) ListPauses(scopedDB *gorm.DB, billID string) ([]*models.BillPause, error) {
+	// Verify the bill exists and belongs to the user
+	if _, err := s.repo.Get(scopedDB, billID); err != nil {
+		return nil, err
+	}
+	return s.scheduleRepo.ListPauses(scopedDB, billID)
+}
+
+// Unpause removes a pause from a bill
+func (s *BillService) Unpause(scopedDB *gorm.DB, billID, pauseID string) error {
+	pause, err := s.scheduleRepo.DeletePause(scopedDB, billID, pauseID)
+	if err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionBillUnpause,
+		EntityType: "bill",
+		EntityID:   billID,
+		Changes:    auditDiff(pause, nil),
+	})
+	return nil
+}
+
+// Skip waives the occurrence of a recurring bill due on skip.DueDate
+func (s *BillService) Skip(scopedDB *gorm.DB, skip *models.BillSkip) error {
+	if err := s.verifyRecurring(scopedDB, skip.BillID); err != nil {
+		return err
+	}
+
+	// Store skips at noon like due dates so they match by calendar day
+	loc := userLocation(scopedDB)
+	day := calendarDay(skip.DueDate, loc)
+	skip.DueDate = time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, loc)
+
+	existing, err := s.scheduleRepo.ListSkips(scopedDB, skip.BillID)
+	if err != nil {
+		return err
+	}
+	if (&billSchedule{skips: existing, loc: loc}).skipped(skip.DueDate) {
+		return fmt.Errorf("occurrence on %s is already skipped", skip.DueDate.Format(time.DateOnly))
+	}
+	if err := s.scheduleRepo.CreateSkip(scopedDB, skip); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionBillSkip,
+		EntityType: "bill",
+		EntityID:   skip.BillID,
+		Changes:    auditDiff(nil, skip),
+	})
+	return nil
+}
+
+// ListSkips retrieves the skipped occurrences of a bill
+func (s *BillService) ListSkips(scopedDB *gorm.DB, billID string) ([]*models.BillSkip, error) {
+	// Verify the bill exists and belongs to the user
+	if _, err := s.repo.Get(scopedDB, billID); err != nil {
+		return nil, err
+	}
+	return s.scheduleRepo.ListSkips(scopedDB, billID)
+}
+
+// Unskip restores a skipped occurrence of a bill
+func (s *BillService) Unskip(scopedDB *gorm.DB, billID, skipID string) error {
+	skip, err := s.scheduleRepo.DeleteSkip(scopedDB, billID, skipID)
+	if err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionBillUnskip,
+		EntityType: "bill",
+		EntityID:   billID,
+		Changes:    auditDiff(skip, nil),
+	})
+	return nil
+}
+
+// =============================================================================
+// Loan Methods
+// =============================================================================
+
+// Amortization returns the amortization schedule of a loan bill. Extra is added to each projected
+// payment to show its effect on the payoff date and total interest.
+func (s *BillService) Amortization(scopedDB *gorm.DB, billID string, extra float64) (*models.AmortizationSchedule, error) {
+	if extra < 0 {
+		return nil, fmt.Errorf("extra_payment must not be negative")
+	}
+	bill, err := s.Get(scopedDB, billID)
+	if err != nil {
+		return nil, err
+	}
+	if bill.Loan == nil {
+		return nil, fmt.Errorf("bill is not a loan")
+	}
+	schedules, err := s.loadSchedules(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+	return s.amortization(scopedDB, bill, schedules.forBill(bill), extra)
+}
+
+// amortization builds the amortization schedule of an enriched loan bill from its recorded payments
+func (s *BillService) amortization(scopedDB *gorm.DB, bill *models.Bill, schedule *billSchedule, extra float64) (*models.AmortizationSchedule, error) {
+	payments, err := s.paymentRepo.List(scopedDB, bill.ID)
+	if err != nil {
+		return nil, err
+	}
+	return amortize(bill, bill.Loan, countedPayments(payments), schedule, extra), nil
+}
+
+// =============================================================================
+// Statement Methods
+// =============================================================================
+
+// SaveStatement records the statement of one occurrence of a variable amount bill, replacing any
+// statement already entered for the same due date
+func (s *BillService) SaveStatement(scopedDB *gorm.DB, statement *models.BillStatement) error {
+	existing, err := s.prepareStatements(scopedDB, statement.BillID, []*models.BillStatement{statement})
+	if err != nil {
+		return err
+	}
+	before, err := s.saveStatement(scopedDB, existing, statement)
+	if err != nil {
+		return err
+	}
+	s.auditStatementSave(scopedDB, before, statement)
+	return nil
+}
+
+// ImportStatements records several statements of a variable amount bill at once, replacing those
+// already entered for the same due dates. Either every statement is saved or none are.
+func (s *BillService) ImportStatements(scopedDB *gorm.DB, billID string, statements []*models.BillStatement) error {
+	existing, err := s.prepareStatements(scopedDB, billID, statements)
+	if err != nil {
+		return err
+	}
+
+	// The statements are saved together or not at all, and only audited once they are committed
+	replaced := make([]*models.BillStatement, len(statements))
+	if err := scopedDB.Transaction(func(tx *gorm.DB) error {
+		for i, statement := range statements {
+			before, err := s.saveStatement(tx, existing, statement)
+			if err != nil {
+				return err
+			}
+			replaced[i] = before
+			existing = append(existing, statement)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for i, statement := range statements {
+		s.auditStatementSave(scopedDB, replaced[i], statement)
+	}
+	return nil
+}
+
+// ListStatements retrieves the statements of a bill, oldest first, with how much was paid toward each
+func (s *BillService) ListStatements(scopedDB *gorm.DB, billID string) ([]*models.BillStatement, error) {
+	// Verify the bill exists and belongs to the user
+	if _, err := s.repo.Get(scopedDB, billID); err != nil {
+		return nil, err
+	}
+	statements, err := s.statementRepo.List(scopedDB, billID)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.paymentRepo.List(scopedDB, billID)
+	if err != nil {
+		return nil, err
+	}
+	applyStatementPayments(statements, countedPayments(payments), userLocation(scopedDB))
+	return statements, nil
+}
+
+// DeleteStatement removes a statement from a bill
+func (s *BillService) DeleteStatement(scopedDB *gorm.DB, billID, statementID string) error {
+	statement, err := s.statementRepo.Delete(scopedDB, billID, statementID)
+	if err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionStatementDelete,
+		EntityType: "statement",
+		EntityID:   statement.ID,
+		Changes:    auditDiff(statement, nil),
+	})
+	return nil
+}
+
+// prepareStatements verifies that statements can be saved to a variable amount bill and returns the
+// statements it already has
+func (s *BillService) prepareStatements(scopedDB *gorm.DB, billID string, statements []*models.BillStatement) ([]*models.BillStatement, error) {
+	bill, err := s.repo.Get(scopedDB, billID)
+	if err != nil {
+		return nil, err
+	}
+	if !bill.VariableAmount {
+		return nil, fmt.Errorf("statements can only be added to variable amount bills")
+	}
+
+	loc := userLocation(scopedDB)
+	for _, statement := range statements {
+		if statement.DueDate.IsZero() {
+			return nil, fmt.Errorf("statement due_date is required")
+		}
+		if statement.Amount < 0 {
+			return nil, fmt.Errorf("statement amount must not be negative")
+		}
+		if statement.MinimumPayment != nil && (*statement.MinimumPayment < 0 || *statement.MinimumPayment > statement.Amount) {
+			return nil, fmt.Errorf("minimum_payment must be between 0 and the statement amount")
+		}
+		statement.BillID = bill.ID
+		statement.UserID = bill.UserID
+		statement.DueDate = statement.DueDate.In(loc)
+	}
+	return s.statementRepo.List(scopedDB, billID)
+}
+
+// saveStatement creates a statement, or updates the one in existing with the same due date, and
+// returns the statement it replaced, if any
+func (s *BillService) saveStatement(scopedDB *gorm.DB, existing []*models.BillStatement, statement *models.BillStatement) (*models.BillStatement, error) {
+	var before *models.BillStatement
+	loc := userLocation(scopedDB)
+	for _, candidate := range existing {
+		if calendarDay(candidate.DueDate, loc).Equal(calendarDay(statement.DueDate, loc)) {
+			before = candidate
+			break
+		}
+	}
+
+	if before == nil {
+		statement.ID = ""
+		if err := s.statementRepo.Create(scopedDB, statement); err != nil {
+			return nil, err
+		}
+	} else {
+		statement.ID = before.ID
+		statement.CreatedAt = before.CreatedAt
+		if err := s.statementRepo.Update(scopedDB, statement); err != nil {
+			return nil, err
+		}
+	}
+	return before, nil
+}
+
+// auditStatementSave records the creation of a statement, or its replacement of before
+func (s *BillService) auditStatementSave(scopedDB *gorm.DB, before, statement *models.BillStatement) {
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionStatementSave,
+		EntityType: "statement",
+		EntityID:   statement.ID,
+		Changes:    auditDiff(before, statement),
+	})
+}
+
+// =============================================================================
+// Late Fee Methods
+// =============================================================================
+
+// WaiveLateFee waives the late fee of the occurrence of a bill due on waiver.DueDate
+func (s *BillService) WaiveLateFee(scopedDB *gorm.DB, waiver *models.BillLateFeeWaiver) error {
+	// Verify the bill exists and belongs to the user
+	if _, err := s.repo.Get(scopedDB, waiver.BillID); err != nil {
+		return err
+	}
+
+	// Store waivers at noon like due dates so they match by calendar day
+	loc := userLocation(scopedDB)
+	day := calendarDay(waiver.DueDate, loc)
+	waiver.DueDate = time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, loc)
+
+	existing, err := s.lateFeeRepo.ListWaivers(scopedDB, waiver.BillID)
+	if err != nil {
+		return err
+	}
+	if waiverOn(existing, waiver.DueDate, waiver.DueDate, loc) != nil {
+		return fmt.Errorf("late fee on %s is already waived", waiver.DueDate.Format(time.DateOnly))
+	}
+	if err := s.lateFeeRepo.CreateWaiver(scopedDB, waiver); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionLateFeeWaive,
+		EntityType: "bill",
+		EntityID:   waiver.BillID,
+		Changes:    auditDiff(nil, waiver),
+	})
+	return nil
+}
+
+// ListLateFeeWaivers retrieves the late fee waivers of a bill
+func (s *BillService) ListLateFeeWaivers(scopedDB *gorm.DB, billID string) ([]*models.BillLateFeeWaiver, error) {
+	// Verify the bill exists and belongs to the user
+	if _, err := s.repo.Get(scopedDB, billID); err != nil {
+		return nil, err
+	}
+	return s.lateFeeRepo.ListWaivers(scopedDB, billID)
+}
+
+// UnwaiveLateFee removes a late fee waiver from a bill
+func (s *BillService) UnwaiveLateFee(scopedDB *gorm.DB, billID, waiverID string) error {
+	waiver, err := s.lateFeeRepo.DeleteWaiver(scopedDB, billID, waiverID)
+	if err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionLateFeeUnwaive,
+		EntityType: "bill",
+		EntityID:   billID,
+		Changes:    auditDiff(waiver, nil),
+	})
+	return nil
+}
+
+// =============================================================================
+// Bill History Methods
+// =============================================================================
+
+// History retrieves the versions of a bill, newest first
+func (s *BillService) History(scopedDB *gorm.DB, billID string) ([]*models.BillVersion, error) {
+	// Verify the bill exists and belongs to the user
+	if _, err := s.repo.Get(scopedDB, billID); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.versionRepo.List(scopedDB, billID)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(versions)
+	return versions, nil
+}
+
+// PriceIncreases lists every increase in a bill's amount that took effect between since and now,
+// newest first. Scheduled future increases are not included.
+func (s *BillService) PriceIncreases(scopedDB *gorm.DB, since time.Time, filter repository.BillFilter) ([]*models.PriceChange, error) {
+	bills, _, err := s.repo.Search(scopedDB, repository.BillFilter{CategoryID: filter.CategoryID, TagID: filter.TagID, PayeeID: filter.PayeeID, AccountID: filter.AccountID})
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(bills))
+	for _, bill := range bills {
+		names[bill.ID] = bill.Name
+	}
+
+	versions, err := s.versionsByBill(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+
+	now := utils.NowInAppTimezone()
+	increases := []*models.PriceChange{}
+	for billID, billVersions := range versions {
+		// Archived and deleted bills are excluded from reports
+		if _, ok := names[billID]; !ok {
+			continue
+		}
+		for i := 1; i < len(billVersions); i++ {
+			previous, current := billVersions[i-1], billVersions[i]
+			if current.Amount <= previous.Amount || current.EffectiveFrom.Before(since) || current.EffectiveFrom.After(now) {
+				continue
+			}
+			increases = append(increases, &models.PriceChange{
+				BillID:        billID,
+				BillName:      names[billID],
+				OldAmount:     previous.Amount,
+				NewAmount:     current.Amount,
+				Change:        current.Amount - previous.Amount,
+				PercentChange: (current.Amount - previous.Amount) / previous.Amount * 100,
+				EffectiveFrom: current.EffectiveFrom,
+			})
+		}
+	}
+
+	slices.SortFunc(increases, func(a, b *models.PriceChange) int {
+		return b.EffectiveFrom.Compare(a.EffectiveFrom)
+	})
+	return increases, nil
+}
+
+// Forecast projects the unpaid occurrences of all bills due between from and to, using the amount and
+// recurrence in effect on each occurrence date
+func (s *BillService) Forecast(scopedDB *gorm.DB, from, to time.Time, filter repository.BillFilter) (*models.BillForecast, error) {
+	bills, err := s.List(scopedDB, filter)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.versionsByBill(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := s.loadSchedules(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+	statements, err := s.statementsByBill(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast := &models.BillForecast{From: from, To: to, Occurrences: []*models.ForecastOccurrence{}}
+	for _, bill := range bills {
+		if bill.NextDueDate == nil {
+			continue
+		}
+
+		// One-time bills occur once, and only while unpaid
+		if bill.RecurrenceType == "none" {
+			if !bill.IsPaid && !bill.NextDueDate.Before(from) && !bill.NextDueDate.After(to) {
+				amount, estimated := occurrenceAmount(bill, versions[bill.ID], statements[bill.ID], *bill.NextDueDate, *bill.NextDueDate, schedules.loc)
+				addOccurrence(forecast, bill, &models.ForecastOccurrence{DueDate: *bill.NextDueDate, Amount: amount, Estimated: estimated})
+			}
+			continue
+		}
+
+		// Loans: the projected payments of the amortization schedule, ending once the loan is repaid
+		schedule := schedules.forBill(bill)
+		if bill.Loan != nil {
+			amortization, err := s.amortization(scopedDB, bill, schedule, 0)
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range amortization.Entries {
+				if !entry.Paid && !entry.DueDate.Before(from) && !entry.DueDate.After(to) {
+					addOccurrence(forecast, bill, &models.ForecastOccurrence{DueDate: entry.DueDate, Amount: entry.Payment})
+				}
+			}
+			continue
+		}
+
+		// Recurring bills: walk forward from the next unpaid due date, stopping at the bill's end
+		start := *bill.NextDueDate
+		if bill.NominalDueDate != nil {
+			start = *bill.NominalDueDate
+		}
+		_, _, billed := schedule.resolve(start)
+		for due := start; !due.After(to); {
+			if schedule.ended(due, billed) {
+				break
+			}
+			version := versionAt(bill, versions[bill.ID], due)
+			pause := schedule.pauseOn(due)
+			if pause != nil && pause.EndDate == nil {
+				break
+			}
+			if pause == nil {
+				skipped := schedule.skipped(due)
+				if !skipped {
+					billed++
+				}
+				if rolled := schedule.rolled(due); !rolled.Before(from) && !rolled.After(to) {
+					amount, estimated := occurrenceAmount(bill, versions[bill.ID], statements[bill.ID], due, rolled, schedule.loc)
+					addOccurrence(forecast, bill, &models.ForecastOccurrence{DueDate: rolled, Amount: amount, Skipped: skipped, Estimated: estimated})
+				}
+			}
+			next, ok := nextOccurrence(version, due, schedule.loc)
+			if !ok {
+				break // A later version made the bill one-time
+			}
+			due = next
+		}
+	}
+
+	slices.SortFunc(forecast.Occurrences, func(a, b *models.ForecastOccurrence) int {
+		return a.DueDate.Compare(b.DueDate)
+	})
+	return forecast, nil
+}
+
+// =============================================================================
+// Payment CRUD Methods
+// =============================================================================
+
+// CreatePayment creates a payment for a bill
+func (s *BillService) CreatePayment(scopedDB *gorm.DB, payment *models.Payment) error {
+	// First verify the bill exists and belongs to the user
+	bill, err := s.repo.Get(scopedDB, payment.BillID)
+	if err != nil {
+		return err
+	}
+	// Payments are made from the bill's account unless another one is given
+	if payment.AccountID == nil {
+		payment.AccountID = bill.AccountID
+	} else if *payment.AccountID == "" {
+		payment.AccountID = nil
+	}
+	if err := repository.VerifyOwnership(scopedDB, repository.Reference{Field: "account_id", Model: &models.FundingAccount{}, ID: payment.AccountID}); err != nil {
+		return err
+	}
+	// Only autopay records generated or reversed payments
+	payment.AutoGenerated = false
+	payment.ReversedAt = nil
+	payment.ReversalReason = ""
+
+	// Create the payment
+	if err := s.paymentRepo.Create(scopedDB, payment); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionPaymentCreate,
+		EntityType: "payment",
+		EntityID:   payment.ID,
+		Changes:    auditDiff(nil, payment),
+	})
+	return nil
+}
+
+// DeletePayment deletes a payment
+func (s *BillService) DeletePayment(scopedDB *gorm.DB, paymentID string) error {
+	before, err := s.paymentRepo.Get(scopedDB, paymentID)
+	if err != nil {
+		return err
+	}
+	if err := s.paymentRepo.Delete(scopedDB, paymentID); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionPaymentDelete,
+		EntityType: "payment",
+		EntityID:   paymentID,
+		Changes:    auditDiff(before, nil),
+	})
+	return nil
+}
+
+// ReversePayment marks an autopay payment as failed. The payment is kept so autopay does not record it
+// again, but the occurrence counts as unpaid.
+func (s *BillService) ReversePayment(scopedDB *gorm.DB, billID, paymentID, reason string) (*models.Payment, error) {
+	payment, err := s.paymentRepo.Get(scopedDB, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.BillID != billID {
+		return nil, repository.ErrPaymentNotFound
+	}
+	if !payment.AutoGenerated {
+		return nil, fmt.Errorf("only autopay payments can be reversed, delete the payment instead")
+	}
+	if payment.ReversedAt != nil {
+		return nil, fmt.Errorf("payment is already reversed")
+	}
+
+	now := utils.NowInAppTimezone()
+	if err := s.paymentRepo.Reverse(scopedDB, paymentID, now, reason); err != nil {
+		return nil, err
+	}
+	before := *payment
+	payment.ReversedAt = &now
+	payment.ReversalReason = reason
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionPaymentReverse,
+		EntityType: "payment",
+		EntityID:   paymentID,
+		Changes:    auditDiff(&before, payment),
+	})
+	return payment, nil
+}
+
+// =============================================================================
+// Autopay Methods
+// =============================================================================
+
+// RunAutopay records a payment for every autopay bill occurrence that is due by now and has no payment yet,
+// returning how many payments were recorded. An occurrence whose payment was reversed is left unpaid.
+// Bills are loaded and enriched once; after each payment their schedule is advanced in memory.
+func (s *BillService) RunAutopay(scopedDB *gorm.DB, now time.Time) (int, error) {
+	bills, err := s.repo.List(scopedDB, repository.ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+	var autopayBills []*models.Bill
+	billIDs := []string{}
+	for _, bill := range bills {
+		if bill.Autopay && bill.AutopayEnabledAt != nil {
+			autopayBills = append(autopayBills, bill)
+			billIDs = append(billIDs, bill.ID)
+		}
+	}
+	if len(autopayBills) == 0 {
+		return 0, nil
+	}
+
+	if _, err := s.enrichWithPaymentStatus(scopedDB, autopayBills); err != nil {
+		return 0, err
+	}
+	schedules, err := s.loadSchedules(scopedDB)
+	if err != nil {
+		return 0, err
+	}
+	payments, err := s.paymentRepo.ListForBills(scopedDB, billIDs)
+	if err != nil {
+		return 0, err
+	}
+	versions, err := s.versionsByBill(scopedDB)
+	if err != nil {
+		return 0, err
+	}
+	statements, err := s.statementsByBill(scopedDB)
+	if err != nil {
+		return 0, err
+	}
+
+	loc := schedules.loc
+	recorded := 0
+	for _, bill := range autopayBills {
+		schedule := schedules.forBill(bill)
+
+		// Catch up on every occurrence missed since the last run, one at a time
+		for range maxAutopayCatchUp {
+			due := nextAutopayDate(bill, payments[bill.ID], now, loc)
+			if due == nil || calendarDay(*due, loc).Before(calendarDay(*bill.AutopayEnabledAt, loc)) {
+				break
+			}
+
+			amount, _ := occurrenceAmount(bill, versions[bill.ID], statements[bill.ID], *due, *due, loc)
+			payment := &models.Payment{
+				BillID:        bill.ID,
+				UserID:        bill.UserID,
+				Amount:        amount,
+				PaymentDate:   *due,
+				Notes:         bill.AutopayNotes,
+				AccountID:     bill.AccountID,
+				AutoGenerated: true,
+			}
+			if err := s.paymentRepo.Create(scopedDB, payment); err != nil {
+				return recorded, err
+			}
+			recorded++
+
+			s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+				UserID:     &bill.UserID,
+				Action:     models.AuditActionPaymentAutopay,
+				EntityType: "payment",
+				EntityID:   payment.ID,
+				Changes:    auditDiff(nil, payment),
+			})
+
+			payments[bill.ID] = append([]*models.Payment{payment}, payments[bill.ID]...)
+			if err := s.advanceSchedule(bill, schedule, payments[bill.ID]); err != nil {
+				return recorded, err
+			}
+		}
+	}
+	return recorded, nil
 }
 
-// NewBillService creates a new bill service
-func NewBillService(repo repository.BillRepository, paymentRepo repository.PaymentRepository, cfg *config.Config) *BillService {
-	return &BillService{
-		repo:        repo,
-		paymentRepo: paymentRepo,
-		config:      cfg,
+// nextAutopayDate returns the scheduled date of an enriched bill's next occurrence if it is due by now
+// and has no payment, including reversed ones, on that date. payments are the bill's payments, newest first.
+func nextAutopayDate(bill *models.Bill, payments []*models.Payment, now time.Time, loc *time.Location) *time.Time {
+	if bill.Status != models.BillStatusActive || bill.NextDueDate == nil || bill.NextDueDate.After(now) {
+		return nil
+	}
+
+	// Record the payment against the scheduled date so the next due date is calculated from it
+	due := *bill.NextDueDate
+	if bill.NominalDueDate != nil {
+		due = *bill.NominalDueDate
+	}
+
+	for _, payment := range payments {
+		if calendarDay(payment.PaymentDate, loc).Equal(calendarDay(due, loc)) {
+			return nil
+		}
+	}
+	return &due
+}
+
+// advanceSchedule recalculates the next due date and status of an enriched bill after a payment was
+// recorded, as enrichWithPaymentStatus would. payments are the bill's payments, newest first.
+func (s *BillService) advanceSchedule(bill *models.Bill, schedule *billSchedule, payments []*models.Payment) error {
+	counted := countedPayments(payments)
+	var latest *models.Payment
+	if len(counted) > 0 {
+		latest = counted[len(counted)-1]
 	}
+
+	nextDue, lastPaid, err := s.calculateNextDueDate(bill, schedule, latest)
+	if err != nil {
+		return err
+	}
+	bill.NextDueDate = nextDue
+	bill.LastPaidDate = lastPaid
+	bill.NominalDueDate = nil
+	applySchedule(bill, schedule)
+
+	if bill.Loan != nil && loanBalance(bill, bill.Loan, counted) <= paidOffThreshold {
+		bill.Status = models.BillStatusPaidOff
+		bill.NextDueDate = nil
+		bill.NominalDueDate = nil
+	}
+	return nil
 }
 
 // =============================================================================
-// Bill CRUD Methods
+// Private Helper Methods
 // =============================================================================
 
-// Create creates a new bill
-func (s *BillService) Create(scopedDB *gorm.DB, bill *models.Bill) error {
-	// Validate recurrence_days based on recurrence_type
-	if err := s.validateRecurrence(bill); err != nil {
-		return err
+// versionsByBill loads every bill version for the tenant, grouped by bill and oldest first
+func (s *BillService) versionsByBill(scopedDB *gorm.DB) (map[string][]*models.BillVersion, error) {
+	versions, err := s.versionRepo.ListAll(scopedDB)
+	if err != nil {
+		return nil, err
 	}
-	return s.repo.Create(scopedDB, bill)
+	byBill := make(map[string][]*models.BillVersion)
+	for _, version := range versions {
+		byBill[version.BillID] = append(byBill[version.BillID], version)
+	}
+	return byBill, nil
 }
 
-// Get retrieves a bill by ID
-func (s *BillService) Get(scopedDB *gorm.DB, id string) (*models.Bill, error) {
-	bill, err := s.repo.Get(scopedDB, id)
+// loansByBill loads the loan terms of every loan bill for the tenant, keyed by bill
+func (s *BillService) loansByBill(scopedDB *gorm.DB) (map[string]*models.BillLoan, error) {
+	loans, err := s.loanRepo.ListAll(scopedDB)
 	if err != nil {
 		return nil, err
 	}
+	byBill := make(map[string]*models.BillLoan, len(loans))
+	for _, loan := range loans {
+		byBill[loan.BillID] = loan
+	}
+	return byBill, nil
+}
 
-	// Calculate next due date
-	nextDue, lastPaid, err := s.calculateNextDueDate(scopedDB, bill)
+// statementsByBill loads the statements of every bill for the tenant, grouped by bill and oldest first
+func (s *BillService) statementsByBill(scopedDB *gorm.DB) (map[string][]*models.BillStatement, error) {
+	statements, err := s.statementRepo.ListAll(scopedDB)
 	if err != nil {
 		return nil, err
 	}
-	bill.NextDueDate = nextDue
-	bill.LastPaidDate = lastPaid
+	byBill := make(map[string][]*models.BillStatement)
+	for _, statement := range statements {
+		byBill[statement.BillID] = append(byBill[statement.BillID], statement)
+	}
+	return byBill, nil
+}
+
+// applyStatements sets the estimate, current statement and payment status of a variable amount bill
+// from its statements and payments
+func applyStatements(bill *models.Bill, statements []*models.BillStatement, payments []*models.Payment, loc *time.Location) {
+	estimate := estimateAmount(statements, bill.Amount)
+	bill.EstimatedAmount = &estimate
+	if len(statements) == 0 {
+		return
+	}
+	applyStatementPayments(statements, countedPayments(payments), loc)
+
+	due := bill.NextDueDate
+	if bill.NominalDueDate != nil {
+		due = bill.NominalDueDate
+	}
+	bill.CurrentStatement = currentStatement(statements, due, loc)
+	if bill.CurrentStatement != nil {
+		bill.PaymentStatus = bill.CurrentStatement.PaymentStatus
+	}
+}
+
+// lateFeesByBill loads the late fee rules and waivers of every bill for the tenant, keyed by bill
+func (s *BillService) lateFeesByBill(scopedDB *gorm.DB) (map[string]*models.BillLateFeeRule, map[string][]*models.BillLateFeeWaiver, error) {
+	rules, err := s.lateFeeRepo.ListAllRules(scopedDB)
+	if err != nil {
+		return nil, nil, err
+	}
+	waivers, err := s.lateFeeRepo.ListAllWaivers(scopedDB)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rulesByBill := make(map[string]*models.BillLateFeeRule, len(rules))
+	for _, rule := range rules {
+		rulesByBill[rule.BillID] = rule
+	}
+	waiversByBill := make(map[string][]*models.BillLateFeeWaiver)
+	for _, waiver := range waivers {
+		waiversByBill[waiver.BillID] = append(waiversByBill[waiver.BillID], waiver)
+	}
+	return rulesByBill, waiversByBill, nil
+}
+
+// saveLateFeeRule stores the late fee rule of a bill, or removes it when the bill has none
+func (s *BillService) saveLateFeeRule(scopedDB *gorm.DB, bill *models.Bill) error {
+	if bill.LateFeeRule == nil {
+		return s.lateFeeRepo.DeleteRule(scopedDB, bill.ID)
+	}
+	bill.LateFeeRule.BillID = bill.ID
+	bill.LateFeeRule.UserID = bill.UserID
+	if err := s.lateFeeRepo.SaveRule(scopedDB, bill.LateFeeRule); err != nil {
+		return fmt.Errorf("failed to save late fee rule: %w", err)
+	}
+	return nil
+}
+
+// saveTags replaces the tags of a bill with its tag_ids
+func (s *BillService) saveTags(scopedDB *gorm.DB, bill *models.Bill) error {
+	if err := s.tagRepo.SetBillTags(scopedDB, bill.ID, bill.UserID, bill.TagIDs); err != nil {
+		return fmt.Errorf("failed to save tags: %w", err)
+	}
+	return nil
+}
 
-	// Calculate is_paid status
-	isPaid, err := s.calculateIsPaid(scopedDB, bill)
+// tagsByBill loads the tags of every bill for the tenant, keyed by bill and ordered by name
+func (s *BillService) tagsByBill(scopedDB *gorm.DB) (map[string][]*models.Tag, error) {
+	tags, err := s.tagRepo.List(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.tagRepo.ListAllBillTags(scopedDB)
 	if err != nil {
 		return nil, err
 	}
-	bill.IsPaid = isPaid
+	tagged := make(map[string]map[string]bool, len(links))
+	for _, link := range links {
+		if tagged[link.BillID] == nil {
+			tagged[link.BillID] = map[string]bool{}
+		}
+		tagged[link.BillID][link.TagID] = true
+	}
+	byBill := make(map[string][]*models.Tag, len(tagged))
+	for billID, tagIDs := range tagged {
+		for _, tag := range tags {
+			if tagIDs[tag.ID] {
+				byBill[billID] = append(byBill[billID], tag)
+			}
+		}
+	}
+	return byBill, nil
+}
 
-	return bill, nil
+// payeesByID loads the payees of the tenant, keyed by ID
+func (s *BillService) payeesByID(scopedDB *gorm.DB) (map[string]*models.Payee, error) {
+	payees, err := s.payeeRepo.List(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*models.Payee, len(payees))
+	for _, payee := range payees {
+		byID[payee.ID] = payee
+	}
+	return byID, nil
+}
+
+// saveLoan stores the loan terms of a loan bill, or removes them when the bill is not a loan
+func (s *BillService) saveLoan(scopedDB *gorm.DB, bill *models.Bill) error {
+	if bill.Kind != models.BillKindLoan {
+		return s.loanRepo.Delete(scopedDB, bill.ID)
+	}
+	bill.Loan.BillID = bill.ID
+	bill.Loan.UserID = bill.UserID
+	if err := s.loanRepo.Save(scopedDB, bill.Loan); err != nil {
+		return fmt.Errorf("failed to save loan terms: %w", err)
+	}
+	return nil
 }
 
-// GetStats retrieves bill statistics
-func (s *BillService) GetStats(scopedDB *gorm.DB) (*models.BillStats, error) {
-	stats, err := s.repo.GetStats(scopedDB)
+// loadSchedules loads every bill pause and skip for the tenant, grouped by bill, along with the user's holidays
+func (s *BillService) loadSchedules(scopedDB *gorm.DB) (*billSchedules, error) {
+	pauses, err := s.scheduleRepo.ListAllPauses(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+	skips, err := s.scheduleRepo.ListAllSkips(scopedDB)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get all bills for user to calculate paid/unpaid stats
-	bills, err := s.List(scopedDB)
+	userCalendar, err := s.holidays.userCalendar(scopedDB)
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate paid/unpaid counts and due amount based on computed is_paid field
-	for _, bill := range bills {
-		if bill.IsPaid {
-			stats.PaidBills++
-		} else {
-			stats.UnpaidBills++
-			stats.DueAmount += bill.Amount
+	schedules := &billSchedules{
+		pauses:       make(map[string][]*models.BillPause),
+		skips:        make(map[string][]*models.BillSkip),
+		holidays:     s.holidays,
+		userCalendar: userCalendar,
+		loc:          userLocation(scopedDB),
+	}
+	for _, pause := range pauses {
+		schedules.pauses[pause.BillID] = append(schedules.pauses[pause.BillID], pause)
+	}
+	for _, skip := range skips {
+		schedules.skips[skip.BillID] = append(schedules.skips[skip.BillID], skip)
+	}
+	return schedules, nil
+}
+
+// applySchedule sets the status of a bill, moves its next due date past skipped and paused occurrences
+// and then rolls it off weekends and holidays
+func applySchedule(bill *models.Bill, schedule *billSchedule) {
+	bill.Status = models.BillStatusActive
+	if bill.NextDueDate == nil {
+		return
+	}
+	if bill.RecurrenceType != "none" {
+		bill.Status, bill.NextDueDate, _ = schedule.resolve(*bill.NextDueDate)
+		if bill.NextDueDate == nil {
+			return
 		}
 	}
 
-	return stats, nil
+	if rolled := schedule.rolled(*bill.NextDueDate); !rolled.Equal(*bill.NextDueDate) {
+		nominal := *bill.NextDueDate
+		bill.NominalDueDate = &nominal
+		bill.NextDueDate = &rolled
+	}
 }
 
-// List retrieves all bills
-func (s *BillService) List(scopedDB *gorm.DB) ([]*models.Bill, error) {
-	bills, err := s.repo.List(scopedDB)
-	if err != nil {
-		return nil, err
+// newBillVersion captures the versioned fields of a bill
+func newBillVersion(bill *models.Bill, effectiveFrom time.Time) *models.BillVersion {
+	return &models.BillVersion{
+		BillID:         bill.ID,
+		UserID:         bill.UserID,
+		Amount:         bill.Amount,
+		RecurrenceType: bill.RecurrenceType,
+		RecurrenceDays: bill.RecurrenceDays,
+		CategoryID:     bill.CategoryID,
+		EffectiveFrom:  effectiveFrom,
 	}
-	return s.enrichWithPaymentStatus(scopedDB, bills)
 }
 
-// Update updates an existing bill
-func (s *BillService) Update(scopedDB *gorm.DB, bill *models.Bill) error {
-	// Validate recurrence_days based on recurrence_type
-	if err := s.validateRecurrence(bill); err != nil {
-		return err
+// billVersionChanged reports whether an update changes any versioned field
+func billVersionChanged(before, after *models.Bill) bool {
+	return before.Amount != after.Amount ||
+		before.RecurrenceType != after.RecurrenceType ||
+		before.RecurrenceDays != after.RecurrenceDays ||
+		!reflect.DeepEqual(before.CategoryID, after.CategoryID)
+}
+
+// versionAt returns the version of a bill in effect at t. Dates before the first version use the
+// first version; bills without any recorded version fall back to their current values.
+func versionAt(bill *models.Bill, versions []*models.BillVersion, t time.Time) *models.BillVersion {
+	if len(versions) == 0 {
+		return newBillVersion(bill, bill.CreatedAt)
+	}
+	current := versions[0]
+	for _, version := range versions[1:] {
+		if version.EffectiveFrom.After(t) {
+			break
+		}
+		current = version
 	}
-	return s.repo.Update(scopedDB, bill)
+	return current
 }
 
-// Delete deletes a bill
-func (s *BillService) Delete(scopedDB *gorm.DB, id string) error {
-	return s.repo.Delete(scopedDB, id)
+// nextOccurrence returns the due date following due for a bill version in loc, or false if it does not recur
+func nextOccurrence(version *models.BillVersion, due time.Time, loc *time.Location) (time.Time, bool) {
+	switch version.RecurrenceType {
+	case "fixed_date":
+		return utils.CalculateNextDueDateAfterPayment(version.RecurrenceDays, due, loc), true
+	case "interval":
+		return utils.CalculateNextDueDateAfterPaymentInterval(version.RecurrenceDays, due, loc), true
+	default:
+		return time.Time{}, false
+	}
 }
 
-// =============================================================================
-// Payment CRUD Methods
-// =============================================================================
+// addOccurrence appends an occurrence of a bill to a forecast. Skipped occurrences are listed but not totalled.
+func addOccurrence(forecast *models.BillForecast, bill *models.Bill, occurrence *models.ForecastOccurrence) {
+	occurrence.BillID = bill.ID
+	occurrence.BillName = bill.Name
+	forecast.Occurrences = append(forecast.Occurrences, occurrence)
+	if !occurrence.Skipped {
+		forecast.TotalAmount += occurrence.Amount
+	}
+}
 
-// CreatePayment creates a payment for a bill
-func (s *BillService) CreatePayment(scopedDB *gorm.DB, payment *models.Payment) error {
-	// First verify the bill exists and belongs to the user
-	_, err := s.repo.Get(scopedDB, payment.BillID)
+// countedPayments returns the payments that were not reversed, oldest first
+func countedPayments(payments []*models.Payment) []*models.Payment {
+	counted := make([]*models.Payment, 0, len(payments))
+	for i := len(payments) - 1; i >= 0; i-- {
+		if payments[i].ReversedAt == nil {
+			counted = append(counted, payments[i])
+		}
+	}
+	return counted
+}
+
+// verifyRecurring ensures a bill exists, belongs to the user and recurs
+func (s *BillService) verifyRecurring(scopedDB *gorm.DB, billID string) error {
+	bill, err := s.repo.Get(scopedDB, billID)
 	if err != nil {
 		return err
 	}
-	// Create the payment
-	return s.paymentRepo.Create(scopedDB, payment)
+	if bill.RecurrenceType == "none" {
+		return fmt.Errorf("only recurring bills can be paused or skipped")
+	}
+	return nil
 }
 
-// ListPayments retrieves all payments for a bill
-func (s *BillService) ListPayments(scopedDB *gorm.DB, billID string) ([]*models.Payment, error) {
-	return s.paymentRepo.List(scopedDB, billID)
-}
+// validateReferences ensures any records the bill points at belong to the same tenant. A category
+// in the trash may stay on the bill it was already on, before, but cannot be newly set.
+func (s *BillService) validateReferences(scopedDB *gorm.DB, bill, before *models.Bill) error {
+	// An empty category_id, payee_id or account_id means none; store NULL rather than an empty string
+	if bill.CategoryID != nil && *bill.CategoryID == "" {
+		bill.CategoryID = nil
+	}
+	if bill.PayeeID != nil && *bill.PayeeID == "" {
+		bill.PayeeID = nil
+	}
+	if bill.AccountID != nil && *bill.AccountID == "" {
+		bill.AccountID = nil
+	}
 
-// DeletePayment deletes a payment
-func (s *BillService) DeletePayment(scopedDB *gorm.DB, paymentID string) error {
-	return s.paymentRepo.Delete(scopedDB, paymentID)
-}
+	var storedCategoryID *string
+	if before != nil {
+		storedCategoryID = before.CategoryID
+	}
+	refs := []repository.Reference{
+		{Field: "category_id", Model: &models.Category{}, ID: bill.CategoryID, Stored: storedCategoryID},
+		{Field: "payee_id", Model: &models.Payee{}, ID: bill.PayeeID},
+		{Field: "account_id", Model: &models.FundingAccount{}, ID: bill.AccountID},
+	}
 
-// =============================================================================
-// Private Helper Methods
-// =============================================================================
+	// Each tag is stored once; an empty list removes all tags
+	if bill.TagIDs != nil {
+		tagIDs := []string{}
+		for _, tagID := range bill.TagIDs {
+			if !slices.Contains(tagIDs, tagID) {
+				tagIDs = append(tagIDs, tagID)
+				refs = append(refs, repository.Reference{Field: "tag_ids", Model: &models.Tag{}, ID: &tagID})
+			}
+		}
+		bill.TagIDs = tagIDs
+	}
+	return repository.VerifyOwnership(scopedDB, refs...)
+}
 
 // calculateIsPaid determines if a bill is considered paid
-func (s *BillService) calculateIsPaid(scopedDB *gorm.DB, bill *models.Bill) (bool, error) {
+func (s *BillService) calculateIsPaid(bill *models.Bill, latestPayment *models.Payment) bool {
 	if bill.RecurrenceType != "none" {
 		// For recurring bills (fixed_date or interval): check if next due date is at least grace_days in the future
+		// Nothing is owed on an ended bill or one paused until further notice
 		if bill.NextDueDate == nil {
-			return false, nil
+			return bill.Status != models.BillStatusActive
 		}
 		graceDays := time.Duration(s.config.Bills.PaymentGraceDays) * 24 * time.Hour
-		return time.Until(*bill.NextDueDate) >= graceDays, nil
+		return time.Until(*bill.NextDueDate) >= graceDays
 	}
 
 	// For non-recurring bills: check if there's a payment record
-	payment, err := s.paymentRepo.GetLatest(scopedDB, bill.ID)
-	if err != nil {
-		return false, err
-	}
-	return payment != nil, nil
+	return latestPayment != nil
 }
 
-// calculateNextDueDate determines the next due date for a bill based on recurrence_type and payment history
-func (s *BillService) calculateNextDueDate(scopedDB *gorm.DB, bill *models.Bill) (*time.Time, *time.Time, error) {
+// calculateNextDueDate determines the next due date for a bill based on recurrence_type and its most
+// recent payment that was not reversed
+func (s *BillService) calculateNextDueDate(bill *models.Bill, schedule *billSchedule, latestPayment *models.Payment) (*time.Time, *time.Time, error) {
 	// Non-recurring bills don't have a next due date
 	if bill.RecurrenceType == "none" {
 		// For one-time bills, use start_date as the due date if available
@@ -173,12 +1431,6 @@ func (s *BillService) calculateNextDueDate(scopedDB *gorm.DB, bill *models.Bill)
 		return nil, nil, nil
 	}
 
-	// Get the most recent payment
-	latestPayment, err := s.paymentRepo.GetLatest(scopedDB, bill.ID)
-	if err != nil {
-		return nil, nil, err
-	}
-
 	var nextDue time.Time
 	var lastPaidPtr *time.Time
 
@@ -190,9 +1442,9 @@ func (s *BillService) calculateNextDueDate(scopedDB *gorm.DB, bill *models.Bill)
 		// Calculate next due date based on recurrence type
 		switch bill.RecurrenceType {
 		case "fixed_date":
-			nextDue = utils.CalculateNextDueDateAfterPayment(bill.RecurrenceDays, latestPayment.PaymentDate)
+			nextDue = utils.CalculateNextDueDateAfterPayment(bill.RecurrenceDays, schedule.scheduledDate(latestPayment.PaymentDate), schedule.loc)
 		case "interval":
-			nextDue = utils.CalculateNextDueDateAfterPaymentInterval(bill.RecurrenceDays, latestPayment.PaymentDate)
+			nextDue = utils.CalculateNextDueDateAfterPaymentInterval(bill.RecurrenceDays, latestPayment.PaymentDate, schedule.loc)
 		default:
 			return nil, nil, fmt.Errorf("unknown recurrence_type: %s", bill.RecurrenceType)
 		}
@@ -205,9 +1457,9 @@ func (s *BillService) calculateNextDueDate(scopedDB *gorm.DB, bill *models.Bill)
 
 		switch bill.RecurrenceType {
 		case "fixed_date":
-			nextDue = utils.CalculateNextDueDate(bill.RecurrenceDays, referenceDate)
+			nextDue = utils.CalculateNextDueDate(bill.RecurrenceDays, referenceDate, schedule.loc)
 		case "interval":
-			nextDue = utils.CalculateNextDueDateInterval(bill.RecurrenceDays, referenceDate)
+			nextDue = utils.CalculateNextDueDateInterval(bill.RecurrenceDays, referenceDate, schedule.loc)
 		default:
 			return nil, nil, fmt.Errorf("unknown recurrence_type: %s", bill.RecurrenceType)
 		}
@@ -216,25 +1468,199 @@ func (s *BillService) calculateNextDueDate(scopedDB *gorm.DB, bill *models.Bill)
 	return &nextDue, lastPaidPtr, nil
 }
 
-// enrichWithPaymentStatus calculates the is_paid status and next_due_date for bills
+// enrichWithPaymentStatus calculates the is_paid status, status and next_due_date for bills. Payments
+// are loaded for all bills at once so the number of queries does not grow with the number of bills.
 func (s *BillService) enrichWithPaymentStatus(scopedDB *gorm.DB, bills []*models.Bill) ([]*models.Bill, error) {
+	if _, err := s.enrich(scopedDB, bills, false); err != nil {
+		return nil, err
+	}
+	return bills, nil
+}
+
+// billHistory holds the versions and statements of the tenant's bills loaded while enriching them,
+// keyed by bill, for callers that price occurrences afterwards
+type billHistory struct {
+	versions   map[string][]*models.BillVersion // Only loaded when asked for or needed for late fees
+	statements map[string][]*models.BillStatement
+}
+
+// enrich does the work of enrichWithPaymentStatus and returns the versions and statements it loaded.
+// Versions are always loaded when withVersions is set.
+func (s *BillService) enrich(scopedDB *gorm.DB, bills []*models.Bill, withVersions bool) (*billHistory, error) {
+	schedules, err := s.loadSchedules(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+	loans, err := s.loansByBill(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+	statements, err := s.statementsByBill(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+	lateFeeRules, waivers, err := s.lateFeesByBill(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.tagsByBill(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+	payees, err := s.payeesByID(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+	// Versions are only needed to price overdue occurrences
+	var versions map[string][]*models.BillVersion
+	if withVersions || len(lateFeeRules) > 0 {
+		if versions, err = s.versionsByBill(scopedDB); err != nil {
+			return nil, err
+		}
+	}
+
+	// The latest payment decides the next due date; loans and statements need every payment
+	billIDs := make([]string, 0, len(bills))
+	var historyIDs []string
+	for _, bill := range bills {
+		billIDs = append(billIDs, bill.ID)
+		if loans[bill.ID] != nil || (bill.VariableAmount && len(statements[bill.ID]) > 0) {
+			historyIDs = append(historyIDs, bill.ID)
+		}
+	}
+	latestPayments, err := s.paymentRepo.GetLatestForBills(scopedDB, billIDs)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.paymentRepo.ListForBills(scopedDB, historyIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	today := utils.NowInAppTimezone()
 	for _, bill := range bills {
 		// Calculate next due date
-		nextDue, lastPaid, err := s.calculateNextDueDate(scopedDB, bill)
+		schedule := schedules.forBill(bill)
+		nextDue, lastPaid, err := s.calculateNextDueDate(bill, schedule, latestPayments[bill.ID])
 		if err != nil {
 			return nil, err
 		}
 		bill.NextDueDate = nextDue
 		bill.LastPaidDate = lastPaid
+		applySchedule(bill, schedule)
+
+		bill.Tags = tags[bill.ID]
+		bill.TagIDs = make([]string, 0, len(bill.Tags))
+		for _, tag := range bill.Tags {
+			bill.TagIDs = append(bill.TagIDs, tag.ID)
+		}
+		if bill.PayeeID != nil {
+			bill.Payee = payees[*bill.PayeeID]
+		}
+
+		// A repaid loan has nothing left to pay
+		bill.Loan = loans[bill.ID]
+		if bill.Loan != nil {
+			if loanBalance(bill, bill.Loan, countedPayments(payments[bill.ID])) <= paidOffThreshold {
+				bill.Status = models.BillStatusPaidOff
+				bill.NextDueDate = nil
+				bill.NominalDueDate = nil
+			}
+		}
+
+		// Variable amount bills report how much of their current statement was paid
+		if bill.VariableAmount {
+			applyStatements(bill, statements[bill.ID], payments[bill.ID], schedule.loc)
+		}
 
 		// Calculate is_paid status
-		isPaid, err := s.calculateIsPaid(scopedDB, bill)
-		if err != nil {
-			return nil, err
+		bill.IsPaid = s.calculateIsPaid(bill, latestPayments[bill.ID])
+
+		// Overdue occurrences accrue late fees once the rule's grace days have passed
+		bill.LateFeeRule = lateFeeRules[bill.ID]
+		if bill.LateFeeRule != nil {
+			amountOn := func(due, rolled time.Time) float64 {
+				amount, _ := occurrenceAmount(bill, versions[bill.ID], statements[bill.ID], due, rolled, schedule.loc)
+				return amount
+			}
+			bill.LateFees = lateFees(bill, schedule, waivers[bill.ID], amountOn, today)
+			bill.LateFeeTotal = 0
+			for _, fee := range bill.LateFees {
+				if !fee.Waived {
+					bill.LateFeeTotal += fee.Amount
+				}
+			}
+			bill.LateFeeTotal = roundCents(bill.LateFeeTotal)
 		}
-		bill.IsPaid = isPaid
 	}
-	return bills, nil
+	return &billHistory{versions: versions, statements: statements}, nil
+}
+
+// validateKind validates the kind of a bill. The amount and schedule of a loan bill are derived from
+// its loan terms.
+func (s *BillService) validateKind(bill *models.Bill, loc *time.Location) error {
+	if bill.Kind == "" {
+		bill.Kind = models.BillKindStandard
+	}
+
+	switch bill.Kind {
+	case models.BillKindStandard:
+		bill.Loan = nil
+		// Variable amount bills may leave the amount to their statements
+		if bill.Amount < 0 || (bill.Amount == 0 && !bill.VariableAmount) {
+			return fmt.Errorf("amount must be greater than 0")
+		}
+	case models.BillKindLoan:
+		loan := bill.Loan
+		if loan == nil {
+			return fmt.Errorf("loan terms are required for loan bills")
+		}
+		if bill.RecurrenceType != "fixed_date" && bill.RecurrenceType != "interval" {
+			return fmt.Errorf("loan bills must recur on a fixed date or interval")
+		}
+		if bill.VariableAmount {
+			return fmt.Errorf("loan bills cannot have a variable amount")
+		}
+		if bill.EndDate != nil || bill.MaxOccurrences != nil {
+			return fmt.Errorf("loan bills end when the loan is repaid, end_date and max_occurrences do not apply")
+		}
+		if loan.Principal <= 0 {
+			return fmt.Errorf("loan principal must be greater than 0")
+		}
+		if loan.InterestRate < 0 {
+			return fmt.Errorf("loan interest_rate must not be negative")
+		}
+		if loan.TermPayments < 1 {
+			return fmt.Errorf("loan term_payments must be at least 1")
+		}
+		if loan.FirstPaymentDate.IsZero() {
+			return fmt.Errorf("loan first_payment_date is required")
+		}
+		if bill.RecurrenceType == "interval" && bill.RecurrenceDays < 1 {
+			return fmt.Errorf("recurrence_days must be at least 1 for interval bills")
+		}
+		applyLoanTerms(bill, loan, loc)
+	default:
+		return fmt.Errorf("invalid kind: must be 'standard' or 'loan'")
+	}
+	return nil
+}
+
+// validateLateFeeRule validates the late fee rule of a bill, if it has one
+func validateLateFeeRule(rule *models.BillLateFeeRule) error {
+	if rule == nil {
+		return nil
+	}
+	if rule.FlatFee < 0 || rule.Percentage < 0 || rule.DailyFee < 0 {
+		return fmt.Errorf("late fee amounts must not be negative")
+	}
+	if rule.GraceDays < 0 {
+		return fmt.Errorf("late fee grace_days must not be negative")
+	}
+	if rule.MaxFee != nil && *rule.MaxFee < 0 {
+		return fmt.Errorf("late fee max_fee must not be negative")
+	}
+	return nil
 }
 
 // validateRecurrence validates the recurrence settings of a bill
@@ -265,5 +1691,21 @@ func (s *BillService) validateRecurrence(bill *models.Bill) error {
 		return fmt.Errorf("unknown recurrence_type: %s", bill.RecurrenceType)
 	}
 
-	return nil
+	// End conditions only apply to recurring bills
+	if bill.RecurrenceType == "none" && (bill.EndDate != nil || bill.MaxOccurrences != nil) {
+		return fmt.Errorf("end_date and max_occurrences only apply to recurring bills")
+	}
+	if bill.EndDate != nil && bill.StartDate != nil && bill.EndDate.Before(*bill.StartDate) {
+		return fmt.Errorf("end_date must not be before start_date")
+	}
+
+	// Validate how due dates on weekends and holidays are moved
+	if bill.BusinessDayRoll == "" {
+		bill.BusinessDayRoll = holidays.RollNone
+	}
+	if err := holidays.ValidateRoll(bill.BusinessDayRoll); err != nil {
+		return err
+	}
+	bill.HolidayCalendar = strings.ToUpper(bill.HolidayCalendar)
+	return s.holidays.ValidateCalendar(bill.HolidayCalendar)
 }
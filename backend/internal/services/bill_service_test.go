@@ -0,0 +1,226 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cryptk/williams/internal/api/middleware"
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// countQueries counts the SELECT statements run on the database from now on. Subqueries are built with
+// a dry run and are not counted.
+func (env *testEnv) countQueries(tb testing.TB) *atomic.Int64 {
+	tb.Helper()
+	var queries atomic.Int64
+	if err := env.db.Callback().Query().After("gorm:query").Register("test:count_queries", func(tx *gorm.DB) {
+		if !tx.DryRun {
+			queries.Add(1)
+		}
+	}); err != nil {
+		tb.Fatal(err)
+	}
+	return &queries
+}
+
+// seedDashboard creates a user with the given number of bills the way the dashboard sees them: every
+// bill has monthly payments, half of them stopped paying a few months ago, every fifth bill has a
+// variable amount with statements and every seventh charges late fees. It returns the user's scoped DB.
+func (env *testEnv) seedDashboard(tb testing.TB, bills int) *gorm.DB {
+	tb.Helper()
+	user := &models.User{
+		ID:           uuid.New().String(),
+		Username:     "user-" + uuid.New().String()[:8],
+		Email:        uuid.New().String() + "@example.com",
+		PasswordHash: "x",
+		Roles:        []string{"user"},
+	}
+	if err := env.db.Create(user).Error; err != nil {
+		tb.Fatal(err)
+	}
+
+	created := time.Now().AddDate(-1, 0, 0)
+	var records []any
+	for i := range bills {
+		bill := &models.Bill{
+			ID:             uuid.New().String(),
+			UserID:         user.ID,
+			Name:           fmt.Sprintf("Bill %d", i),
+			Amount:         float64(50 + i%100),
+			RecurrenceDays: 1 + i%28,
+			RecurrenceType: "fixed_date",
+			Kind:           models.BillKindStandard,
+			VariableAmount: i%5 == 0,
+			CreatedAt:      created,
+		}
+		records = append(records, bill, &models.BillVersion{
+			ID:             uuid.New().String(),
+			BillID:         bill.ID,
+			UserID:         user.ID,
+			Amount:         bill.Amount,
+			RecurrenceType: bill.RecurrenceType,
+			RecurrenceDays: bill.RecurrenceDays,
+			EffectiveFrom:  created,
+		})
+		months := 12
+		if i%2 == 1 {
+			months = 8
+		}
+		for n := range months {
+			records = append(records, &models.Payment{
+				ID:          uuid.New().String(),
+				BillID:      bill.ID,
+				UserID:      user.ID,
+				Amount:      bill.Amount,
+				PaymentDate: created.AddDate(0, n, 0),
+			})
+		}
+		if bill.VariableAmount {
+			for n := range 2 {
+				records = append(records, &models.BillStatement{
+					ID:      uuid.New().String(),
+					BillID:  bill.ID,
+					UserID:  user.ID,
+					DueDate: created.AddDate(0, 10+n, 0),
+					Amount:  bill.Amount + float64(n*10),
+				})
+			}
+		}
+		if i%7 == 0 {
+			records = append(records, &models.BillLateFeeRule{BillID: bill.ID, UserID: user.ID, FlatFee: 5, GraceDays: 3})
+		}
+	}
+	for _, record := range records {
+		if err := env.db.Create(record).Error; err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return env.db.WithContext(context.Background()).Scopes(middleware.TenantScoped(user.ID))
+}
+
+func TestGetStatsQueriesDoNotGrowWithBills(t *testing.T) {
+	env := newTestEnv(t)
+	few := env.seedDashboard(t, 5)
+	many := env.seedDashboard(t, 60)
+	queries := env.countQueries(t)
+
+	if _, err := env.bills.GetStats(few, repository.BillFilter{}); err != nil {
+		t.Fatal(err)
+	}
+	fewQueries := queries.Swap(0)
+	stats, err := env.bills.GetStats(many, repository.BillFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := queries.Load(); n != fewQueries {
+		t.Errorf("GetStats ran %d queries for 60 bills and %d for 5", n, fewQueries)
+	}
+
+	bills, err := env.bills.List(many, repository.BillFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total float64
+	for _, bill := range bills {
+		total += bill.Amount
+	}
+	if stats.TotalBills != len(bills) || stats.TotalAmount != total {
+		t.Errorf("got %d bills totalling %.2f, want %d totalling %.2f", stats.TotalBills, stats.TotalAmount, len(bills), total)
+	}
+	if stats.PaidBills+stats.UnpaidBills+stats.PausedBills+stats.EndedBills != stats.TotalBills {
+		t.Errorf("bill counts %+v do not add up to the total", stats)
+	}
+	if stats.UnpaidBills == 0 || stats.LateFees == 0 {
+		t.Errorf("expected unpaid bills with late fees, got %+v", stats)
+	}
+}
+
+// BenchmarkGetStats measures the dashboard statistics, which used to run 2N+3 queries for N bills.
+// Run with:
+//
+//	go test ./internal/services -run '^$' -bench GetStats
+func BenchmarkGetStats(b *testing.B) {
+	for _, bills := range []int{100, 1000} {
+		b.Run(fmt.Sprintf("bills=%d", bills), func(b *testing.B) {
+			env := newTestEnv(b)
+			scopedDB := env.seedDashboard(b, bills)
+			queries := env.countQueries(b)
+			for b.Loop() {
+				if _, err := env.bills.GetStats(scopedDB, repository.BillFilter{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(queries.Load())/float64(b.N), "queries/op")
+		})
+	}
+}
+
+// failStatementCreate makes the nth statement created from now on fail to save
+func (env *testEnv) failStatementCreate(t *testing.T, n int64) {
+	t.Helper()
+	var created atomic.Int64
+	if err := env.db.Callback().Create().Before("gorm:create").Register("test:fail_statement_create", func(tx *gorm.DB) {
+		if tx.Statement.Table == "bill_statements" && created.Add(1) == n {
+			tx.AddError(errors.New("disk full"))
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportStatementsSavesAllOrNothing(t *testing.T) {
+	env := newTestEnv(t)
+	tn := env.newTenant(t, "alice")
+	bill := &models.Bill{
+		UserID:         tn.userID,
+		Name:           "Power",
+		Amount:         100,
+		VariableAmount: true,
+		RecurrenceType: "fixed_date",
+		RecurrenceDays: 15,
+	}
+	if err := env.bills.Create(tn.db, bill); err != nil {
+		t.Fatal(err)
+	}
+	dueDate := func(month time.Month) time.Time {
+		return time.Date(2026, month, 15, 12, 0, 0, 0, time.UTC)
+	}
+	if err := env.bills.SaveStatement(tn.db, &models.BillStatement{BillID: bill.ID, DueDate: dueDate(time.September), Amount: 95}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The import replaces September and adds two statements, the second of which cannot be saved
+	env.failStatementCreate(t, 2)
+	err := env.bills.ImportStatements(tn.db, bill.ID, []*models.BillStatement{
+		{DueDate: dueDate(time.September), Amount: 80},
+		{DueDate: dueDate(time.October), Amount: 110},
+		{DueDate: dueDate(time.November), Amount: 120},
+	})
+	if err == nil {
+		t.Fatal("import succeeded")
+	}
+
+	statements, err := env.bills.ListStatements(tn.db, bill.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statements) != 1 || statements[0].Amount != 95 {
+		t.Errorf("statements after the failed import: got %d, want only September's for 95", len(statements))
+	}
+	var audited int64
+	if err := env.db.Model(&models.AuditEvent{}).
+		Where("action = ?", models.AuditActionStatementSave).
+		Count(&audited).Error; err != nil {
+		t.Fatal(err)
+	}
+	if audited != 1 {
+		t.Errorf("got %d statement audit events, want only the one for the statement saved before the import", audited)
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+)
+
+// statementAverageWindow is how many of the most recent statements the estimate of a variable amount
+// bill averages
+const statementAverageWindow = 3
+
+// statementOn returns the statement of the occurrence scheduled on due. Statements may name either the
+// scheduled due date or the business day it is rolled to.
+func statementOn(statements []*models.BillStatement, due, rolled time.Time, loc *time.Location) *models.BillStatement {
+	day := calendarDay(due, loc)
+	rolledDay := calendarDay(rolled, loc)
+	for _, statement := range statements {
+		statementDay := calendarDay(statement.DueDate, loc)
+		if statementDay.Equal(day) || statementDay.Equal(rolledDay) {
+			return statement
+		}
+	}
+	return nil
+}
+
+// estimateAmount averages the most recent statements, oldest first, falling back to the bill's amount
+// when there are none
+func estimateAmount(statements []*models.BillStatement, fallback float64) float64 {
+	if len(statements) == 0 {
+		return fallback
+	}
+	recent := statements[max(0, len(statements)-statementAverageWindow):]
+	total := 0.0
+	for _, statement := range recent {
+		total += statement.Amount
+	}
+	return roundCents(total / float64(len(recent)))
+}
+
+// applyStatementPayments sets the paid amount and payment status of each statement, oldest first. A
+// payment counts toward the first statement due on or after its payment date; payments made after the
+// latest statement was due count toward it.
+func applyStatementPayments(statements []*models.BillStatement, payments []*models.Payment, loc *time.Location) {
+	for _, statement := range statements {
+		statement.PaidAmount = 0
+	}
+	if len(statements) == 0 {
+		return
+	}
+	for _, payment := range payments {
+		target := statements[len(statements)-1]
+		paidDay := calendarDay(payment.PaymentDate, loc)
+		for _, statement := range statements {
+			if !calendarDay(statement.DueDate, loc).Before(paidDay) {
+				target = statement
+				break
+			}
+		}
+		target.PaidAmount += payment.Amount
+	}
+	for _, statement := range statements {
+		statement.PaidAmount = roundCents(statement.PaidAmount)
+		statement.PaymentStatus = statementPaymentStatus(statement)
+	}
+}
+
+// statementPaymentStatus distinguishes a statement paid in full from one where only the minimum was paid
+func statementPaymentStatus(statement *models.BillStatement) string {
+	switch {
+	case statement.PaidAmount >= statement.Amount:
+		return models.PaymentStatusPaid
+	case statement.MinimumPayment != nil && statement.PaidAmount >= *statement.MinimumPayment:
+		return models.PaymentStatusMinimumPaid
+	default:
+		return models.PaymentStatusUnpaid
+	}
+}
+
+// currentStatement returns the latest statement due on or before due, or the latest statement when due is nil
+func currentStatement(statements []*models.BillStatement, due *time.Time, loc *time.Location) *models.BillStatement {
+	var current *models.BillStatement
+	for _, statement := range statements {
+		if due != nil && calendarDay(statement.DueDate, loc).After(calendarDay(*due, loc)) {
+			break
+		}
+		current = statement
+	}
+	return current
+}
+
+// occurrenceAmount returns the amount due on an occurrence of a bill and whether it is an estimate.
+// Variable amount bills use the statement of the occurrence, or their estimate until one is entered.
+func occurrenceAmount(bill *models.Bill, versions []*models.BillVersion, statements []*models.BillStatement, due, rolled time.Time, loc *time.Location) (float64, bool) {
+	version := versionAt(bill, versions, due)
+	if !bill.VariableAmount {
+		return version.Amount, false
+	}
+	if statement := statementOn(statements, due, rolled, loc); statement != nil {
+		return statement.Amount, false
+	}
+	return estimateAmount(statements, version.Amount), true
+}
@@ -3,32 +3,90 @@ package services
 import (
 	"github.com/cryptk/williams/internal/models"
 	"github.com/cryptk/williams/internal/repository"
+	"github.com/cryptk/williams/pkg/utils"
 	"gorm.io/gorm"
 )
 
 // CategoryService handles business logic for categories
 type CategoryService struct {
-	repo repository.CategoryRepository
+	repo  repository.CategoryRepository
+	audit *AuditService
 }
 
 // NewCategoryService creates a new category service
-func NewCategoryService(repo repository.CategoryRepository) *CategoryService {
-	return &CategoryService{repo: repo}
+func NewCategoryService(repo repository.CategoryRepository, audit *AuditService) *CategoryService {
+	return &CategoryService{repo: repo, audit: audit}
 }
 
 // Create creates a new category
 func (s *CategoryService) Create(scopedDB *gorm.DB, category *models.Category) error {
-	return s.repo.Create(scopedDB, category)
+	// New categories always start active
+	category.ArchivedAt = nil
+	category.DeletedAt = gorm.DeletedAt{}
+
+	if err := s.repo.Create(scopedDB, category); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionCategoryCreate,
+		EntityType: "category",
+		EntityID:   category.ID,
+		Changes:    auditDiff(nil, category),
+	})
+	return nil
 }
 
-// List retrieves all categories
-func (s *CategoryService) List(scopedDB *gorm.DB) ([]*models.Category, error) {
-	return s.repo.List(scopedDB)
+// List retrieves all categories that are not in the trash
+func (s *CategoryService) List(scopedDB *gorm.DB, opts repository.ListOptions) ([]*models.Category, error) {
+	return s.repo.List(scopedDB, opts)
 }
 
-// Delete deletes a category
+// Delete moves a category to the trash
 func (s *CategoryService) Delete(scopedDB *gorm.DB, id string) error {
-	return s.repo.Delete(scopedDB, id)
+	if err := s.repo.Delete(scopedDB, id); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionCategoryDelete,
+		EntityType: "category",
+		EntityID:   id,
+	})
+	return nil
+}
+
+// Archive hides a category from lists while keeping it on existing bills
+func (s *CategoryService) Archive(scopedDB *gorm.DB, id string) error {
+	now := utils.NowInAppTimezone()
+	if err := s.repo.SetArchived(scopedDB, id, &now); err != nil {
+		return err
+	}
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{Action: models.AuditActionCategoryArchive, EntityType: "category", EntityID: id})
+	return nil
+}
+
+// Unarchive returns an archived category to the active list
+func (s *CategoryService) Unarchive(scopedDB *gorm.DB, id string) error {
+	if err := s.repo.SetArchived(scopedDB, id, nil); err != nil {
+		return err
+	}
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{Action: models.AuditActionCategoryUnarchive, EntityType: "category", EntityID: id})
+	return nil
+}
+
+// ListTrash retrieves the categories in the trash
+func (s *CategoryService) ListTrash(scopedDB *gorm.DB) ([]*models.Category, error) {
+	return s.repo.ListDeleted(scopedDB)
+}
+
+// Restore moves a category out of the trash
+func (s *CategoryService) Restore(scopedDB *gorm.DB, id string) error {
+	if err := s.repo.Restore(scopedDB, id); err != nil {
+		return err
+	}
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{Action: models.AuditActionCategoryRestore, EntityType: "category", EntityID: id})
+	return nil
 }
 
 // CreateDefaults creates default categories for a new user
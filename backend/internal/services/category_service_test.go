@@ -0,0 +1,57 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/cryptk/williams/internal/models"
+)
+
+func TestBillKeepsCategoryMovedToTrash(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.newTenant(t, "alice")
+
+	if err := env.categories.Delete(alice.db, alice.categoryID); err != nil {
+		t.Fatal(err)
+	}
+
+	// The bill can still be saved with the category it already had
+	bill := *alice.bill
+	bill.Name = "Rent and parking"
+	if err := env.bills.Update(alice.db, &bill); err != nil {
+		t.Fatalf("updating a bill whose category is in the trash: %v", err)
+	}
+	saved, err := env.bills.Get(alice.db, alice.bill.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Name != "Rent and parking" || saved.CategoryID == nil || *saved.CategoryID != alice.categoryID {
+		t.Errorf("got name %q and category %v, want the update saved with the category kept", saved.Name, saved.CategoryID)
+	}
+
+	// The category cannot be given to other bills while it is in the trash
+	other := &models.Bill{
+		UserID:         alice.userID,
+		Name:           "Parking",
+		Amount:         50,
+		RecurrenceType: "fixed_date",
+		RecurrenceDays: 1,
+		CategoryID:     &alice.categoryID,
+	}
+	assertReferenceError(t, env.bills.Create(alice.db, other), "category_id")
+
+	other.CategoryID = nil
+	if err := env.bills.Create(alice.db, other); err != nil {
+		t.Fatal(err)
+	}
+	update := *other
+	update.CategoryID = &alice.categoryID
+	assertReferenceError(t, env.bills.Update(alice.db, &update), "category_id")
+
+	// Once restored, it can be used again
+	if err := env.categories.Restore(alice.db, alice.categoryID); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.bills.Update(alice.db, &update); err != nil {
+		t.Fatalf("setting a restored category: %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
+	"github.com/cryptk/williams/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// FundingAccountService handles business logic for the accounts bills are paid from
+type FundingAccountService struct {
+	repo        repository.FundingAccountRepository
+	billRepo    repository.BillRepository
+	paymentRepo repository.PaymentRepository
+	bills       *BillService
+	audit       *AuditService
+}
+
+// NewFundingAccountService creates a new funding account service
+func NewFundingAccountService(repo repository.FundingAccountRepository, billRepo repository.BillRepository, paymentRepo repository.PaymentRepository, bills *BillService, audit *AuditService) *FundingAccountService {
+	return &FundingAccountService{
+		repo:        repo,
+		billRepo:    billRepo,
+		paymentRepo: paymentRepo,
+		bills:       bills,
+		audit:       audit,
+	}
+}
+
+// Create creates a new funding account with its current balance as of now
+func (s *FundingAccountService) Create(scopedDB *gorm.DB, account *models.FundingAccount) error {
+	if err := validateFundingAccount(account); err != nil {
+		return err
+	}
+	account.BalanceUpdatedAt = utils.NowInAppTimezone()
+	if err := s.repo.Create(scopedDB, account); err != nil {
+		return err
+	}
+	account.Balance = account.CurrentBalance
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionAccountCreate,
+		EntityType: "account",
+		EntityID:   account.ID,
+		Changes:    auditDiff(nil, account),
+	})
+	return nil
+}
+
+// Get retrieves a funding account
+func (s *FundingAccountService) Get(scopedDB *gorm.DB, id string) (*models.FundingAccount, error) {
+	account, err := s.repo.Get(scopedDB, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyPayments(scopedDB, []*models.FundingAccount{account}); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// List retrieves all funding accounts
+func (s *FundingAccountService) List(scopedDB *gorm.DB) ([]*models.FundingAccount, error) {
+	accounts, err := s.repo.List(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyPayments(scopedDB, accounts); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// Update updates an existing funding account. Entering a new current balance restarts the count of
+// payments deducted from it.
+func (s *FundingAccountService) Update(scopedDB *gorm.DB, account *models.FundingAccount) error {
+	before, err := s.repo.Get(scopedDB, account.ID)
+	if err != nil {
+		return err
+	}
+	if err := validateFundingAccount(account); err != nil {
+		return err
+	}
+	account.BalanceUpdatedAt = before.BalanceUpdatedAt
+	if account.CurrentBalance != before.CurrentBalance {
+		account.BalanceUpdatedAt = utils.NowInAppTimezone()
+	}
+	if err := s.repo.Update(scopedDB, account); err != nil {
+		return err
+	}
+	account.UserID = before.UserID
+	account.CreatedAt = before.CreatedAt
+	if err := s.applyPayments(scopedDB, []*models.FundingAccount{account}); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionAccountUpdate,
+		EntityType: "account",
+		EntityID:   account.ID,
+		Changes:    auditDiff(before, account),
+	})
+	return nil
+}
+
+// Delete deletes a funding account. Its bills and payments are kept without an account.
+func (s *FundingAccountService) Delete(scopedDB *gorm.DB, id string) error {
+	before, err := s.repo.Get(scopedDB, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(scopedDB, id); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionAccountDelete,
+		EntityType: "account",
+		EntityID:   id,
+		Changes:    auditDiff(before, nil),
+	})
+	return nil
+}
+
+// Projection subtracts the unpaid bill occurrences due by the given number of days from now from the
+// balance of the account each bill is paid from, day by day. Overdue occurrences are taken as due today.
+// With an account ID only that account is projected.
+func (s *FundingAccountService) Projection(scopedDB *gorm.DB, days int, accountID string) (*models.BalanceProjection, error) {
+	var accounts []*models.FundingAccount
+	if accountID != "" {
+		account, err := s.Get(scopedDB, accountID)
+		if err != nil {
+			return nil, err
+		}
+		accounts = []*models.FundingAccount{account}
+	} else {
+		var err error
+		if accounts, err = s.List(scopedDB); err != nil {
+			return nil, err
+		}
+	}
+
+	bills, err := s.billRepo.List(scopedDB, repository.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	billAccounts := make(map[string]string, len(bills))
+	for _, bill := range bills {
+		if bill.AccountID != nil {
+			billAccounts[bill.ID] = *bill.AccountID
+		}
+	}
+
+	loc := userLocation(scopedDB)
+	today := calendarDay(utils.NowInAppTimezone(), loc)
+	to := today.AddDate(0, 0, days)
+	forecast, err := s.bills.Forecast(scopedDB, time.Time{}, to, repository.BillFilter{AccountID: accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	projection := &models.BalanceProjection{
+		From:       today,
+		To:         to,
+		Accounts:   make([]*models.AccountProjection, 0, len(accounts)),
+		Unassigned: []*models.ForecastOccurrence{},
+	}
+	byAccount := make(map[string]*models.AccountProjection, len(accounts))
+	for _, account := range accounts {
+		byAccount[account.ID] = &models.AccountProjection{
+			Account:       account,
+			Days:          []*models.ProjectedDay{},
+			EndingBalance: account.Balance,
+			LowestBalance: account.Balance,
+		}
+		projection.Accounts = append(projection.Accounts, byAccount[account.ID])
+	}
+
+	// Occurrences are sorted by due date, so each account's days are built in order
+	for _, occurrence := range forecast.Occurrences {
+		if occurrence.Skipped {
+			continue
+		}
+		accountProjection := byAccount[billAccounts[occurrence.BillID]]
+		if accountProjection == nil {
+			if billAccounts[occurrence.BillID] == "" {
+				projection.Unassigned = append(projection.Unassigned, occurrence)
+			}
+			continue
+		}
+
+		date := calendarDay(occurrence.DueDate, loc)
+		if date.Before(today) {
+			date = today
+		}
+		var day *models.ProjectedDay
+		if n := len(accountProjection.Days); n > 0 && accountProjection.Days[n-1].Date.Equal(date) {
+			day = accountProjection.Days[n-1]
+		} else {
+			day = &models.ProjectedDay{Date: date, Occurrences: []*models.ForecastOccurrence{}}
+			accountProjection.Days = append(accountProjection.Days, day)
+		}
+		day.Occurrences = append(day.Occurrences, occurrence)
+		accountProjection.EndingBalance = roundCents(accountProjection.EndingBalance - occurrence.Amount)
+		day.Balance = accountProjection.EndingBalance
+	}
+
+	for _, accountProjection := range projection.Accounts {
+		threshold := accountProjection.Account.LowBalanceThreshold
+		if accountProjection.Account.Balance < threshold {
+			accountProjection.BelowThresholdOn = &today
+		}
+		for _, day := range accountProjection.Days {
+			if day.Balance < accountProjection.LowestBalance || accountProjection.LowestBalanceDate == nil {
+				accountProjection.LowestBalance = day.Balance
+				accountProjection.LowestBalanceDate = &day.Date
+			}
+			if accountProjection.BelowThresholdOn == nil && day.Balance < threshold {
+				accountProjection.BelowThresholdOn = &day.Date
+			}
+		}
+	}
+	return projection, nil
+}
+
+// =============================================================================
+// Private Helper Methods
+// =============================================================================
+
+// applyPayments sets the balance of each account to its current balance less the payments made from
+// it since that balance was entered
+func (s *FundingAccountService) applyPayments(scopedDB *gorm.DB, accounts []*models.FundingAccount) error {
+	if len(accounts) == 0 {
+		return nil
+	}
+	since := accounts[0].BalanceUpdatedAt
+	for _, account := range accounts {
+		if account.BalanceUpdatedAt.Before(since) {
+			since = account.BalanceUpdatedAt
+		}
+	}
+	payments, err := s.paymentRepo.ListForAccountsSince(scopedDB, since)
+	if err != nil {
+		return err
+	}
+
+	for _, account := range accounts {
+		balance := account.CurrentBalance
+		for _, payment := range payments[account.ID] {
+			if payment.PaymentDate.After(account.BalanceUpdatedAt) {
+				balance -= payment.Amount
+			}
+		}
+		account.Balance = roundCents(balance)
+	}
+	return nil
+}
+
+// validateFundingAccount trims the account's name and defaults its type
+func validateFundingAccount(account *models.FundingAccount) error {
+	account.Name = strings.TrimSpace(account.Name)
+	if account.Name == "" {
+		return errors.New("name is required")
+	}
+	if account.Type == "" {
+		account.Type = models.FundingAccountChecking
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
+	"github.com/cryptk/williams/pkg/holidays"
+	"gorm.io/gorm"
+)
+
+// HolidayService provides the holiday calendars used to move due dates off weekends and holidays
+type HolidayService struct {
+	registry *holidays.Registry
+	repo     repository.HolidayRepository
+	audit    *AuditService
+}
+
+// NewHolidayService creates a new holiday service
+func NewHolidayService(registry *holidays.Registry, repo repository.HolidayRepository, audit *AuditService) *HolidayService {
+	return &HolidayService{registry: registry, repo: repo, audit: audit}
+}
+
+// ListCalendars returns the available country calendars
+func (s *HolidayService) ListCalendars() []*holidays.Calendar {
+	return s.registry.List()
+}
+
+// GetCalendar returns the calendar of a country
+func (s *HolidayService) GetCalendar(country string) (*holidays.Calendar, error) {
+	cal, ok := s.registry.Get(country)
+	if !ok {
+		return nil, fmt.Errorf("holiday calendar %s not found", strings.ToUpper(country))
+	}
+	return cal, nil
+}
+
+// ValidateCalendar returns an error if a bill's holiday_calendar is not available. Empty means weekends only.
+func (s *HolidayService) ValidateCalendar(country string) error {
+	if country == "" {
+		return nil
+	}
+	_, err := s.GetCalendar(country)
+	return err
+}
+
+// ListUserHolidays retrieves the holidays the user added
+func (s *HolidayService) ListUserHolidays(scopedDB *gorm.DB) ([]*models.UserHoliday, error) {
+	return s.repo.List(scopedDB)
+}
+
+// CreateUserHoliday adds a holiday for the user
+func (s *HolidayService) CreateUserHoliday(scopedDB *gorm.DB, holiday *models.UserHoliday) error {
+	existing, err := s.repo.List(scopedDB)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.Date == holiday.Date {
+			return fmt.Errorf("a holiday on %s already exists", holiday.Date)
+		}
+	}
+	if err := s.repo.Create(scopedDB, holiday); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionHolidayCreate,
+		EntityType: "holiday",
+		EntityID:   holiday.ID,
+		Changes:    auditDiff(nil, holiday),
+	})
+	return nil
+}
+
+// DeleteUserHoliday removes a holiday the user added
+func (s *HolidayService) DeleteUserHoliday(scopedDB *gorm.DB, id string) error {
+	if err := s.repo.Delete(scopedDB, id); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionHolidayDelete,
+		EntityType: "holiday",
+		EntityID:   id,
+	})
+	return nil
+}
+
+// userCalendar builds a calendar from the holidays the user added
+func (s *HolidayService) userCalendar(scopedDB *gorm.DB) (*holidays.Calendar, error) {
+	userHolidays, err := s.repo.List(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]holidays.Holiday, 0, len(userHolidays))
+	for _, holiday := range userHolidays {
+		list = append(list, holidays.Holiday{Date: holiday.Date, Name: holiday.Name})
+	}
+	return holidays.NewCalendar("", "Custom", list)
+}
+
+// calendarsFor returns the calendars that apply to a bill: its country calendar, if any, and the user's own holidays
+func (s *HolidayService) calendarsFor(bill *models.Bill, userCalendar *holidays.Calendar) []*holidays.Calendar {
+	calendars := []*holidays.Calendar{userCalendar}
+	if cal, ok := s.registry.Get(bill.HolidayCalendar); ok && bill.HolidayCalendar != "" {
+		calendars = append(calendars, cal)
+	}
+	return calendars
+}
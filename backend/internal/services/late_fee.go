@@ -0,0 +1,89 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+)
+
+// lateFeeAmount returns the fee of an occurrence of amount that is daysOverdue days past due, or zero
+// while it is within the rule's grace days
+func lateFeeAmount(rule *models.BillLateFeeRule, amount float64, daysOverdue int) float64 {
+	if daysOverdue <= rule.GraceDays {
+		return 0
+	}
+	fee := rule.FlatFee + amount*rule.Percentage/100 + rule.DailyFee*float64(daysOverdue-rule.GraceDays)
+	if rule.MaxFee != nil {
+		fee = math.Min(fee, *rule.MaxFee)
+	}
+	return roundCents(fee)
+}
+
+// daysBetween returns the number of calendar days from one date to another in loc
+func daysBetween(from, to time.Time, loc *time.Location) int {
+	return int(math.Round(calendarDay(to, loc).Sub(calendarDay(from, loc)).Hours() / 24))
+}
+
+// lateFees returns the fees of the occurrences of an enriched bill that are overdue past the grace days
+// of its rule as of today, oldest first. Occurrences are walked from the bill's next due date, so only
+// unpaid ones accrue fees; amountOn returns the amount due on an occurrence.
+func lateFees(bill *models.Bill, schedule *billSchedule, waivers []*models.BillLateFeeWaiver, amountOn func(due, rolled time.Time) float64, today time.Time) []*models.LateFee {
+	rule := bill.LateFeeRule
+	fees := []*models.LateFee{}
+	if rule == nil || bill.NextDueDate == nil || bill.IsPaid {
+		return fees
+	}
+
+	due := *bill.NextDueDate
+	if bill.NominalDueDate != nil {
+		due = *bill.NominalDueDate
+	}
+	_, _, billed := schedule.resolve(due)
+	for i := 0; i < maxScheduleSteps; i++ {
+		if bill.RecurrenceType != "none" && schedule.ended(due, billed) {
+			break
+		}
+		pause := schedule.pauseOn(due)
+		if pause != nil && pause.EndDate == nil {
+			break
+		}
+		if bill.RecurrenceType == "none" || !schedule.interrupted(due) {
+			billed++
+			rolled := schedule.rolled(due)
+			days := daysBetween(rolled, today, schedule.loc)
+			if days <= rule.GraceDays {
+				break // Later occurrences are even less overdue
+			}
+			fee := &models.LateFee{
+				DueDate:     rolled,
+				DaysOverdue: days,
+				Amount:      lateFeeAmount(rule, amountOn(due, rolled), days),
+			}
+			if waiver := waiverOn(waivers, due, rolled, schedule.loc); waiver != nil {
+				fee.Waived = true
+				fee.WaiverNotes = waiver.Notes
+			}
+			fees = append(fees, fee)
+		}
+		if bill.RecurrenceType == "none" {
+			break
+		}
+		due = schedule.next(due)
+	}
+	return fees
+}
+
+// waiverOn returns the waiver of the occurrence scheduled on due, which may name either the scheduled
+// due date or the business day it is rolled to
+func waiverOn(waivers []*models.BillLateFeeWaiver, due, rolled time.Time, loc *time.Location) *models.BillLateFeeWaiver {
+	day := calendarDay(due, loc)
+	rolledDay := calendarDay(rolled, loc)
+	for _, waiver := range waivers {
+		waiverDay := calendarDay(waiver.DueDate, loc)
+		if waiverDay.Equal(day) || waiverDay.Equal(rolledDay) {
+			return waiver
+		}
+	}
+	return nil
+}
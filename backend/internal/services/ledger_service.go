@@ -0,0 +1,117 @@
+package services
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
+	"gorm.io/gorm"
+)
+
+// LedgerService builds the payment register across all bills
+type LedgerService struct {
+	bills        *BillService
+	billRepo     repository.BillRepository
+	paymentRepo  repository.PaymentRepository
+	categoryRepo repository.CategoryRepository
+}
+
+// NewLedgerService creates a new ledger service
+func NewLedgerService(bills *BillService, billRepo repository.BillRepository, paymentRepo repository.PaymentRepository, categoryRepo repository.CategoryRepository) *LedgerService {
+	return &LedgerService{
+		bills:        bills,
+		billRepo:     billRepo,
+		paymentRepo:  paymentRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// Ledger retrieves a page of the payments of every bill not in the trash, each with its bill name and
+// category, along with monthly subtotals of all matching payments
+func (s *LedgerService) Ledger(scopedDB *gorm.DB, filter repository.PaymentFilter) (*models.PaymentLedger, error) {
+	page, err := s.bills.SearchPayments(scopedDB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subtotals need every match, which the page already is when the listing is not paginated
+	all := page.Payments
+	if filter.Cursor != nil || filter.Limit > 0 {
+		unpaged := filter
+		unpaged.Cursor, unpaged.Limit = nil, 0
+		if all, _, err = s.paymentRepo.Search(scopedDB, unpaged); err != nil {
+			return nil, err
+		}
+	}
+
+	bills, err := s.billRepo.List(scopedDB, repository.ListOptions{IncludeArchived: true})
+	if err != nil {
+		return nil, err
+	}
+	billsByID := make(map[string]*models.Bill, len(bills))
+	for _, bill := range bills {
+		billsByID[bill.ID] = bill
+	}
+	categories, err := s.categoryRepo.List(scopedDB, repository.ListOptions{IncludeArchived: true})
+	if err != nil {
+		return nil, err
+	}
+	categoryNames := make(map[string]string, len(categories))
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Name
+	}
+
+	ledger := &models.PaymentLedger{
+		Payments:   make([]*models.LedgerEntry, 0, len(page.Payments)),
+		Total:      page.Total,
+		NextCursor: page.NextCursor,
+		Months:     ledgerMonths(all, userLocation(scopedDB)),
+	}
+	for _, month := range ledger.Months {
+		ledger.TotalAmount += month.Total
+	}
+	ledger.TotalAmount = roundCents(ledger.TotalAmount)
+
+	for _, payment := range page.Payments {
+		entry := &models.LedgerEntry{Payment: payment}
+		if bill := billsByID[payment.BillID]; bill != nil {
+			entry.BillName = bill.Name
+			entry.CategoryID = bill.CategoryID
+			if bill.CategoryID != nil {
+				entry.CategoryName = categoryNames[*bill.CategoryID]
+			}
+		}
+		ledger.Payments = append(ledger.Payments, entry)
+	}
+	return ledger, nil
+}
+
+// ledgerMonths totals payments by the calendar month in loc they were made in, newest first. Reversed
+// payments did not go through and are left out.
+func ledgerMonths(payments []*models.Payment, loc *time.Location) []*models.LedgerMonth {
+	byMonth := map[string]*models.LedgerMonth{}
+	months := []*models.LedgerMonth{}
+	for _, payment := range payments {
+		if payment.ReversedAt != nil {
+			continue
+		}
+		key := payment.PaymentDate.In(loc).Format("2006-01")
+		month := byMonth[key]
+		if month == nil {
+			month = &models.LedgerMonth{Month: key}
+			byMonth[key] = month
+			months = append(months, month)
+		}
+		month.Total += payment.Amount
+		month.Payments++
+	}
+	for _, month := range months {
+		month.Total = roundCents(month.Total)
+	}
+	slices.SortFunc(months, func(a, b *models.LedgerMonth) int {
+		return strings.Compare(b.Month, a.Month)
+	})
+	return months
+}
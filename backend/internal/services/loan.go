@@ -0,0 +1,174 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+)
+
+// maxLoanPeriods bounds how many payments an amortization schedule projects, e.g. when payments
+// barely cover the interest
+const maxLoanPeriods = 1200
+
+// paidOffThreshold is the balance below which a loan counts as repaid
+const paidOffThreshold = 0.005
+
+// loanPeriodsPerYear returns how many payments a loan bill has per year
+func loanPeriodsPerYear(bill *models.Bill) float64 {
+	if bill.RecurrenceType == "interval" {
+		return 365 / float64(bill.RecurrenceDays)
+	}
+	return 12
+}
+
+// loanPeriodicRate converts the annual interest rate of a loan to the rate per payment
+func loanPeriodicRate(bill *models.Bill, loan *models.BillLoan) float64 {
+	return loan.InterestRate / 100 / loanPeriodsPerYear(bill)
+}
+
+// loanInstallment returns the fixed payment that repays a loan over its term
+func loanInstallment(bill *models.Bill, loan *models.BillLoan) float64 {
+	rate := loanPeriodicRate(bill, loan)
+	n := float64(loan.TermPayments)
+	if rate == 0 {
+		return roundCents(loan.Principal / n)
+	}
+	return roundCents(loan.Principal * rate / (1 - math.Pow(1+rate, -n)))
+}
+
+// applyLoanTerms derives the amount and schedule of a loan bill from its terms so the regular due date
+// calculation starts at the first payment date
+func applyLoanTerms(bill *models.Bill, loan *models.BillLoan, loc *time.Location) {
+	bill.Amount = loanInstallment(bill, loan)
+	switch bill.RecurrenceType {
+	case "fixed_date":
+		start := loan.FirstPaymentDate
+		bill.StartDate = &start
+		bill.RecurrenceDays = calendarDay(start, loc).Day()
+	case "interval":
+		start := loan.FirstPaymentDate.AddDate(0, 0, -bill.RecurrenceDays)
+		bill.StartDate = &start
+	}
+}
+
+// loanBalance returns the balance of a loan after its recorded payments, oldest first
+func loanBalance(bill *models.Bill, loan *models.BillLoan, payments []*models.Payment) float64 {
+	balance := loan.Principal
+	rate := loanPeriodicRate(bill, loan)
+	for _, payment := range payments {
+		balance = roundCents(balance - (payment.Amount - roundCents(balance*rate)))
+	}
+	return balance
+}
+
+// amortize builds the amortization schedule of a loan bill. Recorded payments, oldest first, each cover
+// one period; anything paid above the interest reduces the principal. The remaining balance is then
+// projected from the bill's next due date with the installment plus extra per payment.
+func amortize(bill *models.Bill, loan *models.BillLoan, payments []*models.Payment, schedule *billSchedule, extra float64) *models.AmortizationSchedule {
+	rate := loanPeriodicRate(bill, loan)
+	installment := loanInstallment(bill, loan)
+	result := &models.AmortizationSchedule{
+		BillID:       bill.ID,
+		Loan:         loan,
+		Installment:  installment,
+		ExtraPayment: extra,
+		Entries:      []*models.AmortizationEntry{},
+	}
+
+	balance := loan.Principal
+	for _, payment := range payments {
+		interest := roundCents(balance * rate)
+		balance = roundCents(balance - (payment.Amount - interest))
+		result.TotalInterest += interest
+		result.TotalPaid += payment.Amount
+		result.Entries = append(result.Entries, &models.AmortizationEntry{
+			Number:    len(result.Entries) + 1,
+			DueDate:   payment.PaymentDate,
+			Payment:   payment.Amount,
+			Principal: roundCents(payment.Amount - interest),
+			Interest:  interest,
+			Balance:   math.Max(balance, 0),
+			Paid:      true,
+		})
+	}
+	if balance <= paidOffThreshold {
+		result.PaidOff = true
+		if len(payments) > 0 {
+			payoff := payments[len(payments)-1].PaymentDate
+			result.PayoffDate = &payoff
+		}
+		result.TotalInterest = roundCents(result.TotalInterest)
+		result.TotalPaid = roundCents(result.TotalPaid)
+		return result
+	}
+	result.RemainingBalance = roundCents(balance)
+
+	projected := projectLoan(bill, loan, balance, len(payments), schedule, extra)
+	result.Entries = append(result.Entries, projected...)
+	projectedInterest := 0.0
+	for _, entry := range projected {
+		projectedInterest += entry.Interest
+		result.TotalPaid += entry.Payment
+	}
+	result.TotalInterest = roundCents(result.TotalInterest + projectedInterest)
+	result.TotalPaid = roundCents(result.TotalPaid)
+	result.PaymentsRemaining = len(projected)
+	if len(projected) > 0 {
+		payoff := projected[len(projected)-1].DueDate
+		result.PayoffDate = &payoff
+	}
+
+	if extra > 0 {
+		withoutExtra := 0.0
+		for _, entry := range projectLoan(bill, loan, balance, len(payments), schedule, 0) {
+			withoutExtra += entry.Interest
+		}
+		result.InterestSaved = roundCents(withoutExtra - projectedInterest)
+	}
+	return result
+}
+
+// projectLoan projects the payments repaying balance, starting at the bill's next scheduled due date.
+// Skipped and paused occurrences are not payments. The last payment of the term, or one that would
+// overpay, repays whatever is left.
+func projectLoan(bill *models.Bill, loan *models.BillLoan, balance float64, made int, schedule *billSchedule, extra float64) []*models.AmortizationEntry {
+	entries := []*models.AmortizationEntry{}
+	if bill.NextDueDate == nil {
+		return entries
+	}
+	rate := loanPeriodicRate(bill, loan)
+	installment := loanInstallment(bill, loan)
+
+	due := *bill.NextDueDate
+	if bill.NominalDueDate != nil {
+		due = *bill.NominalDueDate
+	}
+	number := made
+	for i := 0; i < maxScheduleSteps && balance > paidOffThreshold && len(entries) < maxLoanPeriods; i++ {
+		if !schedule.interrupted(due) {
+			number++
+			interest := roundCents(balance * rate)
+			payment := installment + extra
+			if number >= loan.TermPayments || payment >= balance+interest {
+				payment = roundCents(balance + interest)
+			}
+			balance = roundCents(balance - (payment - interest))
+			entries = append(entries, &models.AmortizationEntry{
+				Number:    number,
+				DueDate:   schedule.rolled(due),
+				Payment:   payment,
+				Principal: roundCents(payment - interest),
+				Interest:  interest,
+				Balance:   math.Max(balance, 0),
+			})
+		}
+		due = schedule.next(due)
+	}
+	return entries
+}
+
+// roundCents rounds an amount to whole cents
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
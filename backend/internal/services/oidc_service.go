@@ -0,0 +1,470 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/cryptk/williams/internal/config"
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// oidcFlowAudience marks the signed cookie carrying state, nonce and PKCE verifier between login and callback
+	oidcFlowAudience = "williams-oidc-flow"
+	// OIDCFlowTTL is how long a user has to complete the login at the identity provider
+	OIDCFlowTTL = 10 * time.Minute
+	// oidcHTTPTimeout bounds discovery, JWKS and token endpoint requests
+	oidcHTTPTimeout = 10 * time.Second
+)
+
+// ErrOIDCDisabled is returned when SSO endpoints are used without OIDC being configured
+var ErrOIDCDisabled = errors.New("single sign-on is not enabled")
+
+// usernameSanitizer strips characters that are not allowed in generated usernames
+var usernameSanitizer = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// OIDCService handles OpenID Connect single sign-on
+type OIDCService struct {
+	authService  *AuthService
+	userRepo     repository.UserRepository
+	identityRepo repository.IdentityRepository
+	audit        *AuditService
+	cfg          *config.OIDCConfig
+	httpClient   *http.Client
+
+	// Provider discovery is performed lazily on first use so that an unreachable
+	// identity provider does not prevent the application from starting.
+	mu           sync.Mutex
+	provider     *oidc.Provider
+	verifier     *oidc.IDTokenVerifier
+	oauth2Config *oauth2.Config
+}
+
+// oidcFlowClaims is the signed state carried in a cookie between the login redirect and the callback.
+// The subject is set when a logged in user links an identity to their account.
+type oidcFlowClaims struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	jwt.RegisteredClaims
+}
+
+// oidcIDTokenClaims contains the standard ID token claims used for account linking
+type oidcIDTokenClaims struct {
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+}
+
+// NewOIDCService creates a new OpenID Connect service
+func NewOIDCService(authService *AuthService, userRepo repository.UserRepository, identityRepo repository.IdentityRepository, audit *AuditService, cfg *config.OIDCConfig) *OIDCService {
+	return &OIDCService{
+		authService:  authService,
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		audit:        audit,
+		cfg:          cfg,
+		httpClient:   &http.Client{Timeout: oidcHTTPTimeout},
+	}
+}
+
+// Enabled reports whether single sign-on is configured
+func (s *OIDCService) Enabled() bool {
+	return s.cfg.Enabled
+}
+
+// ProviderName returns the display name of the identity provider
+func (s *OIDCService) ProviderName() string {
+	return s.cfg.ProviderName
+}
+
+// PostLoginRedirect returns the frontend URL that receives the result of an SSO login
+func (s *OIDCService) PostLoginRedirect() string {
+	return s.cfg.PostLoginRedirect
+}
+
+// BeginLogin starts an authorization code flow with PKCE.
+// It returns the identity provider URL to redirect to and a signed flow token that must be
+// stored in a cookie and handed back to CompleteLogin.
+func (s *OIDCService) BeginLogin() (string, string, error) {
+	return s.beginFlow("")
+}
+
+// BeginLink starts an authorization code flow that links the identity the user signs in with at the
+// provider to their local account. It returns the same values as BeginLogin.
+func (s *OIDCService) BeginLink(userID string) (string, string, error) {
+	return s.beginFlow(userID)
+}
+
+// beginFlow starts an authorization code flow with PKCE, for linking to linkUserID when it is set
+func (s *OIDCService) beginFlow(linkUserID string) (string, string, error) {
+	if !s.cfg.Enabled {
+		return "", "", ErrOIDCDisabled
+	}
+	if err := s.discover(); err != nil {
+		return "", "", err
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	flowToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, oidcFlowClaims{
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   linkUserID,
+			Audience:  jwt.ClaimStrings{oidcFlowAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(OIDCFlowTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}).SignedString(s.authService.jwtSecret)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to sign flow token: %w", err)
+	}
+
+	authURL := s.oauth2Config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+	return authURL, flowToken, nil
+}
+
+// CompleteLogin handles the callback from the identity provider: it checks the state, exchanges the
+// authorization code, validates the ID token against the provider's JWKS and returns a session token
+// for the linked (or newly provisioned) local user. A flow started by BeginLink links the identity to
+// the user who started it instead.
+func (s *OIDCService) CompleteLogin(ctx context.Context, flowToken, state, code string) (string, *models.User, error) {
+	if !s.cfg.Enabled {
+		return "", nil, ErrOIDCDisabled
+	}
+	if err := s.discover(); err != nil {
+		return "", nil, err
+	}
+
+	flow, err := s.parseFlowToken(flowToken)
+	if err != nil {
+		return "", nil, errors.New("login session expired, please try again")
+	}
+	if state == "" || state != flow.State {
+		return "", nil, errors.New("invalid state parameter")
+	}
+
+	ctx = oidc.ClientContext(ctx, s.httpClient)
+	oauth2Token, err := s.oauth2Config.Exchange(ctx, code, oauth2.VerifierOption(flow.Verifier))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	rawIDToken, ok := oauth2Token.Extra("id_token").(string)
+	if !ok {
+		return "", nil, errors.New("identity provider did not return an id_token")
+	}
+
+	idToken, err := s.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to verify id_token: %w", err)
+	}
+	if idToken.Nonce != flow.Nonce {
+		return "", nil, errors.New("invalid nonce in id_token")
+	}
+
+	var claims oidcIDTokenClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return "", nil, fmt.Errorf("failed to parse id_token claims: %w", err)
+	}
+	var rawClaims map[string]any
+	if err := idToken.Claims(&rawClaims); err != nil {
+		return "", nil, fmt.Errorf("failed to parse id_token claims: %w", err)
+	}
+
+	var user *models.User
+	if flow.Subject != "" {
+		user, err = s.linkIdentity(ctx, flow.Subject, idToken.Issuer, idToken.Subject, &claims)
+	} else {
+		user, err = s.resolveUser(ctx, idToken.Issuer, idToken.Subject, &claims)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := s.syncRoles(user, extractGroups(rawClaims[s.cfg.GroupsClaim])); err != nil {
+		return "", nil, err
+	}
+
+	// The identity provider is responsible for enforcing its own second factor, so local
+	// TOTP is not requested for SSO logins.
+	token, err := s.authService.generateToken(user)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	s.audit.Record(ctx, &models.AuditEvent{
+		UserID:     &user.ID,
+		ActorID:    &user.ID,
+		Action:     models.AuditActionLogin,
+		EntityType: "user",
+		EntityID:   user.ID,
+		Details:    "method=oidc",
+	})
+
+	return token, user, nil
+}
+
+// resolveUser finds the local user for an external identity, linking by verified email or
+// provisioning a new account when configured to do so
+func (s *OIDCService) resolveUser(ctx context.Context, issuer, subject string, claims *oidcIDTokenClaims) (*models.User, error) {
+	// 1. Previously linked identity
+	if identity, err := s.identityRepo.GetBySubject(issuer, subject); err == nil {
+		if err := s.identityRepo.TouchLastLogin(identity.ID, claims.Email); err != nil {
+			log.Warn().Err(err).Str("user_id", identity.UserID).Msg("Failed to record SSO login time")
+		}
+		return s.userRepo.GetByID(identity.UserID)
+	}
+
+	// Linking and provisioning both rely on the email address, so it must be verified by the provider
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, errors.New("identity provider did not supply a verified email address")
+	}
+
+	// 2. Existing local account with the same verified email. The local address must be verified too,
+	// otherwise anyone could register with someone else's address and take over their SSO login.
+	user, err := s.userRepo.GetByEmail(claims.Email)
+	if err == nil && !user.EmailVerified {
+		log.Warn().Str("user_id", user.ID).Str("issuer", issuer).Msg("Refused to link external identity to account with unverified email")
+		return nil, errors.New("an account with this email address exists but the address is not verified; log in and link single sign-on from your account")
+	}
+	if err != nil {
+		// 3. Just-in-time provisioning
+		if !s.cfg.AutoProvision {
+			return nil, errors.New("no account is linked to this identity")
+		}
+		user, err = s.provisionUser(ctx, claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.identityRepo.Create(&models.UserIdentity{
+		UserID:  user.ID,
+		Issuer:  issuer,
+		Subject: subject,
+		Email:   claims.Email,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	log.Info().Str("user_id", user.ID).Str("issuer", issuer).Msg("Linked external identity to user")
+	return user, nil
+}
+
+// linkIdentity links an external identity to the logged in user who started the flow
+func (s *OIDCService) linkIdentity(ctx context.Context, userID, issuer, subject string, claims *oidcIDTokenClaims) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if identity, err := s.identityRepo.GetBySubject(issuer, subject); err == nil {
+		if identity.UserID != user.ID {
+			return nil, errors.New("this identity is already linked to another account")
+		}
+		return user, nil
+	}
+
+	if err := s.identityRepo.Create(&models.UserIdentity{
+		UserID:  user.ID,
+		Issuer:  issuer,
+		Subject: subject,
+		Email:   claims.Email,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	log.Info().Str("user_id", user.ID).Str("issuer", issuer).Msg("Linked external identity to user")
+	s.audit.Record(ctx, &models.AuditEvent{
+		UserID:     &user.ID,
+		ActorID:    &user.ID,
+		Action:     models.AuditActionIdentityLink,
+		EntityType: "user",
+		EntityID:   user.ID,
+		Details:    "issuer=" + issuer,
+	})
+	return user, nil
+}
+
+// provisionUser creates a local account for a first-time SSO user
+func (s *OIDCService) provisionUser(ctx context.Context, claims *oidcIDTokenClaims) (*models.User, error) {
+	username, err := s.uniqueUsername(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// SSO users get a random password they never learn, so password login is impossible
+	// until they set one explicitly.
+	password, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := &models.User{
+		Username:      username,
+		Email:         claims.Email,
+		EmailVerified: true, // Only verified addresses reach provisioning
+		PasswordHash:  string(hashedPassword),
+		Roles:         []string{"user"},
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	s.authService.createDefaultCategories(user.ID)
+
+	log.Info().Str("user_id", user.ID).Str("username", user.Username).Msg("User provisioned via SSO")
+	s.audit.Record(ctx, &models.AuditEvent{
+		UserID:     &user.ID,
+		ActorID:    &user.ID,
+		Action:     models.AuditActionRegister,
+		EntityType: "user",
+		EntityID:   user.ID,
+		Details:    "method=oidc",
+	})
+	return user, nil
+}
+
+// uniqueUsername derives an unused username from the ID token claims
+func (s *OIDCService) uniqueUsername(claims *oidcIDTokenClaims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	base = usernameSanitizer.ReplaceAllString(base, "")
+	if len(base) < 3 {
+		base = "user"
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+
+	candidate := base
+	for i := 2; i < 100; i++ {
+		if _, err := s.userRepo.GetByUsername(candidate); err != nil {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+	return "", errors.New("unable to find an available username")
+}
+
+// syncRoles maps membership of the configured admin group onto the admin role
+func (s *OIDCService) syncRoles(user *models.User, groups []string) error {
+	if s.cfg.AdminGroup == "" {
+		return nil
+	}
+
+	isAdmin := slices.Contains(user.Roles, "admin")
+	shouldBeAdmin := slices.Contains(groups, s.cfg.AdminGroup)
+	if isAdmin == shouldBeAdmin {
+		return nil
+	}
+
+	if shouldBeAdmin {
+		user.Roles = append(user.Roles, "admin")
+	} else {
+		user.Roles = slices.DeleteFunc(user.Roles, func(role string) bool { return role == "admin" })
+	}
+	if !slices.Contains(user.Roles, "user") {
+		user.Roles = append(user.Roles, "user")
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return fmt.Errorf("failed to update user roles: %w", err)
+	}
+
+	log.Info().Str("user_id", user.ID).Bool("admin", shouldBeAdmin).Msg("Synced admin role from identity provider groups")
+	return nil
+}
+
+// discover fetches the provider metadata on first use and caches it
+func (s *OIDCService) discover() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider != nil {
+		return nil
+	}
+
+	ctx := oidc.ClientContext(context.Background(), s.httpClient)
+	provider, err := oidc.NewProvider(ctx, s.cfg.IssuerURL)
+	if err != nil {
+		return fmt.Errorf("failed to discover identity provider: %w", err)
+	}
+
+	s.provider = provider
+	s.verifier = provider.VerifierContext(ctx, &oidc.Config{ClientID: s.cfg.ClientID})
+	s.oauth2Config = &oauth2.Config{
+		ClientID:     s.cfg.ClientID,
+		ClientSecret: s.cfg.ClientSecret,
+		RedirectURL:  s.cfg.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       s.cfg.Scopes,
+	}
+	return nil
+}
+
+// parseFlowToken validates the signed flow cookie
+func (s *OIDCService) parseFlowToken(tokenString string) (*oidcFlowClaims, error) {
+	claims := &oidcFlowClaims{}
+	_, err := jwt.ParseWithClaims(
+		tokenString,
+		claims,
+		func(token *jwt.Token) (any, error) { return s.authService.jwtSecret, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(oidcFlowAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// extractGroups normalizes a groups claim, which providers send either as a list or a single string
+func extractGroups(claim any) []string {
+	switch v := claim.(type) {
+	case []any:
+		groups := make([]string, 0, len(v))
+		for _, g := range v {
+			if s, ok := g.(string); ok {
+				groups = append(groups, s)
+			}
+		}
+		return groups
+	case string:
+		return []string{v}
+	default:
+		return nil
+	}
+}
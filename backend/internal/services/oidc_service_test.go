@@ -0,0 +1,368 @@
+package services_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cryptk/williams/internal/config"
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
+	"github.com/cryptk/williams/internal/services"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const oidcClientID = "williams"
+
+// mockProvider is an OpenID Connect provider serving discovery, JWKS and a token endpoint that issues
+// ID tokens with the claims the test sets
+type mockProvider struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu     sync.Mutex
+	claims jwt.MapClaims // Claims of the next ID token, besides iss, aud, exp, iat and nonce
+	nonce  string
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &mockProvider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"issuer":                                p.URL,
+			"authorization_endpoint":                p.URL + "/authorize",
+			"token_endpoint":                        p.URL + "/token",
+			"jwks_uri":                              p.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "code" || r.FormValue("code_verifier") == "" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		p.mu.Lock()
+		claims := jwt.MapClaims{
+			"iss":   p.URL,
+			"aud":   oidcClientID,
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"nonce": p.nonce,
+		}
+		for name, value := range p.claims {
+			claims[name] = value
+		}
+		p.mu.Unlock()
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "test"
+		idToken, err := token.SignedString(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, map[string]any{"access_token": "access", "token_type": "Bearer", "expires_in": 60, "id_token": idToken})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// issue sets the claims and nonce of the ID tokens the provider issues from now on
+func (p *mockProvider) issue(claims jwt.MapClaims, nonce string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.claims, p.nonce = claims, nonce
+}
+
+func writeJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+// oidcEnv is the test environment with single sign-on against a mock provider
+type oidcEnv struct {
+	*testEnv
+	provider *mockProvider
+	cfg      *config.OIDCConfig
+	oidc     *services.OIDCService
+}
+
+func newOIDCEnv(t *testing.T) *oidcEnv {
+	t.Helper()
+	env := newTestEnv(t)
+	provider := newMockProvider(t)
+	cfg := &config.OIDCConfig{
+		Enabled:      true,
+		IssuerURL:    provider.URL,
+		ClientID:     oidcClientID,
+		ClientSecret: "secret",
+		RedirectURL:  "https://williams.example.com/api/v1/auth/oidc/callback",
+		Scopes:       []string{"openid", "email", "profile", "groups"},
+		GroupsClaim:  "groups",
+		AdminGroup:   "williams-admins",
+	}
+	return &oidcEnv{
+		testEnv:  env,
+		provider: provider,
+		cfg:      cfg,
+		oidc: services.NewOIDCService(env.auth, repository.NewUserRepository(env.db.DB), repository.NewIdentityRepository(env.db.DB),
+			env.audit, cfg),
+	}
+}
+
+// signIn runs an authorization code flow in which the provider vouches for claims, linking to the user
+// with linkUserID when it is set, and returns the user it logged in
+func (env *oidcEnv) signIn(t *testing.T, linkUserID string, claims jwt.MapClaims) (*models.User, error) {
+	t.Helper()
+	var authURL, flowToken string
+	var err error
+	if linkUserID != "" {
+		authURL, flowToken, err = env.oidc.BeginLink(linkUserID)
+	} else {
+		authURL, flowToken, err = env.oidc.BeginLogin()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env.provider.issue(claims, params.Query().Get("nonce"))
+
+	_, user, err := env.oidc.CompleteLogin(context.Background(), flowToken, params.Query().Get("state"), "code")
+	return user, err
+}
+
+// verifiedUser registers a user whose email address is verified
+func (env *oidcEnv) verifiedUser(t *testing.T, username string) *models.User {
+	t.Helper()
+	user := env.register(t, username)
+	if err := env.db.Model(&models.User{}).Where("id = ?", user.ID).Update("email_verified", true).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// identities counts the external identities linked to a user
+func (env *oidcEnv) identities(t *testing.T, userID string) int64 {
+	t.Helper()
+	var count int64
+	if err := env.db.Model(&models.UserIdentity{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestOIDCLinksVerifiedEmail(t *testing.T) {
+	env := newOIDCEnv(t)
+	local := env.verifiedUser(t, "alice")
+
+	user, err := env.signIn(t, "", jwt.MapClaims{"sub": "idp-alice", "email": local.Email, "email_verified": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != local.ID {
+		t.Fatalf("signed in as %s, want the local account %s", user.ID, local.ID)
+	}
+	if n := env.identities(t, local.ID); n != 1 {
+		t.Errorf("got %d linked identities, want 1", n)
+	}
+
+	// Once linked, the identity signs in even after the provider's email changes
+	user, err = env.signIn(t, "", jwt.MapClaims{"sub": "idp-alice", "email": "renamed@example.com", "email_verified": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != local.ID {
+		t.Errorf("linked identity signed in as %s, want %s", user.ID, local.ID)
+	}
+}
+
+func TestOIDCDoesNotLinkUnverifiedEmail(t *testing.T) {
+	tests := []struct {
+		name          string
+		localVerified bool
+		idpVerified   any
+	}{
+		{"unverified at the provider", true, false},
+		{"verification not reported by the provider", true, nil},
+		{"unverified local account", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newOIDCEnv(t)
+			// Even with provisioning on, a second account must not be created for the address
+			env.cfg.AutoProvision = true
+			var local *models.User
+			if tt.localVerified {
+				local = env.verifiedUser(t, "alice")
+			} else {
+				local = env.register(t, "alice")
+			}
+			claims := jwt.MapClaims{"sub": "idp-attacker", "email": local.Email}
+			if tt.idpVerified != nil {
+				claims["email_verified"] = tt.idpVerified
+			}
+
+			if user, err := env.signIn(t, "", claims); err == nil {
+				t.Fatalf("signed in as %s (%s)", user.Username, user.ID)
+			}
+			if n := env.identities(t, local.ID); n != 0 {
+				t.Errorf("got %d linked identities, want 0", n)
+			}
+			var users int64
+			if err := env.db.Model(&models.User{}).Where("email = ?", local.Email).Count(&users).Error; err != nil {
+				t.Fatal(err)
+			}
+			if users != 1 {
+				t.Errorf("got %d accounts with the address, want 1", users)
+			}
+		})
+	}
+}
+
+func TestOIDCProvisionsNewUsers(t *testing.T) {
+	env := newOIDCEnv(t)
+	claims := jwt.MapClaims{"sub": "idp-carol", "email": "carol@corp.example.com", "email_verified": true, "preferred_username": "carol"}
+
+	if _, err := env.signIn(t, "", claims); err == nil {
+		t.Fatal("signed in without an account while auto_provision is off")
+	}
+
+	env.cfg.AutoProvision = true
+	env.register(t, "carol") // Takes the preferred username, but not the address
+	user, err := env.signIn(t, "", claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "carol2" || user.Email != "carol@corp.example.com" || !user.EmailVerified {
+		t.Errorf("provisioned %s <%s> verified=%t, want carol2 <carol@corp.example.com> verified=true", user.Username, user.Email, user.EmailVerified)
+	}
+	if !slices.Equal(user.Roles, []string{"user"}) {
+		t.Errorf("provisioned user has roles %v, want [user]", user.Roles)
+	}
+	if n := env.identities(t, user.ID); n != 1 {
+		t.Errorf("got %d linked identities, want 1", n)
+	}
+
+	again, err := env.signIn(t, "", claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != user.ID {
+		t.Errorf("second sign in provisioned another account %s", again.ID)
+	}
+}
+
+func TestOIDCMapsAdminGroup(t *testing.T) {
+	env := newOIDCEnv(t)
+	env.cfg.AutoProvision = true
+	claims := jwt.MapClaims{"sub": "idp-dave", "email": "dave@example.com", "email_verified": true}
+
+	steps := []struct {
+		groups any
+		admin  bool
+	}{
+		{[]string{"staff", "williams-admins"}, true},
+		{"williams-admins", true}, // Some providers send a single group as a string
+		{[]string{"staff"}, false},
+		{nil, false},
+	}
+	for _, step := range steps {
+		claims["groups"] = step.groups
+		user, err := env.signIn(t, "", claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored, err := env.auth.GetUserByID(user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := slices.Contains(stored.Roles, "admin"); got != step.admin {
+			t.Errorf("groups %v: got admin=%t, want %t (roles %v)", step.groups, got, step.admin, stored.Roles)
+		}
+		if !slices.Contains(stored.Roles, "user") {
+			t.Errorf("groups %v: lost the user role (roles %v)", step.groups, stored.Roles)
+		}
+	}
+}
+
+func TestOIDCLinkFromAccount(t *testing.T) {
+	env := newOIDCEnv(t)
+	// Linking from a logged in session does not depend on the email addresses matching or being verified
+	local := env.register(t, "erin")
+	other := env.verifiedUser(t, "frank")
+
+	user, err := env.signIn(t, local.ID, jwt.MapClaims{"sub": "idp-erin", "email": "erin@corp.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != local.ID {
+		t.Errorf("linked to %s, want %s", user.ID, local.ID)
+	}
+
+	if _, err := env.signIn(t, other.ID, jwt.MapClaims{"sub": "idp-erin", "email": "erin@corp.example.com"}); err == nil {
+		t.Error("an identity linked to one account was linked to another")
+	}
+}
+
+func TestOIDCRejectsTamperedFlows(t *testing.T) {
+	env := newOIDCEnv(t)
+	env.cfg.AutoProvision = true
+	claims := jwt.MapClaims{"sub": "idp-grace", "email": "grace@example.com", "email_verified": true}
+
+	authURL, flowToken, err := env.oidc.BeginLogin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := params.Query().Get("state")
+
+	env.provider.issue(claims, "replayed")
+	if _, _, err := env.oidc.CompleteLogin(context.Background(), flowToken, state, "code"); err == nil {
+		t.Error("accepted an ID token with another nonce")
+	}
+	env.provider.issue(claims, params.Query().Get("nonce"))
+	if _, _, err := env.oidc.CompleteLogin(context.Background(), flowToken, "forged", "code"); err == nil {
+		t.Error("accepted a callback with another state")
+	}
+	if _, _, err := env.oidc.CompleteLogin(context.Background(), flowToken+"x", state, "code"); err == nil {
+		t.Error("accepted a tampered flow token")
+	}
+	if _, _, err := env.oidc.CompleteLogin(context.Background(), flowToken, state, "code"); err != nil {
+		t.Errorf("untampered flow: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
+	"gorm.io/gorm"
+)
+
+// PayeeService handles business logic for payees
+type PayeeService struct {
+	repo  repository.PayeeRepository
+	bills *BillService
+	audit *AuditService
+}
+
+// NewPayeeService creates a new payee service
+func NewPayeeService(repo repository.PayeeRepository, bills *BillService, audit *AuditService) *PayeeService {
+	return &PayeeService{repo: repo, bills: bills, audit: audit}
+}
+
+// Create creates a new payee
+func (s *PayeeService) Create(scopedDB *gorm.DB, payee *models.Payee) error {
+	if err := validatePayee(payee); err != nil {
+		return err
+	}
+	if err := s.repo.Create(scopedDB, payee); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionPayeeCreate,
+		EntityType: "payee",
+		EntityID:   payee.ID,
+		Changes:    auditDiff(nil, payee),
+	})
+	return nil
+}
+
+// Get retrieves a payee with the bills owed to it and their statistics
+func (s *PayeeService) Get(scopedDB *gorm.DB, id string) (*models.PayeeDetails, error) {
+	payee, err := s.repo.Get(scopedDB, id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.BillFilter{PayeeID: id}
+	bills, err := s.bills.List(scopedDB, filter)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.bills.GetStats(scopedDB, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PayeeDetails{Payee: payee, Bills: bills, Stats: stats}, nil
+}
+
+// List retrieves all payees
+func (s *PayeeService) List(scopedDB *gorm.DB) ([]*models.Payee, error) {
+	return s.repo.List(scopedDB)
+}
+
+// Update updates an existing payee
+func (s *PayeeService) Update(scopedDB *gorm.DB, payee *models.Payee) error {
+	before, err := s.repo.Get(scopedDB, payee.ID)
+	if err != nil {
+		return err
+	}
+	if err := validatePayee(payee); err != nil {
+		return err
+	}
+	if err := s.repo.Update(scopedDB, payee); err != nil {
+		return err
+	}
+	payee.UserID = before.UserID
+	payee.CreatedAt = before.CreatedAt
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionPayeeUpdate,
+		EntityType: "payee",
+		EntityID:   payee.ID,
+		Changes:    auditDiff(before, payee),
+	})
+	return nil
+}
+
+// Delete deletes a payee. Its bills are kept without a payee.
+func (s *PayeeService) Delete(scopedDB *gorm.DB, id string) error {
+	before, err := s.repo.Get(scopedDB, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(scopedDB, id); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionPayeeDelete,
+		EntityType: "payee",
+		EntityID:   id,
+		Changes:    auditDiff(before, nil),
+	})
+	return nil
+}
+
+// =============================================================================
+// Private Helper Methods
+// =============================================================================
+
+// validatePayee trims the payee's name and account number and ensures a name is given
+func validatePayee(payee *models.Payee) error {
+	payee.Name = strings.TrimSpace(payee.Name)
+	payee.AccountNumber = strings.TrimSpace(payee.AccountNumber)
+	if payee.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
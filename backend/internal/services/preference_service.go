@@ -0,0 +1,135 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
+	"github.com/cryptk/williams/pkg/utils"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// Defaults for users who have not saved preferences. The timezone defaults to the instance timezone.
+const (
+	defaultLocale     = "en-US"
+	defaultCurrency   = "USD"
+	defaultDateFormat = models.DateFormatISO
+)
+
+var (
+	// localePattern matches BCP 47 language tags such as "en", "en-US" or "zh-Hant-TW"
+	localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+	// currencyPattern matches ISO 4217 currency codes
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// PreferenceService handles business logic for user preferences
+type PreferenceService struct {
+	repo  repository.PreferenceRepository
+	audit *AuditService
+}
+
+// NewPreferenceService creates a new preference service
+func NewPreferenceService(repo repository.PreferenceRepository, audit *AuditService) *PreferenceService {
+	return &PreferenceService{repo: repo, audit: audit}
+}
+
+// Get retrieves the user's preferences, or the defaults if they have not saved any
+func (s *PreferenceService) Get(scopedDB *gorm.DB) (*models.UserPreferences, error) {
+	preferences, err := s.repo.Get(scopedDB)
+	if err != nil {
+		return nil, err
+	}
+	if preferences == nil {
+		preferences = &models.UserPreferences{}
+		if err := validatePreferences(preferences); err != nil {
+			return nil, err
+		}
+	}
+	return preferences, nil
+}
+
+// Update replaces the user's preferences. Fields left empty are reset to their defaults.
+func (s *PreferenceService) Update(scopedDB *gorm.DB, preferences *models.UserPreferences) error {
+	before, err := s.Get(scopedDB)
+	if err != nil {
+		return err
+	}
+	if err := validatePreferences(preferences); err != nil {
+		return err
+	}
+	if err := s.repo.Save(scopedDB, preferences); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionPreferencesUpdate,
+		EntityType: "preferences",
+		EntityID:   preferences.UserID,
+		Changes:    auditDiff(before, preferences),
+	})
+	return nil
+}
+
+// Location returns the timezone the user's due dates are calculated in. A preference that cannot be
+// loaded falls back to the instance timezone so it never blocks a request.
+func (s *PreferenceService) Location(scopedDB *gorm.DB) *time.Location {
+	preferences, err := s.repo.Get(scopedDB)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load user preferences, using the instance timezone")
+		return utils.GetAppLocation()
+	}
+	if preferences == nil {
+		return utils.GetAppLocation()
+	}
+	loc, err := time.LoadLocation(preferences.Timezone)
+	if err != nil {
+		log.Warn().Err(err).Str("timezone", preferences.Timezone).Msg("Unknown user timezone, using the instance timezone")
+		return utils.GetAppLocation()
+	}
+	return loc
+}
+
+// =============================================================================
+// Private Helper Methods
+// =============================================================================
+
+// validatePreferences checks the timezone, locale and currency of preferences and fills in defaults
+// for the ones left empty
+func validatePreferences(preferences *models.UserPreferences) error {
+	preferences.Timezone = strings.TrimSpace(preferences.Timezone)
+	if preferences.Timezone == "" {
+		preferences.Timezone = utils.GetAppLocation().String()
+	}
+	if _, err := time.LoadLocation(preferences.Timezone); err != nil || preferences.Timezone == "Local" {
+		return fmt.Errorf("unknown timezone %q, use an IANA name such as America/New_York", preferences.Timezone)
+	}
+
+	preferences.Locale = strings.TrimSpace(preferences.Locale)
+	if preferences.Locale == "" {
+		preferences.Locale = defaultLocale
+	}
+	if !localePattern.MatchString(preferences.Locale) {
+		return fmt.Errorf("locale must be a language tag such as en-US")
+	}
+
+	preferences.Currency = strings.ToUpper(strings.TrimSpace(preferences.Currency))
+	if preferences.Currency == "" {
+		preferences.Currency = defaultCurrency
+	}
+	if !currencyPattern.MatchString(preferences.Currency) {
+		return fmt.Errorf("currency must be a three-letter ISO 4217 code such as USD")
+	}
+
+	if preferences.DateFormat == "" {
+		preferences.DateFormat = defaultDateFormat
+	}
+	if preferences.FirstDayOfWeek < 0 || preferences.FirstDayOfWeek > 6 {
+		return fmt.Errorf("first_day_of_week must be between 0 (Sunday) and 6 (Saturday)")
+	}
+	return nil
+}
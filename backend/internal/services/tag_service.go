@@ -0,0 +1,150 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ErrTagExists is returned when a tag would share its name with another tag of the user
+var ErrTagExists = errors.New("a tag with this name already exists")
+
+// TagService handles business logic for tags
+type TagService struct {
+	repo   repository.TagRepository
+	ledger *LedgerService
+	audit  *AuditService
+}
+
+// NewTagService creates a new tag service
+func NewTagService(repo repository.TagRepository, ledger *LedgerService, audit *AuditService) *TagService {
+	return &TagService{repo: repo, ledger: ledger, audit: audit}
+}
+
+// Create creates a new tag. Names are unique per user, ignoring case.
+func (s *TagService) Create(scopedDB *gorm.DB, tag *models.Tag) error {
+	if err := s.validateName(scopedDB, tag); err != nil {
+		return err
+	}
+	if err := s.repo.Create(scopedDB, tag); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionTagCreate,
+		EntityType: "tag",
+		EntityID:   tag.ID,
+		Changes:    auditDiff(nil, tag),
+	})
+	return nil
+}
+
+// List retrieves all tags
+func (s *TagService) List(scopedDB *gorm.DB) ([]*models.Tag, error) {
+	return s.repo.List(scopedDB)
+}
+
+// Update renames or recolors a tag
+func (s *TagService) Update(scopedDB *gorm.DB, tag *models.Tag) error {
+	before, err := s.repo.Get(scopedDB, tag.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateName(scopedDB, tag); err != nil {
+		return err
+	}
+	if err := s.repo.Update(scopedDB, tag); err != nil {
+		return err
+	}
+	tag.UserID = before.UserID
+	tag.CreatedAt = before.CreatedAt
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionTagUpdate,
+		EntityType: "tag",
+		EntityID:   tag.ID,
+		Changes:    auditDiff(before, tag),
+	})
+	return nil
+}
+
+// Delete deletes a tag and removes it from every bill
+func (s *TagService) Delete(scopedDB *gorm.DB, id string) error {
+	before, err := s.repo.Get(scopedDB, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(scopedDB, id); err != nil {
+		return err
+	}
+
+	s.audit.RecordScoped(scopedDB, &models.AuditEvent{
+		Action:     models.AuditActionTagDelete,
+		EntityType: "tag",
+		EntityID:   id,
+		Changes:    auditDiff(before, nil),
+	})
+	return nil
+}
+
+// PaymentReport groups the payments made in a calendar year by the tags of their bills, oldest first.
+// With a tag ID only that tag is reported.
+func (s *TagService) PaymentReport(scopedDB *gorm.DB, year int, tagID string) (*models.TagPaymentReport, error) {
+	var tags []*models.Tag
+	if tagID != "" {
+		tag, err := s.repo.Get(scopedDB, tagID)
+		if err != nil {
+			return nil, err
+		}
+		tags = []*models.Tag{tag}
+	} else {
+		var err error
+		if tags, err = s.repo.List(scopedDB); err != nil {
+			return nil, err
+		}
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, userLocation(scopedDB))
+	to := from.AddDate(1, 0, 0)
+	report := &models.TagPaymentReport{Year: year, Tags: make([]*models.TagPayments, 0, len(tags))}
+	for _, tag := range tags {
+		ledger, err := s.ledger.Ledger(scopedDB, repository.PaymentFilter{
+			TagID: tag.ID,
+			From:  &from,
+			To:    &to,
+			Sort:  repository.Sort{Field: "payment_date"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		group := &models.TagPayments{Tag: tag, Total: ledger.TotalAmount, Payments: []*models.LedgerEntry{}}
+		for _, entry := range ledger.Payments {
+			if entry.ReversedAt == nil {
+				group.Payments = append(group.Payments, entry)
+			}
+		}
+		report.Tags = append(report.Tags, group)
+	}
+	return report, nil
+}
+
+// validateName trims a tag's name and ensures no other tag of the user has it
+func (s *TagService) validateName(scopedDB *gorm.DB, tag *models.Tag) error {
+	tag.Name = strings.TrimSpace(tag.Name)
+	if tag.Name == "" {
+		return errors.New("name is required")
+	}
+	existing, err := s.repo.GetByName(scopedDB, tag.Name)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != tag.ID {
+		return ErrTagExists
+	}
+	return nil
+}
@@ -0,0 +1,404 @@
+package services_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptk/williams/internal/api/middleware"
+	"github.com/cryptk/williams/internal/config"
+	"github.com/cryptk/williams/internal/database"
+	"github.com/cryptk/williams/internal/encryption"
+	"github.com/cryptk/williams/internal/models"
+	"github.com/cryptk/williams/internal/repository"
+	"github.com/cryptk/williams/internal/services"
+	"github.com/cryptk/williams/internal/storage"
+	"github.com/cryptk/williams/pkg/holidays"
+	"github.com/cryptk/williams/pkg/ratelimit"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	// Every query is logged at debug level, which would drown out the results
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	cipher, err := encryption.New(&config.EncryptionConfig{Key: base64.StdEncoding.EncodeToString(key)})
+	if err != nil {
+		panic(err)
+	}
+	encryption.Use(cipher)
+
+	os.Exit(m.Run())
+}
+
+// testEnv holds the services under test, wired to a migrated SQLite database like the API server
+type testEnv struct {
+	db          *database.DB
+	bills       *services.BillService
+	categories  *services.CategoryService
+	attachments *services.AttachmentService
+	audit       *services.AuditService
+	auth        *services.AuthService
+	accounts    *services.AccountService
+	mail        *testMailer
+	files       string // Directory the attachment store keeps files in
+}
+
+// tenant is a user with one of each record a bill or payment can reference
+type tenant struct {
+	userID            string
+	db                *gorm.DB // Scoped to the user
+	categoryID        string
+	payeeID           string
+	accountID         string
+	tagID             string
+	bill              *models.Bill
+	payment           *models.Payment
+	billAttachment    *models.Attachment
+	paymentAttachment *models.Attachment
+}
+
+func newTestEnv(t testing.TB) *testEnv {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := database.New(&config.DatabaseConfig{Driver: "sqlite", DSN: filepath.Join(dir, "williams.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.RunMigrations(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Auth: config.AuthConfig{
+			JWTSecret:  "test-secret",
+			TOTPIssuer: "Williams",
+			RateLimit: config.RateLimitConfig{
+				LockoutThreshold:   5,
+				LockoutDuration:    time.Minute,
+				LockoutMaxDuration: time.Hour,
+			},
+		},
+		Bills: config.BillsConfig{PaymentGraceDays: 3, MaximumBillingInterval: 365},
+		Attachments: config.AttachmentsConfig{
+			LocalPath:    filepath.Join(dir, "attachments"),
+			MaxSize:      1 << 20,
+			AllowedTypes: []string{"text/plain"},
+		},
+	}
+	registry, err := holidays.NewRegistry("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := storage.New(&cfg.Attachments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	billRepo := repository.NewBillRepository(db.DB)
+	paymentRepo := repository.NewPaymentRepository()
+	auditService := services.NewAuditService(repository.NewAuditRepository(db.DB), &cfg.Audit)
+	holidayService := services.NewHolidayService(registry, repository.NewHolidayRepository(), auditService)
+	userRepo := repository.NewUserRepository(db.DB)
+	authService := services.NewAuthService(userRepo, repository.NewCategoryRepository(db.DB), repository.NewRecoveryCodeRepository(db.DB),
+		repository.NewAPITokenRepository(db.DB), ratelimit.NewMemoryStore(), auditService, &cfg.Auth)
+	mail := &testMailer{}
+	return &testEnv{
+		db: db,
+		bills: services.NewBillService(billRepo, paymentRepo, repository.NewBillVersionRepository(), repository.NewBillScheduleRepository(),
+			repository.NewBillLoanRepository(), repository.NewBillStatementRepository(), repository.NewLateFeeRepository(),
+			repository.NewTagRepository(), repository.NewPayeeRepository(), holidayService, auditService, cfg),
+		categories:  services.NewCategoryService(repository.NewCategoryRepository(db.DB), auditService),
+		attachments: services.NewAttachmentService(repository.NewAttachmentRepository(db.DB), billRepo, paymentRepo, store, auditService, &cfg.Attachments),
+		audit:       auditService,
+		auth:        authService,
+		accounts: services.NewAccountService(authService, userRepo, repository.NewUserTokenRepository(db.DB), repository.NewUserDataRepository(db.DB),
+			services.NewPreferenceService(repository.NewPreferenceRepository(), auditService), mail, auditService, "https://williams.example.com", 0),
+		mail:  mail,
+		files: cfg.Attachments.LocalPath,
+	}
+}
+
+// newTenant creates a user with a category, payee, funding account and tag, and a bill referencing all
+// of them that has a payment and an attachment on both
+func (env *testEnv) newTenant(t *testing.T, name string) *tenant {
+	t.Helper()
+	user := &models.User{
+		ID:           uuid.New().String(),
+		Username:     name,
+		Email:        name + "@example.com",
+		PasswordHash: "x",
+		Roles:        []string{"user"},
+	}
+	if err := env.db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	tn := &tenant{
+		userID:     user.ID,
+		db:         env.db.WithContext(context.Background()).Scopes(middleware.TenantScoped(user.ID)),
+		categoryID: uuid.New().String(),
+		payeeID:    uuid.New().String(),
+		accountID:  uuid.New().String(),
+		tagID:      uuid.New().String(),
+	}
+	for _, record := range []any{
+		&models.Category{ID: tn.categoryID, UserID: user.ID, Name: "Housing"},
+		&models.Payee{ID: tn.payeeID, UserID: user.ID, Name: "Landlord"},
+		&models.FundingAccount{ID: tn.accountID, UserID: user.ID, Name: "Checking"},
+		&models.Tag{ID: tn.tagID, UserID: user.ID, Name: "Essential"},
+	} {
+		if err := env.db.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tn.bill = &models.Bill{
+		UserID:         user.ID,
+		Name:           "Rent",
+		Amount:         1200,
+		RecurrenceType: "fixed_date",
+		RecurrenceDays: 1,
+		CategoryID:     &tn.categoryID,
+		PayeeID:        &tn.payeeID,
+		AccountID:      &tn.accountID,
+		TagIDs:         []string{tn.tagID},
+	}
+	if err := env.bills.Create(tn.db, tn.bill); err != nil {
+		t.Fatalf("creating %s's bill: %v", name, err)
+	}
+	tn.payment = &models.Payment{
+		BillID:      tn.bill.ID,
+		UserID:      user.ID,
+		Amount:      1200,
+		PaymentDate: time.Date(2026, time.September, 1, 12, 0, 0, 0, time.UTC),
+	}
+	if err := env.bills.CreatePayment(tn.db, tn.payment); err != nil {
+		t.Fatalf("creating %s's payment: %v", name, err)
+	}
+
+	tn.billAttachment = &models.Attachment{UserID: user.ID, BillID: tn.bill.ID, FileName: "lease.txt"}
+	if err := env.attachments.Upload(context.Background(), tn.db, tn.billAttachment, strings.NewReader("lease")); err != nil {
+		t.Fatalf("attaching to %s's bill: %v", name, err)
+	}
+	tn.paymentAttachment = &models.Attachment{UserID: user.ID, BillID: tn.bill.ID, PaymentID: &tn.payment.ID, FileName: "receipt.txt"}
+	if err := env.attachments.Upload(context.Background(), tn.db, tn.paymentAttachment, strings.NewReader("receipt")); err != nil {
+		t.Fatalf("attaching to %s's payment: %v", name, err)
+	}
+	return tn
+}
+
+// assertReferenceError fails the test unless err reports field as not found
+func assertReferenceError(t *testing.T, err error, field string) {
+	t.Helper()
+	var refErr *repository.ReferenceError
+	if !errors.As(err, &refErr) {
+		t.Fatalf("got error %v, want a reference error for %s", err, field)
+	}
+	if refErr.Field != field {
+		t.Errorf("reference error for %s, want %s", refErr.Field, field)
+	}
+}
+
+// assertNotFound fails the test unless err reports the record as not found
+func assertNotFound(t *testing.T, err error) {
+	t.Helper()
+	if err == nil || !strings.HasSuffix(err.Error(), "not found") {
+		t.Fatalf("got error %v, want not found", err)
+	}
+}
+
+// crossTenantReferences returns, for each reference a bill can hold, a change pointing it at other's record
+func crossTenantReferences(own, other *tenant) map[string]func(*models.Bill) {
+	return map[string]func(*models.Bill){
+		"category_id": func(bill *models.Bill) { bill.CategoryID = &other.categoryID },
+		"payee_id":    func(bill *models.Bill) { bill.PayeeID = &other.payeeID },
+		"account_id":  func(bill *models.Bill) { bill.AccountID = &other.accountID },
+		"tag_ids":     func(bill *models.Bill) { bill.TagIDs = []string{own.tagID, other.tagID} },
+	}
+}
+
+func TestCreateBillRejectsOtherTenantsReferences(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.newTenant(t, "alice")
+	bob := env.newTenant(t, "bob")
+
+	for field, reference := range crossTenantReferences(alice, bob) {
+		t.Run(field, func(t *testing.T) {
+			bill := &models.Bill{
+				UserID:         alice.userID,
+				Name:           "Electricity",
+				Amount:         80,
+				RecurrenceType: "fixed_date",
+				RecurrenceDays: 15,
+			}
+			reference(bill)
+			assertReferenceError(t, env.bills.Create(alice.db, bill), field)
+
+			var count int64
+			if err := alice.db.Model(&models.Bill{}).Where("name = ?", "Electricity").Count(&count).Error; err != nil {
+				t.Fatal(err)
+			}
+			if count != 0 {
+				t.Errorf("rejected bill was saved")
+			}
+		})
+	}
+}
+
+func TestUpdateBillRejectsOtherTenantsReferences(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.newTenant(t, "alice")
+	bob := env.newTenant(t, "bob")
+
+	for field, reference := range crossTenantReferences(alice, bob) {
+		t.Run(field, func(t *testing.T) {
+			bill := *alice.bill
+			bill.TagIDs = []string{alice.tagID}
+			reference(&bill)
+			assertReferenceError(t, env.bills.Update(alice.db, &bill), field)
+
+			saved, err := env.bills.Get(alice.db, alice.bill.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *saved.CategoryID != alice.categoryID || *saved.PayeeID != alice.payeeID || *saved.AccountID != alice.accountID {
+				t.Errorf("rejected update changed the bill's references")
+			}
+			if len(saved.TagIDs) != 1 || saved.TagIDs[0] != alice.tagID {
+				t.Errorf("rejected update changed the bill's tags to %v", saved.TagIDs)
+			}
+		})
+	}
+}
+
+func TestUpdateBillRejectsOtherTenantsBill(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.newTenant(t, "alice")
+	bob := env.newTenant(t, "bob")
+
+	bill := *bob.bill
+	bill.UserID = alice.userID
+	bill.Name = "Taken over"
+	bill.CategoryID, bill.PayeeID, bill.AccountID, bill.TagIDs = nil, nil, nil, nil
+	if err := env.bills.Update(alice.db, &bill); !errors.Is(err, repository.ErrBillNotFound) {
+		t.Fatalf("got error %v, want %v", err, repository.ErrBillNotFound)
+	}
+
+	saved, err := env.bills.Get(bob.db, bob.bill.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Name != "Rent" {
+		t.Errorf("bob's bill was renamed to %q", saved.Name)
+	}
+}
+
+func TestCreatePaymentRejectsOtherTenantsRecords(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.newTenant(t, "alice")
+	bob := env.newTenant(t, "bob")
+	paymentDate := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("bill_id", func(t *testing.T) {
+		payment := &models.Payment{BillID: bob.bill.ID, UserID: alice.userID, Amount: 10, PaymentDate: paymentDate}
+		if err := env.bills.CreatePayment(alice.db, payment); !errors.Is(err, repository.ErrBillNotFound) {
+			t.Fatalf("got error %v, want %v", err, repository.ErrBillNotFound)
+		}
+	})
+
+	t.Run("account_id", func(t *testing.T) {
+		payment := &models.Payment{BillID: alice.bill.ID, UserID: alice.userID, Amount: 10, PaymentDate: paymentDate, AccountID: &bob.accountID}
+		assertReferenceError(t, env.bills.CreatePayment(alice.db, payment), "account_id")
+	})
+
+	var count int64
+	if err := env.db.Model(&models.Payment{}).Where("payment_date = ?", paymentDate).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("rejected payments were saved")
+	}
+}
+
+func TestOtherTenantsRecordsAreNotFound(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.newTenant(t, "alice")
+	bob := env.newTenant(t, "bob")
+	ctx := context.Background()
+
+	t.Run("bill", func(t *testing.T) {
+		_, err := env.bills.Get(alice.db, bob.bill.ID)
+		if !errors.Is(err, repository.ErrBillNotFound) {
+			t.Fatalf("got error %v, want %v", err, repository.ErrBillNotFound)
+		}
+		_, err = env.bills.History(alice.db, bob.bill.ID)
+		assertNotFound(t, err)
+	})
+
+	t.Run("payments", func(t *testing.T) {
+		_, err := env.bills.SearchPayments(alice.db, repository.PaymentFilter{BillID: bob.bill.ID})
+		if !errors.Is(err, repository.ErrBillNotFound) {
+			t.Fatalf("got error %v, want %v", err, repository.ErrBillNotFound)
+		}
+		_, err = env.bills.ReversePayment(alice.db, bob.bill.ID, bob.payment.ID, "insufficient funds")
+		assertNotFound(t, err)
+		assertNotFound(t, env.bills.DeletePayment(alice.db, bob.payment.ID))
+
+		all, err := env.bills.SearchPayments(alice.db, repository.PaymentFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, payment := range all.Payments {
+			if payment.UserID != alice.userID {
+				t.Errorf("listed payment %s of another user", payment.ID)
+			}
+		}
+	})
+
+	t.Run("attachments", func(t *testing.T) {
+		_, err := env.attachments.ListForBill(alice.db, bob.bill.ID)
+		assertNotFound(t, err)
+		_, err = env.attachments.ListForPayment(alice.db, bob.bill.ID, bob.payment.ID)
+		assertNotFound(t, err)
+		_, err = env.attachments.ListForPayment(alice.db, alice.bill.ID, bob.payment.ID)
+		assertNotFound(t, err)
+		for _, attachment := range []*models.Attachment{bob.billAttachment, bob.paymentAttachment} {
+			_, _, err = env.attachments.Open(ctx, alice.db, attachment.ID)
+			assertNotFound(t, err)
+			assertNotFound(t, env.attachments.Delete(ctx, alice.db, attachment.ID))
+		}
+	})
+
+	// Nothing of bob's was changed along the way
+	if _, err := env.bills.Get(bob.db, bob.bill.ID); err != nil {
+		t.Errorf("bob's bill: %v", err)
+	}
+	payments, err := env.bills.SearchPayments(bob.db, repository.PaymentFilter{BillID: bob.bill.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payments.Payments) != 1 || payments.Payments[0].ReversedAt != nil {
+		t.Errorf("bob's payment was deleted or reversed")
+	}
+	for _, attachment := range []*models.Attachment{bob.billAttachment, bob.paymentAttachment} {
+		_, file, err := env.attachments.Open(ctx, bob.db, attachment.ID)
+		if err != nil {
+			t.Errorf("bob's attachment %s: %v", attachment.FileName, err)
+			continue
+		}
+		file.Close()
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/cryptk/williams/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+// trashPurgeInterval is how often expired trash is purged
+const trashPurgeInterval = time.Hour
+
+// TrashService permanently deletes bills and categories that have been in the trash longer than the retention period
+type TrashService struct {
+	billRepo     repository.BillRepository
+	categoryRepo repository.CategoryRepository
+	retention    time.Duration
+}
+
+// NewTrashService creates a new trash service
+func NewTrashService(billRepo repository.BillRepository, categoryRepo repository.CategoryRepository, retention time.Duration) *TrashService {
+	return &TrashService{
+		billRepo:     billRepo,
+		categoryRepo: categoryRepo,
+		retention:    retention,
+	}
+}
+
+// StartPurge purges expired trash now and then periodically until ctx is cancelled
+func (s *TrashService) StartPurge(ctx context.Context) {
+	if s.retention <= 0 {
+		log.Info().Msg("Trash purging disabled, deleted bills and categories are kept forever")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+		for {
+			s.purgeExpired()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// purgeExpired permanently deletes bills and categories deleted before the retention period
+func (s *TrashService) purgeExpired() {
+	cutoff := time.Now().Add(-s.retention)
+
+	bills, err := s.billRepo.PurgeDeleted(cutoff)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to purge deleted bills")
+	}
+	categories, err := s.categoryRepo.PurgeDeleted(cutoff)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to purge deleted categories")
+	}
+
+	if bills > 0 || categories > 0 {
+		log.Info().Int64("bills", bills).Int64("categories", categories).Msg("Purged expired trash")
+	}
+}
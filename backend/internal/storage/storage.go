@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cryptk/williams/internal/config"
+)
+
+// ErrNotFound is returned when no object is stored under a key
+var ErrNotFound = errors.New("object not found")
+
+// Store keeps the contents of uploaded files under opaque keys
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New creates a store from configuration. Files are kept on the local filesystem unless an
+// S3-compatible backend is selected.
+func New(cfg *config.AttachmentsConfig) (Store, error) {
+	switch cfg.Storage {
+	case "", "local":
+		return newLocalStore(cfg.LocalPath)
+	case "s3":
+		return newS3Store(&cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown attachment storage %q: must be 'local' or 's3'", cfg.Storage)
+	}
+}
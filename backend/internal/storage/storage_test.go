@@ -0,0 +1,253 @@
+package storage_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cryptk/williams/internal/config"
+	"github.com/cryptk/williams/internal/storage"
+)
+
+// mockS3 is an in-memory S3-compatible object store that checks requests are signed for its bucket
+type mockS3 struct {
+	*httptest.Server
+	bucket string
+
+	mu           sync.Mutex
+	objects      map[string][]byte
+	contentTypes map[string]string
+	failWith     int // When set, every request is answered with this status
+}
+
+func newMockS3(t *testing.T) *mockS3 {
+	t.Helper()
+	s := &mockS3{bucket: "williams", objects: make(map[string][]byte), contentTypes: make(map[string]string)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *mockS3) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failWith != 0 {
+		http.Error(w, "<Error><Code>InternalError</Code></Error>", s.failWith)
+		return
+	}
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=test-key/") || !strings.Contains(auth, "/eu-west-1/s3/aws4_request") ||
+		!strings.Contains(auth, "Signature=") || r.Header.Get("X-Amz-Date") == "" {
+		http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sum := sha256.Sum256(body)
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+		http.Error(w, "<Error><Code>XAmzContentSHA256Mismatch</Code></Error>", http.StatusBadRequest)
+		return
+	}
+
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+s.bucket+"/")
+	if !ok {
+		http.Error(w, "<Error><Code>NoSuchBucket</Code></Error>", http.StatusNotFound)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		s.objects[key] = body
+		s.contentTypes[key] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		object, ok := s.objects[key]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		w.Write(object)
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// contentType returns the content type an object was stored with
+func (s *mockS3) contentType(key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.contentTypes[key]
+}
+
+func (s *mockS3) newStore(t *testing.T) storage.Store {
+	t.Helper()
+	store, err := storage.New(&config.AttachmentsConfig{
+		Storage: "s3",
+		S3: config.S3Config{
+			Endpoint:        s.URL,
+			Region:          "eu-west-1",
+			Bucket:          s.bucket,
+			AccessKeyID:     "test-key",
+			SecretAccessKey: "test-secret",
+			UsePathStyle:    true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func newLocalStore(t *testing.T) (storage.Store, string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "attachments")
+	store, err := storage.New(&config.AttachmentsConfig{Storage: "local", LocalPath: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, dir
+}
+
+// testStore checks the behaviour every store must share
+func testStore(t *testing.T, store storage.Store) {
+	ctx := context.Background()
+	const key = "user-1/attachment-1"
+
+	if err := store.Put(ctx, key, strings.NewReader("first"), 5, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(ctx, key, strings.NewReader("second"), 6, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readObject(t, store, key); got != "second" {
+		t.Errorf("got %q, want the replaced contents %q", got, "second")
+	}
+
+	if _, err := store.Get(ctx, "user-1/missing"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("get missing object: got %v, want ErrNotFound", err)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("get deleted object: got %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("deleting a missing object: %v", err)
+	}
+}
+
+func readObject(t *testing.T, store storage.Store, key string) string {
+	t.Helper()
+	r, err := store.Get(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestLocalStore(t *testing.T) {
+	store, dir := newLocalStore(t)
+	testStore(t, store)
+
+	// Files are written to a temporary name and renamed, so none are left behind
+	if err := store.Put(context.Background(), "user-1/attachment-2", strings.NewReader("data"), 4, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "user-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "attachment-2" {
+		t.Errorf("files in the user's directory: got %v, want only attachment-2", entries)
+	}
+}
+
+func TestLocalStoreRejectsKeysOutsideItsDirectory(t *testing.T) {
+	store, dir := newLocalStore(t)
+	ctx := context.Background()
+	for _, key := range []string{"../escaped", "user-1/../../escaped", "/etc/escaped", ""} {
+		if err := store.Put(ctx, key, strings.NewReader("data"), 4, "text/plain"); err == nil {
+			t.Errorf("put %q succeeded", key)
+		}
+		if _, err := store.Get(ctx, key); err == nil || errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("get %q: got %v, want an invalid key error", key, err)
+		}
+		if err := store.Delete(ctx, key); err == nil {
+			t.Errorf("delete %q succeeded", key)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escaped")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("a file was written outside the store's directory")
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	s3 := newMockS3(t)
+	store := s3.newStore(t)
+	testStore(t, store)
+
+	if err := store.Put(context.Background(), "user-1/receipt", strings.NewReader("%PDF-1.7"), 8, "application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+	if got := s3.contentType("user-1/receipt"); got != "application/pdf" {
+		t.Errorf("stored content type: got %q, want %q", got, "application/pdf")
+	}
+}
+
+func TestS3StoreReportsErrors(t *testing.T) {
+	s3 := newMockS3(t)
+	store := s3.newStore(t)
+	s3.failWith = http.StatusInternalServerError
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "user-1/attachment-1", strings.NewReader("data"), 4, "text/plain"); err == nil || !strings.Contains(err.Error(), "InternalError") {
+		t.Errorf("put: got %v, want the storage error", err)
+	}
+	if _, err := store.Get(ctx, "user-1/attachment-1"); err == nil || errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("get: got %v, want the storage error", err)
+	}
+	if err := store.Delete(ctx, "user-1/attachment-1"); err == nil {
+		t.Error("delete succeeded")
+	}
+}
+
+func TestNewRejectsInvalidConfiguration(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.AttachmentsConfig
+	}{
+		{"unknown storage", config.AttachmentsConfig{Storage: "ftp"}},
+		{"local without a path", config.AttachmentsConfig{Storage: "local"}},
+		{"s3 without a bucket", config.AttachmentsConfig{Storage: "s3", S3: config.S3Config{Endpoint: "http://minio:9000"}}},
+		{"s3 without an endpoint", config.AttachmentsConfig{Storage: "s3", S3: config.S3Config{Bucket: "williams"}}},
+		{"s3 with an invalid endpoint", config.AttachmentsConfig{Storage: "s3", S3: config.S3Config{Endpoint: "minio", Bucket: "williams"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := storage.New(&tt.cfg); err == nil {
+				t.Error("store was created")
+			}
+		})
+	}
+}
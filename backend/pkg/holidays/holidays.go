@@ -0,0 +1,162 @@
+package holidays
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// bundled holds the holiday calendars shipped with the application, one file per country
+//
+//go:embed data
+var bundled embed.FS
+
+// dateLayout is the format holiday dates are stored and compared in
+const dateLayout = time.DateOnly
+
+// Holiday is a single non-business day
+type Holiday struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	Name string `json:"name"`
+}
+
+// Calendar is a set of holidays, usually the public holidays of one country
+type Calendar struct {
+	Country string `json:"country"` // Upper-case country code, e.g. US
+	Name    string `json:"name"`
+	dates   map[string]string
+}
+
+// NewCalendar creates a calendar from a list of holidays
+func NewCalendar(country, name string, holidays []Holiday) (*Calendar, error) {
+	cal := &Calendar{
+		Country: strings.ToUpper(country),
+		Name:    name,
+		dates:   make(map[string]string, len(holidays)),
+	}
+	for _, holiday := range holidays {
+		if _, err := time.Parse(dateLayout, holiday.Date); err != nil {
+			return nil, fmt.Errorf("invalid holiday date %q: %w", holiday.Date, err)
+		}
+		cal.dates[holiday.Date] = holiday.Name
+	}
+	return cal, nil
+}
+
+// IsHoliday reports whether the date of t is a holiday. Nil calendars have no holidays.
+func (c *Calendar) IsHoliday(t time.Time) bool {
+	if c == nil {
+		return false
+	}
+	_, ok := c.dates[t.Format(dateLayout)]
+	return ok
+}
+
+// Holidays returns the holidays in the given year, or every holiday when year is 0, in date order
+func (c *Calendar) Holidays(year int) []Holiday {
+	prefix := ""
+	if year != 0 {
+		prefix = fmt.Sprintf("%04d-", year)
+	}
+	holidays := []Holiday{}
+	for date, name := range c.dates {
+		if strings.HasPrefix(date, prefix) {
+			holidays = append(holidays, Holiday{Date: date, Name: name})
+		}
+	}
+	slices.SortFunc(holidays, func(a, b Holiday) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+	return holidays
+}
+
+// Registry holds the available calendars keyed by country code
+type Registry struct {
+	calendars map[string]*Calendar
+}
+
+// NewRegistry creates a registry with the bundled calendars, adding any calendar files found in dir.
+// Files in dir are named after their country code (e.g. de.ics or us.json) and are merged into a
+// bundled calendar of the same country.
+func NewRegistry(dir string) (*Registry, error) {
+	r := &Registry{calendars: make(map[string]*Calendar)}
+	if err := r.load(bundled, "data"); err != nil {
+		return nil, fmt.Errorf("failed to load bundled holiday calendars: %w", err)
+	}
+	if dir != "" {
+		if err := r.load(os.DirFS(dir), "."); err != nil {
+			return nil, fmt.Errorf("failed to load holiday calendars from %s: %w", dir, err)
+		}
+	}
+	return r, nil
+}
+
+// Get returns the calendar of a country
+func (r *Registry) Get(country string) (*Calendar, bool) {
+	cal, ok := r.calendars[strings.ToUpper(country)]
+	return cal, ok
+}
+
+// List returns every available calendar ordered by country code
+func (r *Registry) List() []*Calendar {
+	calendars := make([]*Calendar, 0, len(r.calendars))
+	for _, cal := range r.calendars {
+		calendars = append(calendars, cal)
+	}
+	slices.SortFunc(calendars, func(a, b *Calendar) int {
+		return strings.Compare(a.Country, b.Country)
+	})
+	return calendars
+}
+
+// load parses every .json and .ics file in dir of fsys
+func (r *Registry) load(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if ext != ".json" && ext != ".ics" {
+			continue
+		}
+
+		f, err := fsys.Open(filepath.ToSlash(filepath.Join(dir, entry.Name())))
+		if err != nil {
+			return err
+		}
+		country := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		var cal *Calendar
+		if ext == ".json" {
+			cal, err = ParseJSON(f)
+		} else {
+			cal, err = ParseICS(country, f)
+		}
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		r.add(cal)
+	}
+	return nil
+}
+
+// add registers a calendar, merging it into an existing calendar of the same country
+func (r *Registry) add(cal *Calendar) {
+	existing, ok := r.calendars[cal.Country]
+	if !ok {
+		r.calendars[cal.Country] = cal
+		return
+	}
+	for date, name := range cal.dates {
+		existing.dates[date] = name
+	}
+}
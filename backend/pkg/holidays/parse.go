@@ -0,0 +1,93 @@
+package holidays
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// calendarFile is the JSON holiday calendar format
+type calendarFile struct {
+	Country  string    `json:"country"`
+	Name     string    `json:"name"`
+	Holidays []Holiday `json:"holidays"`
+}
+
+// ParseJSON reads a calendar in the JSON format:
+//
+//	{"country": "US", "name": "United States", "holidays": [{"date": "2026-01-01", "name": "New Year's Day"}]}
+func ParseJSON(r io.Reader) (*Calendar, error) {
+	var file calendarFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+	if file.Country == "" {
+		return nil, fmt.Errorf("calendar has no country")
+	}
+	return NewCalendar(file.Country, file.Name, file.Holidays)
+}
+
+// ParseICS reads the all-day events of an iCalendar file as holidays. Only DTSTART and SUMMARY are
+// used; recurrence rules are not expanded, so each holiday must be listed for every year.
+func ParseICS(country string, r io.Reader) (*Calendar, error) {
+	name := strings.ToUpper(country)
+	var holidays []Holiday
+	var current *Holiday
+
+	for _, line := range unfoldICS(r) {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// Drop parameters such as DTSTART;VALUE=DATE
+		key, _, _ = strings.Cut(key, ";")
+
+		switch strings.ToUpper(key) {
+		case "X-WR-CALNAME":
+			name = value
+		case "BEGIN":
+			if value == "VEVENT" {
+				current = &Holiday{}
+			}
+		case "DTSTART":
+			if current != nil {
+				date, err := time.Parse("20060102", value[:min(len(value), 8)])
+				if err != nil {
+					return nil, fmt.Errorf("invalid DTSTART %q: %w", value, err)
+				}
+				current.Date = date.Format(dateLayout)
+			}
+		case "SUMMARY":
+			if current != nil {
+				current.Name = strings.ReplaceAll(value, `\,`, ",")
+			}
+		case "END":
+			if value == "VEVENT" && current != nil {
+				if current.Date != "" {
+					holidays = append(holidays, *current)
+				}
+				current = nil
+			}
+		}
+	}
+
+	return NewCalendar(country, name, holidays)
+}
+
+// unfoldICS splits an iCalendar file into logical lines, joining folded continuation lines
+func unfoldICS(r io.Reader) []string {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,65 @@
+package holidays
+
+import (
+	"fmt"
+	"time"
+)
+
+// Business day roll conventions for dates that fall on a weekend or holiday
+const (
+	RollNone              = "none"               // Keep the date as is
+	RollPrevious          = "previous"           // Move back to the previous business day
+	RollNext              = "next"               // Move forward to the next business day
+	RollModifiedFollowing = "modified_following" // Move forward, unless that changes the month, then move back
+)
+
+// maxRollDays bounds how far a date is moved looking for a business day
+const maxRollDays = 31
+
+// ValidateRoll returns an error for unknown roll conventions
+func ValidateRoll(convention string) error {
+	switch convention {
+	case RollNone, RollPrevious, RollNext, RollModifiedFollowing:
+		return nil
+	default:
+		return fmt.Errorf("invalid business_day_roll: must be 'none', 'previous', 'next', or 'modified_following'")
+	}
+}
+
+// IsBusinessDay reports whether t is a weekday that is not a holiday in any of the calendars
+func IsBusinessDay(t time.Time, calendars ...*Calendar) bool {
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	for _, cal := range calendars {
+		if cal.IsHoliday(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// Roll moves t to a business day according to the convention. The time of day is kept.
+func Roll(t time.Time, convention string, calendars ...*Calendar) time.Time {
+	switch convention {
+	case RollPrevious:
+		return step(t, -1, calendars)
+	case RollNext:
+		return step(t, 1, calendars)
+	case RollModifiedFollowing:
+		if next := step(t, 1, calendars); next.Month() == t.Month() {
+			return next
+		}
+		return step(t, -1, calendars)
+	default:
+		return t
+	}
+}
+
+// step moves t by one day in the given direction until it reaches a business day
+func step(t time.Time, direction int, calendars []*Calendar) time.Time {
+	for i := 0; i < maxRollDays && !IsBusinessDay(t, calendars...); i++ {
+		t = t.AddDate(0, 0, direction)
+	}
+	return t
+}
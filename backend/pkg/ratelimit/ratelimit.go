@@ -0,0 +1,140 @@
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+)
+
+// LimitError is returned when a request is rejected by a Limiter or Lockout
+type LimitError struct {
+	RetryAfter time.Duration
+	Locked     bool // True for a lockout after repeated failures, false for a plain rate limit
+}
+
+// Error implements error
+func (e *LimitError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed attempts, try again in %s", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("too many requests, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+// Limiter allows a fixed number of requests per key within a window
+type Limiter struct {
+	store  Store
+	prefix string
+	limit  int
+	window time.Duration
+}
+
+// NewLimiter creates a fixed-window limiter. Keys are namespaced with prefix so several
+// limiters can share one store.
+func NewLimiter(store Store, prefix string, limit int, window time.Duration) *Limiter {
+	return &Limiter{store: store, prefix: prefix, limit: limit, window: window}
+}
+
+// Allow counts a request for key and returns a *LimitError if the limit has been exceeded.
+// A limit of zero or less disables the limiter.
+func (l *Limiter) Allow(key string) error {
+	if l.limit <= 0 {
+		return nil
+	}
+
+	count, expiresAt, err := l.store.Increment(l.prefix+":"+key, l.window)
+	if err != nil {
+		return err
+	}
+	if count > l.limit {
+		return &LimitError{RetryAfter: time.Until(expiresAt)}
+	}
+	return nil
+}
+
+// Lockout locks a key after repeated failures. Each consecutive lockout doubles in length,
+// up to a maximum, so sustained guessing gets progressively slower.
+type Lockout struct {
+	store       Store
+	prefix      string
+	threshold   int
+	baseLock    time.Duration
+	maxLock     time.Duration
+	memoryReset time.Duration // How long failures and lockout levels are remembered
+}
+
+// NewLockout creates a progressive lockout. A threshold of zero or less disables it.
+func NewLockout(store Store, prefix string, threshold int, baseLock, maxLock time.Duration) *Lockout {
+	return &Lockout{
+		store:       store,
+		prefix:      prefix,
+		threshold:   threshold,
+		baseLock:    baseLock,
+		maxLock:     maxLock,
+		memoryReset: 24 * time.Hour,
+	}
+}
+
+// Check returns a *LimitError if key is currently locked
+func (l *Lockout) Check(key string) error {
+	if l.threshold <= 0 {
+		return nil
+	}
+
+	locked, expiresAt, err := l.store.Get(l.key("lock", key))
+	if err != nil {
+		return err
+	}
+	if locked > 0 {
+		return &LimitError{RetryAfter: time.Until(expiresAt), Locked: true}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed attempt. When the threshold is reached the key is locked and the
+// lock duration is returned; otherwise it returns zero.
+func (l *Lockout) RecordFailure(key string) (time.Duration, error) {
+	if l.threshold <= 0 {
+		return 0, nil
+	}
+
+	failures, _, err := l.store.Increment(l.key("fail", key), l.memoryReset)
+	if err != nil {
+		return 0, err
+	}
+	if failures < l.threshold {
+		return 0, nil
+	}
+
+	// Threshold reached: lock for base * 2^(previous lockouts), capped at max
+	level, _, err := l.store.Increment(l.key("level", key), l.memoryReset)
+	if err != nil {
+		return 0, err
+	}
+	duration := l.baseLock
+	for i := 1; i < level && duration < l.maxLock; i++ {
+		duration *= 2
+	}
+	duration = min(duration, l.maxLock)
+
+	if err := l.store.Set(l.key("lock", key), 1, duration); err != nil {
+		return 0, err
+	}
+	if err := l.store.Delete(l.key("fail", key)); err != nil {
+		return 0, err
+	}
+	return duration, nil
+}
+
+// RecordSuccess clears the failure count and lockout level after a successful attempt
+func (l *Lockout) RecordSuccess(key string) error {
+	if l.threshold <= 0 {
+		return nil
+	}
+	if err := l.store.Delete(l.key("fail", key)); err != nil {
+		return err
+	}
+	return l.store.Delete(l.key("level", key))
+}
+
+func (l *Lockout) key(kind, key string) string {
+	return l.prefix + ":" + kind + ":" + key
+}
@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Store holds expiring counters. Implementations must be safe for concurrent use.
+// The operations map directly onto Redis (INCR + EXPIRE NX, GET + PTTL, SET PX, DEL), so a
+// shared backend can be added for multi-instance deployments without changing callers.
+type Store interface {
+	// Increment adds one to the counter for key, creating it with the given ttl if it does not exist.
+	// It returns the new count and the time the counter expires.
+	Increment(key string, ttl time.Duration) (int, time.Time, error)
+	// Get returns the current count and expiry for key, or zero values if it does not exist.
+	Get(key string) (int, time.Time, error)
+	// Set stores a counter value with the given ttl, replacing any existing value.
+	Set(key string, value int, ttl time.Duration) error
+	// Delete removes the counter for key.
+	Delete(key string) error
+}
+
+// sweepInterval controls how often expired entries are purged from the memory store
+const sweepInterval = time.Minute
+
+type memoryEntry struct {
+	count     int
+	expiresAt time.Time
+}
+
+// MemoryStore is an in-process Store. Counters are lost on restart and not shared between instances.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:   make(map[string]memoryEntry),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Increment implements Store
+func (m *MemoryStore) Increment(key string, ttl time.Duration) (int, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	entry, ok := m.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		entry = memoryEntry{expiresAt: now.Add(ttl)}
+	}
+	entry.count++
+	m.entries[key] = entry
+
+	return entry.count, entry.expiresAt, nil
+}
+
+// Get implements Store
+func (m *MemoryStore) Get(key string) (int, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok || !m.now().Before(entry.expiresAt) {
+		return 0, time.Time{}, nil
+	}
+	return entry.count, entry.expiresAt, nil
+}
+
+// Set implements Store
+func (m *MemoryStore) Set(key string, value int, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = memoryEntry{count: value, expiresAt: m.now().Add(ttl)}
+	return nil
+}
+
+// Delete implements Store
+func (m *MemoryStore) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+	return nil
+}
+
+// sweep removes expired entries so the map does not grow without bound. Caller must hold the lock.
+func (m *MemoryStore) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	for key, entry := range m.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(m.entries, key)
+		}
+	}
+	m.lastSweep = now
+}
@@ -5,10 +5,11 @@ import "time"
 // CalculateNextDueDate calculates the next due date for a fixed-date recurring bill based on:
 // - dueDay: the day of the month (1-31) the bill is due
 // - referenceDate: the date to calculate from (created date or last payment date)
+// - loc: the timezone of the user the bill belongs to
 // Returns the next occurrence of dueDay that is >= referenceDate
-func CalculateNextDueDate(dueDay int, referenceDate time.Time) time.Time {
-	// Ensure we're working in the application's timezone
-	referenceDate = ConvertToAppTimezone(referenceDate)
+func CalculateNextDueDate(dueDay int, referenceDate time.Time, loc *time.Location) time.Time {
+	// Ensure we're working in the user's timezone
+	referenceDate = referenceDate.In(loc)
 
 	// Only compare the date (year, month, day), ignore time
 	year := referenceDate.Year()
@@ -16,7 +17,7 @@ func CalculateNextDueDate(dueDay int, referenceDate time.Time) time.Time {
 	refDay := referenceDate.Day()
 
 	// Try the current month first
-	nextDue := time.Date(year, month, dueDay, 12, 0, 0, 0, GetAppLocation())
+	nextDue := time.Date(year, month, dueDay, 12, 0, 0, 0, loc)
 
 	// If the due day for this month has already passed, move to next month
 	if dueDay < refDay {
@@ -26,7 +27,7 @@ func CalculateNextDueDate(dueDay int, referenceDate time.Time) time.Time {
 			month = 1
 			year++
 		}
-		nextDue = time.Date(year, month, dueDay, 12, 0, 0, 0, GetAppLocation())
+		nextDue = time.Date(year, month, dueDay, 12, 0, 0, 0, loc)
 	}
 
 	// Handle case where due_day doesn't exist in the month (e.g., Feb 30)
@@ -34,7 +35,7 @@ func CalculateNextDueDate(dueDay int, referenceDate time.Time) time.Time {
 	if nextDue.Day() != dueDay {
 		// The date was adjusted because the day doesn't exist in this month
 		// Use the last day of the previous month instead
-		nextDue = time.Date(year, month, 1, 12, 0, 0, 0, GetAppLocation()).AddDate(0, 0, -1)
+		nextDue = time.Date(year, month, 1, 12, 0, 0, 0, loc).AddDate(0, 0, -1)
 	}
 
 	return nextDue
@@ -42,15 +43,15 @@ func CalculateNextDueDate(dueDay int, referenceDate time.Time) time.Time {
 
 // CalculateNextDueDateAfterPayment calculates the next due date after a payment for fixed-date recurring bills
 // This moves to the next occurrence of dueDay after the payment date
-func CalculateNextDueDateAfterPayment(dueDay int, paymentDate time.Time) time.Time {
-	// Ensure we're working in the application's timezone
-	paymentDate = ConvertToAppTimezone(paymentDate)
+func CalculateNextDueDateAfterPayment(dueDay int, paymentDate time.Time, loc *time.Location) time.Time {
+	// Ensure we're working in the user's timezone
+	paymentDate = paymentDate.In(loc)
 
 	// Start by moving to next month using AddDate - this handles year rollover automatically
 	nextMonth := paymentDate.AddDate(0, 1, 0)
 
 	// Create the next due date with the specified day
-	nextDue := time.Date(nextMonth.Year(), nextMonth.Month(), dueDay, 12, 0, 0, 0, GetAppLocation())
+	nextDue := time.Date(nextMonth.Year(), nextMonth.Month(), dueDay, 12, 0, 0, 0, loc)
 
 	// Check if we rolled forward 2+ months instead of 1
 	// This happens when the due day doesn't exist in the next month (e.g., due day 31 in February)
@@ -62,7 +63,7 @@ func CalculateNextDueDateAfterPayment(dueDay int, paymentDate time.Time) time.Ti
 	if monthDiff > 1 {
 		// We rolled too far forward, use the last day of the next month instead
 		// Get the first day of the next month, then go back one day
-		nextDue = time.Date(nextMonth.Year(), nextMonth.Month()+1, 1, 12, 0, 0, 0, GetAppLocation()).AddDate(0, 0, -1)
+		nextDue = time.Date(nextMonth.Year(), nextMonth.Month()+1, 1, 12, 0, 0, 0, loc).AddDate(0, 0, -1)
 	}
 
 	return nextDue
@@ -71,14 +72,15 @@ func CalculateNextDueDateAfterPayment(dueDay int, paymentDate time.Time) time.Ti
 // CalculateNextDueDateInterval calculates the next due date for an interval-based recurring bill based on:
 // - intervalDays: the number of days between each occurrence of the bill
 // - referenceDate: the date to calculate from (created date or last payment date)
+// - loc: the timezone of the user the bill belongs to
 // Returns the next due date that is >= referenceDate
-func CalculateNextDueDateInterval(intervalDays int, referenceDate time.Time) time.Time {
-	// Ensure we're working in the application's timezone
-	referenceDate = ConvertToAppTimezone(referenceDate)
+func CalculateNextDueDateInterval(intervalDays int, referenceDate time.Time, loc *time.Location) time.Time {
+	// Ensure we're working in the user's timezone
+	referenceDate = referenceDate.In(loc)
 
 	// For interval bills, simply add the interval to the reference date
 	// Normalize to noon for consistency
-	nextDue := time.Date(referenceDate.Year(), referenceDate.Month(), referenceDate.Day(), 12, 0, 0, 0, GetAppLocation())
+	nextDue := time.Date(referenceDate.Year(), referenceDate.Month(), referenceDate.Day(), 12, 0, 0, 0, loc)
 	nextDue = nextDue.AddDate(0, 0, intervalDays)
 
 	return nextDue
@@ -86,13 +88,13 @@ func CalculateNextDueDateInterval(intervalDays int, referenceDate time.Time) tim
 
 // CalculateNextDueDateAfterPaymentInterval calculates the next due date after a payment for interval-based recurring bills
 // This adds the interval days to the payment date
-func CalculateNextDueDateAfterPaymentInterval(intervalDays int, paymentDate time.Time) time.Time {
-	// Ensure we're working in the application's timezone
-	paymentDate = ConvertToAppTimezone(paymentDate)
+func CalculateNextDueDateAfterPaymentInterval(intervalDays int, paymentDate time.Time, loc *time.Location) time.Time {
+	// Ensure we're working in the user's timezone
+	paymentDate = paymentDate.In(loc)
 
 	// For interval bills, add the interval to the payment date
 	// Normalize to noon for consistency
-	nextDue := time.Date(paymentDate.Year(), paymentDate.Month(), paymentDate.Day(), 12, 0, 0, 0, GetAppLocation())
+	nextDue := time.Date(paymentDate.Year(), paymentDate.Month(), paymentDate.Day(), 12, 0, 0, 0, loc)
 	nextDue = nextDue.AddDate(0, 0, intervalDays)
 
 	return nextDue
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"time"
 )
@@ -37,6 +38,24 @@ func NowInAppTimezone() time.Time {
 	return time.Now().In(GetAppLocation())
 }
 
+type locationKey struct{}
+
+// WithLocation returns a copy of ctx carrying the timezone of the user a request is made for
+func WithLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, locationKey{}, loc)
+}
+
+// LocationFromContext returns the user's timezone stored in ctx, or the application timezone if
+// there is none
+func LocationFromContext(ctx context.Context) *time.Location {
+	if ctx != nil {
+		if loc, ok := ctx.Value(locationKey{}).(*time.Location); ok && loc != nil {
+			return loc
+		}
+	}
+	return GetAppLocation()
+}
+
 // ParseAndConvertToAppTimezone parses an RFC3339 time string and converts it to the application timezone
 func ParseAndConvertToAppTimezone(timeStr string) (time.Time, error) {
 	t, err := time.Parse(time.RFC3339, timeStr)